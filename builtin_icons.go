@@ -0,0 +1,76 @@
+package galendar
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BuiltinIconPrefix is the prefix of icons embedded in the binary, for
+// example "builtin:christmas_tree"
+const BuiltinIconPrefix = "builtin:"
+
+//go:embed assets/*.svg
+var builtinIcons embed.FS
+
+// BuiltinIconNames returns the sorted names of all the builtin icons,
+// without the BuiltinIconPrefix
+func BuiltinIconNames() []string {
+	entries, err := fs.ReadDir(builtinIcons, "assets")
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// IsBuiltinIcon reports if icon references an icon embedded in the binary
+func IsBuiltinIcon(icon string) bool {
+	return strings.HasPrefix(icon, BuiltinIconPrefix)
+}
+
+// resolveIconPath makes icon relative to baseDir unless it is a builtin icon
+// or an absolute path, builtin icons must exist
+func resolveIconPath(icon, baseDir string) (string, error) {
+	if icon == "" {
+		return icon, nil
+	}
+
+	if IsBuiltinIcon(icon) {
+		if _, err := fs.Stat(builtinIcons, builtinIconFilename(icon)); err != nil {
+			return "", fmt.Errorf("unknown builtin icon %q (available: %s)", icon, strings.Join(BuiltinIconNames(), ", "))
+		}
+		return icon, nil
+	}
+
+	if filepath.IsAbs(icon) || baseDir == "" {
+		return icon, nil
+	}
+
+	return filepath.Join(baseDir, icon), nil
+}
+
+// readIcon reads the content of an icon from the filesystem or from the
+// builtin icons
+func readIcon(icon string) ([]byte, error) {
+	if IsBuiltinIcon(icon) {
+		return builtinIcons.ReadFile(builtinIconFilename(icon))
+	}
+
+	return os.ReadFile(icon)
+}
+
+func builtinIconFilename(icon string) string {
+	return path.Join("assets", strings.TrimPrefix(icon, BuiltinIconPrefix)+".svg")
+}
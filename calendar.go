@@ -2,6 +2,7 @@ package galendar
 
 import (
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -13,10 +14,21 @@ type Calendar struct {
 	Weeks       [][]Day
 	WeekStart   time.Weekday
 	SpecialDays SpecialDays
+	MaxRows     int            // 0 means as many rows as needed, see Fold
+	Location    *time.Location // time zone of the times of the days, such as the phases of the moon
+	Coordinates *Coordinates   // place of the sunrises and sunsets of the days, nil means none
+
+	unfoldedWeeks [][]Day    // every week in its own row, nil if no week is folded
+	modelMonths   []Calendar // every month of calendars from models, see JSONCalendar.Calendar
 }
 
-// NewCalendar creates a new calendar for the given month and year
+// NewCalendar creates a new calendar for the given month and year. Times are
+// in UTC and days have no sun, see In and At
 func NewCalendar(year, month int, weekStart time.Weekday, specialDays SpecialDays) (Calendar, error) {
+	return newCalendar(year, month, weekStart, specialDays, time.UTC, nil)
+}
+
+func newCalendar(year, month int, weekStart time.Weekday, specialDays SpecialDays, loc *time.Location, coordinates *Coordinates) (Calendar, error) {
 	var cal Calendar
 
 	if month < 1 || month > 12 {
@@ -75,41 +87,262 @@ func NewCalendar(year, month int, weekStart time.Weekday, specialDays SpecialDay
 	}
 
 	cal.Weeks = weeks
-	return cal, nil
+	return cal.withSky(loc, coordinates), nil
+}
+
+// Fold returns the calendar with at most maxRows rows of weeks: if the month
+// needs more (only possible with 5) the days of the last week are folded into
+// the cells of the week before, 0 means no limit
+func (cal Calendar) Fold(maxRows int) (Calendar, error) {
+	if maxRows != 0 && maxRows < 5 {
+		return cal, fmt.Errorf("invalid max rows: %d (must be 0 or at least 5)", maxRows)
+	}
+
+	return cal.eachMonth(func(month Calendar) Calendar {
+		return month.fold(maxRows)
+	}), nil
+}
+
+// fold folds the weeks of cal, see Fold
+func (cal Calendar) fold(maxRows int) Calendar {
+	cal = cal.unfolded()
+	cal.MaxRows = maxRows
+	if maxRows == 0 || len(cal.Weeks) <= maxRows {
+		return cal
+	}
+
+	// The other days of the folded weeks belong to the next month and are
+	// dropped
+	weeks := slices.Clone(cal.Weeks[:maxRows])
+	last := slices.Clone(weeks[maxRows-1])
+	for _, week := range cal.Weeks[maxRows:] {
+		for i, day := range week {
+			if day.IsCurrentMonth {
+				last[i].Folded = &day
+			}
+		}
+	}
+	weeks[maxRows-1] = last
+
+	cal.unfoldedWeeks = cal.Weeks
+	cal.Weeks = weeks
+	return cal
 }
 
+// CloneAt returns the calendar of another month with the same week start,
+// special days and rows, or the month of the model of cal if it has it.
+// Months before 1 or after 12 are in the previous or next years, so
+// CloneAt(cal.Month-1) and CloneAt(cal.Month+1) always work
 func (cal Calendar) CloneAt(month int) (Calendar, error) {
-	return NewCalendar(cal.Year, month, cal.WeekStart, cal.SpecialDays)
+	year := cal.Year
+	for month < 1 {
+		month += 12
+		year--
+	}
+	for month > 12 {
+		month -= 12
+		year++
+	}
+
+	for _, modelMonth := range cal.modelMonths {
+		if modelMonth.Year == year && modelMonth.Month == month {
+			return modelMonth.fold(cal.MaxRows), nil
+		}
+	}
+
+	clone, err := newCalendar(year, month, cal.WeekStart, cal.SpecialDays, cal.location(), cal.Coordinates)
+	if err != nil {
+		return clone, err
+	}
+	clone.modelMonths = cal.modelMonths
+	return clone.fold(cal.MaxRows), nil
+}
+
+// months returns the months of the year of cal: the 12 months, or the months
+// of its model for calendars from models
+func (cal Calendar) months() ([]Calendar, error) {
+	if cal.modelMonths != nil {
+		return cal.modelMonths, nil
+	}
+
+	months := make([]Calendar, 0, 12)
+	for month := 1; month <= 12; month++ {
+		monthCal, err := cal.CloneAt(month)
+		if err != nil {
+			return nil, fmt.Errorf("can't clone calendar at month %d: %w", month, err)
+		}
+		months = append(months, monthCal)
+	}
+	return months, nil
+}
+
+// eachMonth returns cal with f applied to it and to the months of its model,
+// if any
+func (cal Calendar) eachMonth(f func(Calendar) Calendar) Calendar {
+	if cal.modelMonths != nil {
+		months := make([]Calendar, len(cal.modelMonths))
+		for i, month := range cal.modelMonths {
+			months[i] = f(month)
+		}
+		cal.modelMonths = linkMonths(months)
+	}
+	modelMonths := cal.modelMonths
+	cal = f(cal)
+	cal.modelMonths = modelMonths
+	return cal
+}
+
+// linkMonths sets months as the model months of each of them, see
+// Calendar.CloneAt
+func linkMonths(months []Calendar) []Calendar {
+	for i := range months {
+		months[i].modelMonths = months
+	}
+	return months
+}
+
+// In returns the calendar with the times of its days in loc: the phases of
+// the moon are in the days they happen in loc
+func (cal Calendar) In(loc *time.Location) Calendar {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return cal.eachMonth(func(month Calendar) Calendar {
+		return month.withSky(loc, month.Coordinates)
+	})
+}
+
+// At returns the calendar with the sunrises and sunsets of its days at
+// coordinates
+func (cal Calendar) At(coordinates Coordinates) Calendar {
+	return cal.eachMonth(func(month Calendar) Calendar {
+		return month.withSky(month.location(), &coordinates)
+	})
+}
+
+// withSky returns the calendar with the moon and the sun of its days, and of
+// the days folded into them, computed in loc and at coordinates
+func (cal Calendar) withSky(loc *time.Location, coordinates *Coordinates) Calendar {
+	cal.Location = loc
+	cal.Coordinates = coordinates
+	cal.Weeks = weeksWithSky(cal.Weeks, loc, coordinates)
+	if cal.unfoldedWeeks != nil {
+		cal.unfoldedWeeks = weeksWithSky(cal.unfoldedWeeks, loc, coordinates)
+	}
+	return cal
+}
+
+// weeksWithSky returns a copy of weeks with the moon and the sun of their
+// days, see Calendar.withSky
+func weeksWithSky(weeks [][]Day, loc *time.Location, coordinates *Coordinates) [][]Day {
+	if len(weeks) == 0 || len(weeks[0]) == 0 {
+		return weeks
+	}
+
+	// Folded days are a week after the last row
+	startDate := weeks[0][0].Date
+	endDate := startDate.AddDate(0, 0, 7*(len(weeks)+1)-1)
+	moons := moonOfDays(startDate, endDate, loc)
+	var suns map[time.Time]*Sun
+	if coordinates != nil {
+		suns = sunOfDays(startDate, endDate, loc, *coordinates)
+	}
+
+	sky := func(day Day) Day {
+		day.Moon = moons[day.Date]
+		day.Sun = suns[day.Date]
+		if day.Folded != nil {
+			folded := *day.Folded
+			folded.Moon = moons[folded.Date]
+			folded.Sun = suns[folded.Date]
+			day.Folded = &folded
+		}
+		return day
+	}
+
+	result := make([][]Day, len(weeks))
+	for i, week := range weeks {
+		result[i] = make([]Day, len(week))
+		for j, day := range week {
+			result[i][j] = sky(day)
+		}
+	}
+	return result
+}
+
+// location returns the time zone of cal, UTC for calendars not created by
+// NewCalendar
+func (cal Calendar) location() *time.Location {
+	if cal.Location == nil {
+		return time.UTC
+	}
+	return cal.Location
+}
+
+// unfolded returns the calendar with every week in its own row
+func (cal Calendar) unfolded() Calendar {
+	cal.MaxRows = 0
+	if cal.unfoldedWeeks != nil {
+		cal.Weeks = cal.unfoldedWeeks
+		cal.unfoldedWeeks = nil
+	}
+	return cal
 }
 
 type Day struct {
 	Date           time.Time
 	DayNumber      int
 	IsCurrentMonth bool
+	Folded         *Day // day of the week after drawn in the same cell, see Calendar.Fold
+	Moon           Moon
+	Sun            *Sun // nil if the calendar has no coordinates, see Calendar.At
 	special        *SpecialDay
+	holiday        *bool // overrides weekends and special days, in calendars from models
 }
 
-func (day Day) TextColor() (r, g, b, a int) {
-	if !day.IsCurrentMonth {
-		return 128, 128, 128, 0
+// CellDays returns the days drawn in the cell of day: day itself and the day
+// folded into it, if any
+func (day Day) CellDays() []Day {
+	if day.Folded == nil {
+		return []Day{day}
 	}
+	return []Day{day, *day.Folded}
+}
 
-	return 0, 0, 0, 1
+// TextColor returns the color of the number of day in the default theme, a
+// is 1 if it has a color
+//
+// Deprecated: colors are in themes, see Theme.DayNumber and Theme.OtherMonth
+func (day Day) TextColor() (r, g, b, a int) {
+	return colorComponents(defaultTheme().dayNumberColor(day))
 }
 
+// FillColor returns the fill of the box with the number of day in the
+// default theme, a is 1 if it has a color
+//
+// Deprecated: colors are in themes, see Theme.DayBox
 func (day Day) FillColor() (r, g, b, a int) {
 	if !day.IsCurrentMonth {
 		return 0, 0, 0, 0
 	}
+	return colorComponents(defaultTheme().dayBoxFill(day))
+}
 
-	if day.IsHoliday() {
-		return 200, 200, 200, 1
+// colorComponents returns the components of c as the deprecated colors of
+// days
+func colorComponents(c Color) (r, g, b, a int) {
+	if !c.Valid {
+		return 0, 0, 0, 0
 	}
-
-	return 0, 0, 0, 0
+	r, g, b = c.RGB()
+	return r, g, b, 1
 }
 
 func (day Day) IsHoliday() bool {
+	if day.holiday != nil {
+		return *day.holiday
+	}
+
 	weekday := day.Date.Weekday()
 	if weekday == time.Saturday || weekday == time.Sunday {
 		return true
@@ -133,3 +366,29 @@ func (day Day) Note() *SpecialDayNote {
 
 	return &day.special.Note
 }
+
+func (day Day) Icon() string {
+	if day.special == nil {
+		return ""
+	}
+
+	return day.special.Icon
+}
+
+// Icons returns the icons of day from right to left, Icon first
+func (day Day) Icons() []string {
+	if day.special == nil || day.special.Icon == "" {
+		return nil
+	}
+
+	return append([]string{day.special.Icon}, day.special.Icons...)
+}
+
+// QR returns the text of the QR code of day, empty if it has none
+func (day Day) QR() string {
+	if day.special == nil {
+		return ""
+	}
+
+	return day.special.QR
+}
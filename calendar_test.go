@@ -0,0 +1,78 @@
+package galendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestCalendar_CloneAtCrossesYears(t *testing.T) {
+	cal, err := galendar.NewCalendar(2025, 1, time.Sunday, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		month         int
+		expectedYear  int
+		expectedMonth int
+	}{
+		{0, 2024, 12},
+		{-1, 2024, 11},
+		{6, 2025, 6},
+		{13, 2026, 1},
+		{25, 2027, 1},
+	} {
+		clone, err := cal.CloneAt(tc.month)
+		if err != nil {
+			t.Fatalf("CloneAt(%d) failed: %v", tc.month, err)
+		}
+		if clone.Year != tc.expectedYear || clone.Month != tc.expectedMonth {
+			t.Errorf("CloneAt(%d) = %d-%02d, expected %d-%02d", tc.month, clone.Year, clone.Month, tc.expectedYear, tc.expectedMonth)
+		}
+	}
+}
+
+func TestCalendar_MaxRowsFoldsSixthWeek(t *testing.T) {
+	// March 2025 starts on a Saturday, with weeks starting on Sunday it needs
+	// 6 rows and the 30th and 31st share their cells with the 23rd and 24th
+	unfolded, err := galendar.NewCalendar(2025, 3, time.Sunday, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	cal, err := unfolded.Fold(5)
+	if err != nil {
+		t.Fatalf("Fold failed: %v", err)
+	}
+
+	if len(cal.Weeks) != 5 {
+		t.Fatalf("Expected 5 weeks, got %d", len(cal.Weeks))
+	}
+
+	last := cal.Weeks[4]
+	for i, day := range last {
+		switch i {
+		case 0, 1:
+			if day.Folded == nil || day.Folded.DayNumber != day.DayNumber+7 {
+				t.Errorf("Expected day %d to hold day %d, got %v", day.DayNumber, day.DayNumber+7, day.Folded)
+			} else if len(day.CellDays()) != 2 {
+				t.Errorf("Expected 2 days in the cell of day %d, got %d", day.DayNumber, len(day.CellDays()))
+			}
+		default:
+			if day.Folded != nil {
+				t.Errorf("Expected day %d to hold no other day, got %d", day.DayNumber, day.Folded.DayNumber)
+			}
+		}
+	}
+
+	if len(unfolded.Weeks) != 6 {
+		t.Errorf("Expected 6 weeks without a limit, got %d", len(unfolded.Weeks))
+	}
+	if refolded, _ := cal.Fold(0); len(refolded.Weeks) != 6 || refolded.Weeks[4][0].Folded != nil {
+		t.Errorf("Expected 6 weeks and no folded days after unfolding, got %d", len(refolded.Weeks))
+	}
+	if _, err := unfolded.Fold(4); err == nil {
+		t.Errorf("Expected an error for less than 5 rows")
+	}
+}
@@ -0,0 +1,332 @@
+package galendar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ChineseDate is a date of the chinese lunisolar calendar: months start on
+// the day of a new moon in China and the winter solstice is in the 11th
+// month. Years of 13 months repeat as leap the first month without a
+// principal term, a solar term at a multiple of 30 degrees
+type ChineseDate struct {
+	Year  int  // gregorian year of the new year
+	Month int  // 1-12
+	Leap  bool // the month repeats the month before
+	Day   int
+}
+
+// SolarTerm is one of the 24 solar terms of the chinese calendar, the sun at
+// a multiple of 15 degrees of longitude
+type SolarTerm struct {
+	Time time.Time // in UTC
+	Name string    // to be translated, as "Pure brightness"
+}
+
+// chinaOffset is the offset from UTC of the time zone of China, the calendar
+// follows the moon and the sun as seen there
+const chinaOffset = 8 * 60 * 60
+
+// tropicalYear is the mean days between two passes of the sun by the same
+// longitude
+const tropicalYear = 365.242189
+
+// solarTerms are the names of the solar terms from the spring equinox, at 0
+// degrees, in steps of 15 degrees: in English to be translated and in pinyin
+// for the special days
+var solarTerms = []struct{ name, pinyin string }{
+	{"Spring equinox", "chunfen"}, {"Pure brightness", "qingming"}, {"Grain rain", "guyu"},
+	{"Start of summer", "lixia"}, {"Grain buds", "xiaoman"}, {"Grain in ear", "mangzhong"},
+	{"Summer solstice", "xiazhi"}, {"Minor heat", "xiaoshu"}, {"Major heat", "dashu"},
+	{"Start of autumn", "liqiu"}, {"End of heat", "chushu"}, {"White dew", "bailu"},
+	{"Autumn equinox", "qiufen"}, {"Cold dew", "hanlu"}, {"Frost descent", "shuangjiang"},
+	{"Start of winter", "lidong"}, {"Minor snow", "xiaoxue"}, {"Major snow", "daxue"},
+	{"Winter solstice", "dongzhi"}, {"Minor cold", "xiaohan"}, {"Major cold", "dahan"},
+	{"Start of spring", "lichun"}, {"Rain water", "yushui"}, {"Awakening of insects", "jingzhe"},
+}
+
+// solarLongitudeTerms are the periodic terms of the longitude of the sun, as
+// amplitude, phase and rate, from "Calendrical Calculations" by Reingold and
+// Dershowitz, after Bretagnon and Simon
+var solarLongitudeTerms = [][3]float64{
+	{403406, 270.54861, 0.9287892}, {195207, 340.19128, 35999.1376958},
+	{119433, 63.91854, 35999.4089666}, {112392, 331.26220, 35998.7287385},
+	{3891, 317.843, 71998.20261}, {2819, 86.631, 71998.4403},
+	{1721, 240.052, 36000.35726}, {660, 310.26, 71997.4812},
+	{350, 247.23, 32964.4678}, {334, 260.87, -19.4410},
+	{314, 297.82, 445267.1117}, {268, 343.14, 45036.8840},
+	{242, 166.79, 3.1008}, {234, 81.53, 22518.4434},
+	{158, 3.50, -19.9739}, {132, 132.75, 65928.9345},
+	{129, 182.95, 9038.0293}, {114, 162.03, 3034.7684},
+	{99, 29.8, 33718.148}, {93, 266.4, 3034.448},
+	{86, 249.2, -2280.773}, {78, 157.6, 29929.992},
+	{72, 257.8, 31556.493}, {68, 185.1, 149.588},
+	{64, 69.9, 9037.750}, {46, 8.0, 107997.405},
+	{38, 197.1, -4444.176}, {37, 250.4, 151.771},
+	{32, 65.3, 67555.316}, {29, 162.7, 31556.080},
+	{28, 341.5, -4561.540}, {27, 291.6, 107996.706},
+	{27, 98.5, 1221.655}, {25, 146.7, 62894.167},
+	{24, 110.0, 31437.369}, {21, 5.2, 14578.298},
+	{21, 342.6, -31931.757}, {20, 230.9, 34777.243},
+	{18, 256.1, 1221.999}, {17, 45.3, 62894.511},
+	{14, 242.9, -4442.039}, {13, 115.2, 107997.909},
+	{13, 151.8, 119.066}, {13, 285.3, 16859.071},
+	{12, 53.3, -4.578}, {10, 126.6, 26895.292},
+	{10, 205.7, -39.127}, {10, 85.9, 12297.536},
+	{10, 146.1, 90073.778},
+}
+
+// solarLongitude returns the apparent longitude of the sun at t in degrees,
+// from 0 to 360
+func solarLongitude(t time.Time) float64 {
+	c := (julianEphemerisDay(t) - jdJ2000) / 36525
+
+	longitude := 282.7771834 + 36000.76953744*c
+	sum := 0.0
+	for _, term := range solarLongitudeTerms {
+		sum += term[0] * math.Sin(radians(term[1]+term[2]*c))
+	}
+	longitude += 0.000005729577951308232 * sum
+
+	// Aberration and nutation in longitude
+	longitude += 0.0000974*math.Cos(radians(177.63+35999.01848*c)) - 0.005575
+	a := radians(124.90 - 1934.134*c + 0.002063*c*c)
+	b := radians(201.11 + 72001.5377*c + 0.00057*c*c)
+	longitude += -0.004778*math.Sin(a) - 0.0003667*math.Sin(b)
+
+	return math.Mod(math.Mod(longitude, 360)+360, 360)
+}
+
+// solarLongitudeAfter returns the first time after t the apparent longitude
+// of the sun is longitude
+func solarLongitudeAfter(longitude float64, t time.Time) time.Time {
+	days := func(degrees float64) time.Duration {
+		return time.Duration(degrees / 360 * tropicalYear * float64(24*time.Hour))
+	}
+
+	event := t.Add(days(math.Mod(longitude-solarLongitude(t)+360, 360)))
+	for range 5 {
+		diff := math.Mod(longitude-solarLongitude(event)+540, 360) - 180
+		event = event.Add(days(diff))
+	}
+	return event.Truncate(time.Second)
+}
+
+// SolarTerms returns the solar terms of year in China, in order
+func SolarTerms(year int) []SolarTerm {
+	start := chinaMidnight(daysOfDate(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	end := chinaMidnight(daysOfDate(time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)))
+
+	var terms []SolarTerm
+	index := int(math.Ceil(solarLongitude(start)/15)) % 24
+	for t := start; ; index = (index + 1) % 24 {
+		t = solarLongitudeAfter(float64(index*15), t)
+		if !t.Before(end) {
+			return terms
+		}
+		terms = append(terms, SolarTerm{Time: t.UTC(), Name: solarTerms[index].name})
+		t = t.Add(time.Hour)
+	}
+}
+
+// solarTermOn returns the index in solarTerms of the solar term on the day
+// of days in China, false if there is none
+func solarTermOn(days int) (int, bool) {
+	before := int(solarLongitude(chinaMidnight(days)) / 15)
+	after := int(solarLongitude(chinaMidnight(days+1)) / 15)
+	return after % 24, before != after
+}
+
+// daysOfDate returns the days from 1970-01-01 to the date of t, a date at
+// midnight in any time zone
+func daysOfDate(date time.Time) int {
+	return int(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// dateOfDays returns the date of days from 1970-01-01, at midnight UTC
+func dateOfDays(days int) time.Time {
+	return time.Unix(int64(days)*86400, 0).UTC()
+}
+
+// chinaMidnight returns the start of the day of days in China
+func chinaMidnight(days int) time.Time {
+	return time.Unix(int64(days)*86400-chinaOffset, 0).UTC()
+}
+
+// chinaDayOf returns the day of t in China, in days from 1970-01-01
+func chinaDayOf(t time.Time) int {
+	return int(math.Floor(float64(t.Unix()+chinaOffset) / 86400))
+}
+
+// chineseNewMoonOnOrAfter returns the day in China of the first new moon
+// since the start of days
+func chineseNewMoonOnOrAfter(days int) int {
+	start := chinaMidnight(days)
+	k := math.Floor((julianDay(start)-jdNewMoon2000)/synodicMonth) - 1
+	for moonEventAt(k).time.Before(start) {
+		k++
+	}
+	return chinaDayOf(moonEventAt(k).time)
+}
+
+// chineseNewMoonBefore returns the day in China of the last new moon before
+// the start of days
+func chineseNewMoonBefore(days int) int {
+	start := chinaMidnight(days)
+	k := math.Ceil((julianDay(start)-jdNewMoon2000)/synodicMonth) + 1
+	for !moonEventAt(k).time.Before(start) {
+		k--
+	}
+	return chinaDayOf(moonEventAt(k).time)
+}
+
+// chineseWinterSolsticeOnOrBefore returns the day in China of the last winter
+// solstice on or before days
+func chineseWinterSolsticeOnOrBefore(days int) int {
+	solstice := chinaDayOf(solarLongitudeAfter(270, chinaMidnight(days-370)))
+	for {
+		next := chinaDayOf(solarLongitudeAfter(270, chinaMidnight(solstice+1)))
+		if next > days {
+			return solstice
+		}
+		solstice = next
+	}
+}
+
+// chineseMajorSolarTerm returns the principal term in effect at the start of
+// days, numbered as the month it belongs to: the winter solstice is 11
+func chineseMajorSolarTerm(days int) int {
+	return (int(solarLongitude(chinaMidnight(days))/30)+1)%12 + 1
+}
+
+// chineseNoMajorSolarTerm reports if the month starting on days has no
+// principal term
+func chineseNoMajorSolarTerm(days int) bool {
+	return chineseMajorSolarTerm(days) == chineseMajorSolarTerm(chineseNewMoonOnOrAfter(days+1))
+}
+
+// chinesePriorLeapMonth reports if there is a leap month from the month
+// starting on start to the one starting on month
+func chinesePriorLeapMonth(start, month int) bool {
+	for ; month >= start; month = chineseNewMoonBefore(month) {
+		if chineseNoMajorSolarTerm(month) {
+			return true
+		}
+	}
+	return false
+}
+
+// chineseNewYearInSui returns the day of the new year in the year from the
+// winter solstice on or before days to the next one
+func chineseNewYearInSui(days int) int {
+	s1 := chineseWinterSolsticeOnOrBefore(days)
+	s2 := chineseWinterSolsticeOnOrBefore(s1 + 370)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	m13 := chineseNewMoonOnOrAfter(m12 + 1)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+
+	// The leap month of a year of 13 months between the solstices can be
+	// the 12th or the 13th, moving the new year a month
+	if math.Round(float64(nextM11-m12)/synodicMonth) == 12 &&
+		(chineseNoMajorSolarTerm(m12) || chineseNoMajorSolarTerm(m13)) {
+		return chineseNewMoonOnOrAfter(m13 + 1)
+	}
+	return m13
+}
+
+// chineseNewYearOnOrBefore returns the day of the last new year on or before
+// days
+func chineseNewYearOnOrBefore(days int) int {
+	if newYear := chineseNewYearInSui(days); days >= newYear {
+		return newYear
+	}
+	return chineseNewYearInSui(days - 180)
+}
+
+// ChineseFromTime returns the chinese date of the day of t
+func ChineseFromTime(t time.Time) ChineseDate {
+	return chineseFromDays(daysOfDate(t))
+}
+
+func chineseFromDays(days int) ChineseDate {
+	s1 := chineseWinterSolsticeOnOrBefore(days)
+	s2 := chineseWinterSolsticeOnOrBefore(s1 + 370)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+	m := chineseNewMoonBefore(days + 1)
+	leapYear := math.Round(float64(nextM11-m12)/synodicMonth) == 12
+
+	month := int(math.Round(float64(m-m12) / synodicMonth))
+	if leapYear && chinesePriorLeapMonth(m12, m) {
+		month--
+	}
+	month = (month+11)%12 + 1
+
+	return ChineseDate{
+		Year:  dateOfDays(chineseNewYearOnOrBefore(days)).Year(),
+		Month: month,
+		Leap:  leapYear && chineseNoMajorSolarTerm(m) && !chinesePriorLeapMonth(m12, chineseNewMoonBefore(m)),
+		Day:   days - m + 1,
+	}
+}
+
+// days returns the days from 1970-01-01 to date, false if date doesn't exist
+func (date ChineseDate) days() (int, bool) {
+	newYear := chineseNewYearInSui(daysOfDate(time.Date(date.Year, time.July, 1, 0, 0, 0, 0, time.UTC)))
+	start := chineseNewMoonOnOrAfter(newYear + (date.Month-1)*29)
+	if found := chineseFromDays(start); found.Month != date.Month || found.Leap != date.Leap {
+		start = chineseNewMoonOnOrAfter(start + 1)
+	}
+
+	days := start + date.Day - 1
+	found := chineseFromDays(days)
+	return days, found.Month == date.Month && found.Leap == date.Leap && found.Day == date.Day
+}
+
+// MonthName returns the name of the month of date in lang
+func (date ChineseDate) MonthName(lang Language) string {
+	if date.Leap {
+		return fmt.Sprintf("%s %d", lang.Read("Leap month"), date.Month)
+	}
+	return fmt.Sprintf("%s %d", lang.Read("Month"), date.Month)
+}
+
+// chineseDaysOfYear returns the days of year, in the gregorian calendar, that
+// are the day of month of the chinese calendar, not leap: one, or none for
+// the 30th of months of 29 days
+func chineseDaysOfYear(year, month, day int) ([]time.Time, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("invalid chinese month: %d (must be 1-12)", month)
+	}
+	if day < 1 || day > 30 {
+		return nil, fmt.Errorf("invalid chinese day: %d (must be 1-30)", day)
+	}
+
+	var dates []time.Time
+	for _, chineseYear := range []int{year - 1, year} {
+		days, ok := ChineseDate{Year: chineseYear, Month: month, Day: day}.days()
+		if date := dateOfDays(days); ok && date.Year() == year {
+			dates = append(dates, date)
+		}
+	}
+	return dates, nil
+}
+
+// solarTermDaysOfYear returns the day of year, in China, of the solar term
+// with a name in pinyin such as "qingming"
+func solarTermDaysOfYear(year int, pinyin string) ([]time.Time, error) {
+	pinyin = strings.ToLower(strings.TrimSpace(pinyin))
+	for _, name := range solarTerms {
+		if name.pinyin != pinyin {
+			continue
+		}
+		for _, term := range SolarTerms(year) {
+			if term.Name == name.name {
+				return []time.Time{dateOfDays(chinaDayOf(term.Time))}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown solar term: %q (must be a name in pinyin, such as qingming)", pinyin)
+}
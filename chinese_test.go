@@ -0,0 +1,97 @@
+package galendar_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestChineseFromTime(t *testing.T) {
+	for date, expected := range map[string]galendar.ChineseDate{
+		"2023-01-22": {Year: 2023, Month: 1, Day: 1},
+		"2023-03-22": {Year: 2023, Month: 2, Leap: true, Day: 1},
+		"2023-04-20": {Year: 2023, Month: 3, Day: 1},
+		"2024-02-10": {Year: 2024, Month: 1, Day: 1},
+		"2024-09-17": {Year: 2024, Month: 8, Day: 15}, // Mid-Autumn Festival
+		"2025-01-28": {Year: 2024, Month: 12, Day: 29},
+		"2025-01-29": {Year: 2025, Month: 1, Day: 1}, // Chinese New Year
+		"2025-05-31": {Year: 2025, Month: 5, Day: 5}, // Dragon Boat Festival
+		"2025-07-25": {Year: 2025, Month: 6, Leap: true, Day: 1},
+		"2025-10-06": {Year: 2025, Month: 8, Day: 15},
+		"2033-12-22": {Year: 2033, Month: 11, Leap: true, Day: 1}, // the leap month of the "2033 problem"
+		"2034-02-19": {Year: 2034, Month: 1, Day: 1},
+	} {
+		at, _ := time.Parse(time.DateOnly, date)
+		if got := galendar.ChineseFromTime(at); got != expected {
+			t.Errorf("Expected %+v for %s, got %+v", expected, date, got)
+		}
+	}
+}
+
+func TestSolarTerms(t *testing.T) {
+	terms := galendar.SolarTerms(2025)
+	if len(terms) != 24 {
+		t.Fatalf("Expected 24 solar terms, got %d", len(terms))
+	}
+
+	china := time.FixedZone("CST", 8*60*60)
+	for i, expected := range map[int]struct {
+		name string
+		time time.Time
+	}{
+		0:  {"Minor cold", time.Date(2025, time.January, 5, 10, 33, 0, 0, china)},
+		2:  {"Start of spring", time.Date(2025, time.February, 3, 22, 10, 0, 0, china)},
+		6:  {"Pure brightness", time.Date(2025, time.April, 4, 20, 48, 0, 0, china)},
+		11: {"Summer solstice", time.Date(2025, time.June, 21, 10, 42, 0, 0, china)},
+		23: {"Winter solstice", time.Date(2025, time.December, 21, 23, 3, 0, 0, china)},
+	} {
+		if terms[i].Name != expected.name || terms[i].Time.Sub(expected.time).Abs() > 2*time.Minute {
+			t.Errorf("Expected %s at %s, got %s at %s", expected.name, expected.time, terms[i].Name, terms[i].Time.In(china))
+		}
+	}
+}
+
+func TestLoadSpecialDaysFromFile_Chinese(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "chinese:1/1"
+text = "Chinese New Year"
+holiday = true
+
+[[day]]
+when = "chinese:15/8"
+text = "Mid-Autumn Festival"
+
+[[day]]
+when = "chinese:qingming"
+text = "Qingming"
+holiday = true
+`)
+	defer os.Remove(tmpFile)
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, galendar.Config{Year: 2025, Month: 1})
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+	for date, text := range map[string]string{
+		"2025-01-29": "Chinese New Year",
+		"2025-10-06": "Mid-Autumn Festival",
+		"2025-04-04": "Qingming",
+	} {
+		at, _ := time.Parse(time.DateOnly, date)
+		if day := specialDays.At(at); day == nil || day.Note.Text != text {
+			t.Errorf("Expected %s on %s, got %+v", text, date, day)
+		}
+	}
+
+	for _, when := range []string{"chinese:1/13", "chinese:31/1", "chinese:newyear"} {
+		tmpFile := createTempSpecialDaysFile(t, "[[day]]\nwhen = \""+when+"\"\ntext = \"Invalid\"\n")
+		defer os.Remove(tmpFile)
+		if _, err := galendar.LoadSpecialDaysFromFile(tmpFile, galendar.Config{Year: 2025, Month: 1}); err == nil {
+			t.Errorf("Expected an error for %q", when)
+		}
+	}
+}
@@ -20,6 +20,35 @@ func main() {
 }
 
 func run() error {
+	args := os.Args[1:]
+	command := "render"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "render":
+		return render(args)
+	case "icons":
+		return icons(args)
+	default:
+		return fmt.Errorf("unknown command: %q (available: render, icons)", command)
+	}
+}
+
+func icons(args []string) error {
+	if len(args) != 1 || args[0] != "list" {
+		return fmt.Errorf("usage: galendar icons list")
+	}
+
+	for _, name := range galendar.BuiltinIconNames() {
+		fmt.Printf("%-16s icon = \"%s%s\"\n", name, galendar.BuiltinIconPrefix, name)
+	}
+
+	return nil
+}
+
+func render(args []string) error {
 	defaultOutputDir, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("can't read working directory: %w", err)
@@ -31,27 +60,67 @@ func run() error {
 	defaultFont := galendar.DefaultFont
 	defaultWeekStart := time.Sunday.String()
 	defaultLanguage := "es"
+	defaultSVGFonts := string(galendar.SVGFontsReference)
+	defaultNoteOverflow := string(galendar.NoteOverflowTruncate)
 
 	pflag.IntP("month", "m", defaultMonth, "Month: 1-12 to render the month, 0 (or missing) to render the whole year")
 	pflag.IntP("year", "y", defaultYear, "Year")
-	pflag.String("renderer", defaultRenderer, "Output format: pdf or svg")
+	pflag.String("renderer", defaultRenderer, "Output format: pdf, svg or json")
 	pflag.String("week-start", defaultWeekStart, "Week start day: 0-6 (0=Sunday) or day name (sunday, monday, etc.)")
 	pflag.String("config", "", "Path to JSON configuration file")
 	pflag.StringP("output-dir", "o", "", "Output directory, defaults to current directory")
 	pflag.Bool("show-extra-days", false, "Show days outside current month, defaults to false")
+	pflag.Bool("mini-months", false, "Show the previous and next months beside the title, defaults to false")
+	pflag.String("layout", string(galendar.LayoutGrid), "Pages of each month: grid, spread (image page and grid page) or split (image above the grid), images are set per month in the config file, relative to it (images.1 = \"jan.jpg\", images.cover = \"cover.jpg\")")
+	pflag.String("image-fit", string(galendar.ImageFitCrop), "How images fill their area: crop (cover it cutting the borders) or fit (whole image inside it)")
+	pflag.Bool("year-index", false, "Add a page with all the months after the cover, linking to their pages (pdf only), defaults to false")
+	pflag.Bool("appendix", false, "Add pages listing the special days of every month at the end, linked from their cells (pdf only), defaults to false")
+	pflag.String("author", "", "Author in the metadata of the output, optional")
+	pflag.Int("max-rows", 0, "Maximum rows of weeks per month, with 5 the days of a sixth week share their cells with the days a week before, 0 (or missing) means no limit. Days that share a cell show no QR code, sun or day label")
 	pflag.StringP("language", "l", defaultLanguage, "Language to use when rendering the calendar, defaults to es (Spanish)")
 	pflag.StringP("special-days", "s", "", "Special Days filename, optional")
+	pflag.String("from-json", "", "Render a calendar model written by the json renderer instead of computing it, optional")
+	pflag.String("theme", galendar.DefaultThemeName, fmt.Sprintf("Theme: a builtin theme (%s) or path to a theme file", strings.Join(galendar.BuiltinThemeNames(), ", ")))
+	pflag.Float64("font-notes-min-size", galendar.DefaultNoteMinFontSize, "Smallest font size for notes, notes are shrunk down to it to fit in their cells")
+	pflag.String("note-overflow", defaultNoteOverflow, "What to do with notes that don't fit in their cells: truncate (with an ellipsis) or footnote (truncate and write them whole at the bottom of the page)")
+	pflag.String("svg-fonts", defaultSVGFonts, "How the svg renderer outputs fonts: embed (subset in the file), outline (text as paths) or reference (by name)")
+	pflag.Float64("bleed", 0, "Bleed in millimeters, backgrounds extend beyond the trim by this amount (pdf only)")
+	pflag.Bool("crop-marks", false, "Draw crop and registration marks around the trim (pdf only)")
+	pflag.Float64("safe-zone", 0, "Distance in millimeters from the trim kept free of content, the page margin is raised to it if smaller")
+	pflag.Bool("cmyk", false, "Write colors and images in CMYK, colors can be given as cmyk(c, m, y, k) in themes (pdf only)")
+	pflag.Bool("pdfx", false, "Write PDF/X-1a:2001 files: CMYK, embedded fonts and no transparency (pdf only)")
+	pflag.String("output-intent", galendar.DefaultOutputIntent, "Output condition of PDF/X files, a registered characterization (e.g. FOGRA39, GRACoL2006_Coated1v2)")
+	pflag.String("imposition", string(galendar.ImpositionNone), "Arrange the pages on A4 sheets for a saddle-stitched booklet printed duplex flipping on the short edge: none, 2-up (folded into A5) or 4-up (cut across and folded into A6) (pdf only)")
+	pflag.Float64("creep", 0, "Creep compensation in millimeters, the pages of each sheet of a booklet move this much more toward the fold than the ones of the sheet around it")
+	pflag.String("poster", "", "Scale the pages to a poster of this size (a0-a5, letter, legal, tabloid or WxH in millimeters) split into tiles of --tile-paper to be printed and glued together (pdf only)")
+	pflag.String("tile-paper", galendar.DefaultTilePaper, "Paper of the tiles of posters: a4, letter or any size of --poster")
+	pflag.Float64("tile-overlap", galendar.DefaultTileOverlap, "Content in millimeters printed on both neighbour tiles of posters, the cut and glue line with its marks is at its middle")
+	pflag.Bool("preflight", false, "Report the print problems of the document, such as fonts that can't be embedded (pdf only)")
+	pflag.String("footer-qr", "", "Text of a QR code in the footer of month pages, such as the url of the calendar online (footer.qr in the config file)")
+	pflag.Float64("qr-size", galendar.DefaultQRSize, "Size of QR codes in millimeters, QR codes of special days (qr in the special days file) are at most this size to fit in their cells")
+	pflag.String("qr-level", string(galendar.DefaultQRLevel), "Error correction of QR codes: L (7%), M (15%), Q (25%) or H (30%)")
+	pflag.String("moon", string(galendar.MoonDisplayNone), "Days that show the moon: none, phases (new, first quarter, full and last quarter moons with their time) or daily (every day)")
+	pflag.Bool("seasons", false, "Add the equinoxes and solstices of the year to the special days, with the icon and name of the season they start, defaults to false")
+	pflag.String("hemisphere", string(galendar.HemisphereNorth), "Hemisphere of the seasons and the moon: north or south (the seasons are swapped and the moon is mirrored)")
+	pflag.String("timezone", "UTC", "Time zone of the times shown in the calendar, such as the phases of the moon and the sunrises (e.g. America/Argentina/Buenos_Aires or Local)")
+	pflag.String("location", "", "Latitude and longitude in degrees of the sunrises and sunsets shown in the cells (e.g. -34.6,-58.4), optional")
+	pflag.Bool("twilight", false, "Show the civil twilight below the sunrise and sunset (needs --location), defaults to false")
+	pflag.String("day-label", string(galendar.DayLabelNone), "Date of another calendar in the bottom right corner of the cells: none, hijri (day and month of the islamic calendar), hebrew (day and month of the hebrew calendar) or chinese (day of the chinese calendar, its month on the 1st and the 24 solar terms)")
+	pflag.Int("hijri-adjustment", 0, "Days the islamic months start before the tabular calendar, -2 to 2, to follow the sighting of the moon of a country (used by --day-label hijri and hijri:day/month in the special days file)")
+	pflag.Bool("day-length", false, "Show the length of the day and its change since the day before below the sunrise and sunset (needs --location), defaults to false")
 
 	for _, font := range galendar.AllFonts {
 		entity := strings.TrimPrefix(font, "font-")
 		doc := fmt.Sprintf("Font for %s (system font name or path to font file)", entity)
 		pflag.String(font, defaultFont, doc)
-		pflag.Float64(font+"-size", galendar.DefaultFontSizes[font], fmt.Sprintf("Font size for %s", entity))
+		pflag.Float64(font+"-size", 0, fmt.Sprintf("Font size for %s, defaults to the size in the theme", entity))
 		viper.SetDefault(font, defaultFont)
-		viper.SetDefault(font+"-size", galendar.DefaultFontSizes[font])
+		viper.SetDefault(font+"-size", 0)
 	}
 
-	pflag.Parse()
+	if err := pflag.CommandLine.Parse(args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
 
 	viper.SetDefault("month", defaultMonth)
 	viper.SetDefault("year", defaultYear)
@@ -59,13 +128,50 @@ func run() error {
 	viper.SetDefault("week-start", defaultWeekStart)
 	viper.SetDefault("output-dir", defaultOutputDir)
 	viper.SetDefault("show-extra-days", false)
+	viper.SetDefault("mini-months", false)
+	viper.SetDefault("max-rows", 0)
+	viper.SetDefault("year-index", false)
+	viper.SetDefault("appendix", false)
+	viper.SetDefault("author", "")
+	viper.SetDefault("layout", string(galendar.LayoutGrid))
+	viper.SetDefault("image-fit", string(galendar.ImageFitCrop))
 	viper.SetDefault("language", defaultLanguage)
 	viper.SetDefault("special-days", "")
+	viper.SetDefault("from-json", "")
+	viper.SetDefault("svg-fonts", defaultSVGFonts)
+	viper.SetDefault("theme", galendar.DefaultThemeName)
+	viper.SetDefault("font-notes-min-size", galendar.DefaultNoteMinFontSize)
+	viper.SetDefault("note-overflow", defaultNoteOverflow)
+	viper.SetDefault("bleed", 0)
+	viper.SetDefault("crop-marks", false)
+	viper.SetDefault("safe-zone", 0)
+	viper.SetDefault("cmyk", false)
+	viper.SetDefault("pdfx", false)
+	viper.SetDefault("output-intent", galendar.DefaultOutputIntent)
+	viper.SetDefault("preflight", false)
+	viper.SetDefault("imposition", string(galendar.ImpositionNone))
+	viper.SetDefault("creep", 0)
+	viper.SetDefault("poster", "")
+	viper.SetDefault("tile-paper", galendar.DefaultTilePaper)
+	viper.SetDefault("tile-overlap", galendar.DefaultTileOverlap)
+	viper.SetDefault("footer.qr", "")
+	viper.SetDefault("qr-size", galendar.DefaultQRSize)
+	viper.SetDefault("qr-level", string(galendar.DefaultQRLevel))
+	viper.SetDefault("moon", string(galendar.MoonDisplayNone))
+	viper.SetDefault("timezone", "UTC")
+	viper.SetDefault("location", "")
+	viper.SetDefault("twilight", false)
+	viper.SetDefault("day-length", false)
+	viper.SetDefault("day-label", string(galendar.DayLabelNone))
+	viper.SetDefault("hijri-adjustment", 0)
+	viper.SetDefault("seasons", false)
+	viper.SetDefault("hemisphere", string(galendar.HemisphereNorth))
 
 	viper.SetEnvPrefix("galendar")
 	viper.AutomaticEnv()
 
 	viper.BindPFlags(pflag.CommandLine)
+	viper.BindPFlag("footer.qr", pflag.Lookup("footer-qr"))
 
 	configFile := pflag.Lookup("config").Value.String()
 	if configFile != "" {
@@ -85,6 +191,10 @@ func run() error {
 }
 
 func writeCalendar(cfg galendar.Config) error {
+	if cfg.FromJSONFilename != "" {
+		return writeCalendarFromJSON(cfg)
+	}
+
 	month := cfg.Month
 
 	renderFunc := cfg.Renderer.RenderMonth
@@ -93,15 +203,84 @@ func writeCalendar(cfg galendar.Config) error {
 		renderFunc = cfg.Renderer.RenderYear
 	}
 
-	specialDays, err := galendar.LoadSpecialDaysFromFile(cfg.SpecialDaysFilename, cfg)
+	specialDays, err := loadSpecialDays(cfg)
 	if err != nil {
-		return fmt.Errorf("can't load special days file: %w", err)
+		return err
 	}
 
 	cal, err := galendar.NewCalendar(cfg.Year, month, cfg.WeekStart, specialDays)
 	if err != nil {
 		return fmt.Errorf("invalid calendar: %w", err)
 	}
+	cal = cal.In(cfg.TimeZone)
+	if cfg.Coordinates != nil {
+		cal = cal.At(*cfg.Coordinates)
+	}
+	cal, err = cal.Fold(cfg.MaxRows)
+	if err != nil {
+		return fmt.Errorf("invalid calendar: %w", err)
+	}
+
+	err = renderFunc(cfg, cal)
+	if err != nil {
+		return fmt.Errorf("can't generate calendar: %w", err)
+	}
+
+	return nil
+}
+
+// loadSpecialDays loads the special days of cfg.Year and of the years before
+// and after it, that calendars show in their mini months
+func loadSpecialDays(cfg galendar.Config) (galendar.SpecialDays, error) {
+	specialDays := galendar.SpecialDays{}
+	for year := cfg.Year - 1; year <= cfg.Year+1; year++ {
+		yearCfg := cfg
+		yearCfg.Year = year
+
+		days, err := galendar.LoadSpecialDaysFromFile(cfg.SpecialDaysFilename, yearCfg)
+		if err != nil {
+			return nil, fmt.Errorf("can't load special days file: %w", err)
+		}
+		if cfg.Seasons {
+			days = days.Combine(galendar.SeasonSpecialDays(yearCfg))
+		}
+		specialDays = specialDays.Merge(days)
+	}
+
+	return specialDays, nil
+}
+
+func writeCalendarFromJSON(cfg galendar.Config) error {
+	model, err := galendar.LoadJSONCalendarFromFile(cfg.FromJSONFilename)
+	if err != nil {
+		return fmt.Errorf("can't load calendar model: %w", err)
+	}
+
+	// The calendar has the months of the model as they are, a model with more
+	// than one month is rendered as a year of only those months
+	cal, err := model.Calendar()
+	if err != nil {
+		return fmt.Errorf("invalid calendar model: %w", err)
+	}
+
+	cfg.Year = model.Year
+	cfg.WeekStart = cal.WeekStart
+
+	cfg.Month = cal.Month
+	renderFunc := cfg.Renderer.RenderMonth
+	if len(model.Months) > 1 {
+		cfg.Month = 0
+		renderFunc = cfg.Renderer.RenderYear
+	}
+
+	cal = cal.In(cfg.TimeZone)
+	if cfg.Coordinates != nil {
+		cal = cal.At(*cfg.Coordinates)
+	}
+	cal, err = cal.Fold(cfg.MaxRows)
+	if err != nil {
+		return fmt.Errorf("invalid calendar: %w", err)
+	}
 
 	err = renderFunc(cfg, cal)
 	if err != nil {
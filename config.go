@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata" // time zones don't depend on the system
 
 	"github.com/spf13/viper"
 )
@@ -22,27 +25,55 @@ const (
 	FontNotes    = "font-notes"
 )
 
-var DefaultFontSizes = map[string]float64{
-	FontMonths:   24.0,
-	FontWeekdays: 22.0,
-	FontDays:     20.0,
-	FontNotes:    20.0,
-}
+// DefaultFontSizes are the font sizes of the default theme
+//
+// Deprecated: font sizes are in themes, see Theme and LoadTheme
+var DefaultFontSizes = defaultTheme().fontSizes()
 
 var AllFonts = []string{FontMonths, FontWeekdays, FontDays, FontNotes}
 
 // Config holds the application configuration with all values already resolved
 type Config struct {
-	Month               int                // 1-12, 0 means current month
-	Year                int                // 0 means current year
-	WeekStart           time.Weekday       // 0-6, representing Sunday through Saturday
-	Renderer            Renderer           // "pdf" or "svg", default "pdf"
-	OutputDir           string             // Output directory name
-	ShowExtraDays       bool               // show days outside current month (defaults to false)
-	Language            Language           // language to use on the output (defaults to Spanish)
-	Fonts               map[string]string  // Fonts to use by name
-	FontSizes           map[string]float64 // Font sizes
-	SpecialDaysFilename string             // Special days filename (optional, defaults to "")
+	Month         int               // 1-12, 0 means current month
+	Year          int               // 0 means current year
+	WeekStart     time.Weekday      // 0-6, representing Sunday through Saturday
+	Renderer      Renderer          // "pdf", "svg" or "json", default "pdf"
+	OutputDir     string            // Output directory name
+	ShowExtraDays bool              // show days outside current month (defaults to false)
+	MiniMonths    bool              // show the previous and next months beside the title (defaults to false)
+	MaxRows       int               // maximum rows of weeks per month, 5 folds six week months (0 means no limit)
+	Layout        PageLayout        // Pages of a month: "grid", "spread" (image page and grid page) or "split" (image above the grid), default "grid"
+	Images        map[int]string    // Image of each month (1-12) for the spread and split layouts (optional)
+	CoverImage    string            // Image of the cover page, the first page of a year (optional)
+	ImageFit      ImageFit          // How images fill their area: "crop" or "fit", default "crop"
+	YearIndex     bool              // add a page with the months of the year after the cover, linking to them (pdf only, defaults to false)
+	Appendix      bool              // add pages listing the special days of every month, linked from their cells (pdf only, defaults to false)
+	Author        string            // author in the metadata of the output (optional)
+	Language      Language          // language to use on the output (defaults to Spanish)
+	Fonts         map[string]string // Fonts to use by name
+	// Deprecated: font sizes are in Theme, sizes above 0 here override the
+	// ones of Theme when rendering. NewConfig leaves it empty
+	FontSizes           map[string]float64
+	Theme               Theme          // Colors, lines, font sizes and spacing of the output (defaults to the classic theme)
+	SpecialDaysFilename string         // Special days filename (optional, defaults to "")
+	FromJSONFilename    string         // Calendar model to render instead of computing one (optional, defaults to "")
+	SourceDate          time.Time      // Date stamped in output metadata (from SOURCE_DATE_EPOCH, optional)
+	SVGFonts            SVGFontMode    // How the svg renderer outputs fonts: "embed", "outline" or "reference", default "reference"
+	NoteMinFontSize     float64        // Smallest font size notes are shrunk to before truncating them (0 means DefaultNoteMinFontSize)
+	NoteOverflow        NoteOverflow   // What to do with notes that don't fit: "truncate" or "footnote", default "truncate"
+	Print               PrintOptions   // Bleed, marks, colors and PDF/X of the pdf renderer
+	FooterQR            string         // Text of a QR code in the footer of month pages, such as the url of the calendar online (optional)
+	QRSize              float64        // Size of QR codes in millimeters with their quiet zone, QR codes of cells are at most this size (0 means DefaultQRSize)
+	QRLevel             QRLevel        // Error correction of QR codes: "L", "M", "Q" or "H", default "M"
+	Moon                MoonDisplay    // Days that show the moon: "none", "phases" or "daily", default "none"
+	TimeZone            *time.Location // Time zone of the times shown in the calendar, such as the phases of the moon (defaults to UTC)
+	Seasons             bool           // add the equinoxes and solstices to the special days (defaults to false)
+	Hemisphere          Hemisphere     // Hemisphere of the seasons and the moon: "north" or "south", default "north"
+	Coordinates         *Coordinates   // Place of the sunrises and sunsets shown in the cells (optional)
+	Twilight            bool           // show the civil twilight below the sunrise and sunset (defaults to false)
+	DayLength           bool           // show the length of the day and its change since the day before below the sunrise and sunset (defaults to false)
+	DayLabel            DayLabel       // Date of another calendar in the corner of the cells: "none", "hijri", "hebrew" or "chinese", default "none"
+	HijriAdjustment     int            // Days the islamic months start before the tabular calendar, -2 to 2 (defaults to 0)
 }
 
 var weekdayStringToWeekday = map[string]time.Weekday{
@@ -102,11 +133,161 @@ func NewConfig(v *viper.Viper) (Config, error) {
 		return Config{}, fmt.Errorf("invalid language: %q", language)
 	}
 
+	svgFonts, err := ParseSVGFontMode(viper.GetString("svg-fonts"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid svg fonts: %w", err)
+	}
+
+	noteOverflow, err := ParseNoteOverflow(viper.GetString("note-overflow"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid note overflow: %w", err)
+	}
+
+	qrLevel, err := ParseQRLevel(viper.GetString("qr-level"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid qr level: %w", err)
+	}
+
+	qrSize := viper.GetFloat64("qr-size")
+	if qrSize < 0 {
+		return Config{}, fmt.Errorf("invalid qr size: %v (must be 0 or more)", qrSize)
+	}
+
+	footerQR := viper.GetString("footer.qr")
+	if footerQR != "" {
+		if _, err := EncodeQR(footerQR, qrLevel); err != nil {
+			return Config{}, fmt.Errorf("invalid footer qr: %w", err)
+		}
+	}
+
+	moon, err := ParseMoonDisplay(viper.GetString("moon"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid moon: %w", err)
+	}
+
+	timeZone, err := time.LoadLocation(viper.GetString("timezone"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid time zone: %w", err)
+	}
+
+	hemisphere, err := ParseHemisphere(viper.GetString("hemisphere"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid hemisphere: %w", err)
+	}
+
+	coordinates, err := ParseCoordinates(viper.GetString("location"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid location: %w", err)
+	}
+
+	dayLabel, err := ParseDayLabel(viper.GetString("day-label"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid day label: %w", err)
+	}
+
+	hijriAdjustment := viper.GetInt("hijri-adjustment")
+	if hijriAdjustment < -MaxHijriAdjustment || hijriAdjustment > MaxHijriAdjustment {
+		return Config{}, fmt.Errorf("invalid hijri adjustment: %d (must be between %d and %d)", hijriAdjustment, -MaxHijriAdjustment, MaxHijriAdjustment)
+	}
+
+	maxRows := viper.GetInt("max-rows")
+	if maxRows != 0 && maxRows < 5 {
+		return Config{}, fmt.Errorf("invalid max rows: %d (must be 0 or at least 5)", maxRows)
+	}
+
+	layout, err := ParsePageLayout(viper.GetString("layout"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid layout: %w", err)
+	}
+
+	imageFit, err := ParseImageFit(viper.GetString("image-fit"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid image fit: %w", err)
+	}
+
+	// Images are set in the config file, relative to it as the icons of
+	// special days are to their file
+	imagesDir := ""
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		imagesDir = filepath.Dir(configFile)
+	}
+	images, coverImage, err := parseImages(viper.GetStringMapString("images"), imagesDir)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid images: %w", err)
+	}
+
+	imposition, err := ParseImposition(viper.GetString("imposition"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid imposition: %w", err)
+	}
+
+	poster, err := ParsePaperSize(viper.GetString("poster"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid poster: %w", err)
+	}
+
+	tilePaper, err := ParsePaperSize(viper.GetString("tile-paper"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid tile paper: %w", err)
+	}
+	if tilePaper.IsZero() {
+		tilePaper, _ = ParsePaperSize(DefaultTilePaper)
+	}
+
+	print := PrintOptions{
+		Bleed:        viper.GetFloat64("bleed"),
+		Marks:        viper.GetBool("crop-marks"),
+		SafeZone:     viper.GetFloat64("safe-zone"),
+		CMYK:         viper.GetBool("cmyk"),
+		PDFX:         viper.GetBool("pdfx"),
+		OutputIntent: viper.GetString("output-intent"),
+		Preflight:    viper.GetBool("preflight"),
+		Imposition:   imposition,
+		Creep:        viper.GetFloat64("creep"),
+		Poster:       poster,
+		TilePaper:    tilePaper,
+		TileOverlap:  viper.GetFloat64("tile-overlap"),
+	}
+	if print.Bleed < 0 {
+		return Config{}, fmt.Errorf("invalid bleed: %v (must be 0 or more)", print.Bleed)
+	}
+	if print.SafeZone < 0 {
+		return Config{}, fmt.Errorf("invalid safe zone: %v (must be 0 or more)", print.SafeZone)
+	}
+	if print.Creep < 0 {
+		return Config{}, fmt.Errorf("invalid creep: %v (must be 0 or more)", print.Creep)
+	}
+	if imposition != ImpositionNone && (print.Bleed > 0 || print.Marks) {
+		return Config{}, fmt.Errorf("invalid imposition: %s can't be combined with bleed or crop marks", imposition)
+	}
+	if print.TileOverlap < 0 {
+		return Config{}, fmt.Errorf("invalid tile overlap: %v (must be 0 or more)", print.TileOverlap)
+	}
+	if !poster.IsZero() && (imposition != ImpositionNone || print.Bleed > 0 || print.Marks) {
+		return Config{}, fmt.Errorf("invalid poster: tiles can't be combined with imposition, bleed or crop marks")
+	}
+
+	theme, err := LoadTheme(viper.GetString("theme"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid theme: %w", err)
+	}
+
+	var sourceDate time.Time
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %q", epoch)
+		}
+		sourceDate = time.Unix(seconds, 0).UTC()
+	}
+
+	// Font sizes given explicitly override the ones of the theme
 	fonts := map[string]string{}
-	fontSizes := map[string]float64{}
 	for _, font := range AllFonts {
 		fonts[font] = viper.GetString(font)
-		fontSizes[font] = viper.GetFloat64(font + "-size")
+		if size := viper.GetFloat64(font + "-size"); size > 0 {
+			*theme.fontSize(font) = size
+		}
 	}
 
 	return Config{
@@ -116,18 +297,91 @@ func NewConfig(v *viper.Viper) (Config, error) {
 		Renderer:            renderer,
 		OutputDir:           outputDir,
 		ShowExtraDays:       viper.GetBool("show-extra-days"),
+		MiniMonths:          viper.GetBool("mini-months"),
+		MaxRows:             maxRows,
+		Layout:              layout,
+		Images:              images,
+		CoverImage:          coverImage,
+		ImageFit:            imageFit,
+		YearIndex:           viper.GetBool("year-index"),
+		Appendix:            viper.GetBool("appendix"),
+		Author:              viper.GetString("author"),
 		Language:            language,
 		Fonts:               fonts,
-		FontSizes:           fontSizes,
+		Theme:               theme,
 		SpecialDaysFilename: viper.GetString("special-days"),
+		FromJSONFilename:    viper.GetString("from-json"),
+		SourceDate:          sourceDate,
+		SVGFonts:            svgFonts,
+		NoteMinFontSize:     viper.GetFloat64("font-notes-min-size"),
+		NoteOverflow:        noteOverflow,
+		Print:               print,
+		FooterQR:            footerQR,
+		QRSize:              qrSize,
+		QRLevel:             qrLevel,
+		Moon:                moon,
+		TimeZone:            timeZone,
+		Seasons:             viper.GetBool("seasons"),
+		Hemisphere:          hemisphere,
+		Coordinates:         coordinates,
+		Twilight:            viper.GetBool("twilight"),
+		DayLength:           viper.GetBool("day-length"),
+		DayLabel:            dayLabel,
+		HijriAdjustment:     hijriAdjustment,
 	}, nil
 }
 
+// parseImages parses the images of the months by number, 1-12, and the one of
+// the cover, checking that they can be read. Relative paths are relative to
+// baseDir
+func parseImages(values map[string]string, baseDir string) (map[int]string, string, error) {
+	images := map[int]string{}
+	cover := ""
+	for key, filename := range values {
+		if !filepath.IsAbs(filename) && baseDir != "" {
+			filename = filepath.Join(baseDir, filename)
+		}
+		if _, err := os.Stat(filename); err != nil {
+			return nil, "", fmt.Errorf("image %s: %w", key, err)
+		}
+		if key == "cover" {
+			cover = filename
+			continue
+		}
+
+		month, err := strconv.Atoi(key)
+		if err != nil || month < 1 || month > 12 {
+			return nil, "", fmt.Errorf("invalid image key: %q (must be 1-12 or cover)", key)
+		}
+		images[month] = filename
+	}
+
+	return images, cover, nil
+}
+
+// OutputDate returns the date to stamp in the metadata of the output files,
+// it never depends on the current time so the same inputs always produce the
+// same files: SourceDate if set, or January 1st of the calendar year
+func (cfg Config) OutputDate() time.Time {
+	if !cfg.SourceDate.IsZero() {
+		return cfg.SourceDate
+	}
+
+	return time.Date(cfg.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
 func (cfg Config) YearOutputFilePath() string {
 	filename := fmt.Sprintf("%s-%04d.%s", cfg.Language.Read("calendar"), cfg.Year, cfg.Renderer.Name())
 	return path.Join(cfg.OutputDir, filename)
 }
 
+// CoverOutputFilePath returns the file of the cover page for renderers that
+// write a file per page
+func (cfg Config) CoverOutputFilePath() string {
+	filename := fmt.Sprintf("%s-%04d-cover.%s", cfg.Language.Read("calendar"), cfg.Year, cfg.Renderer.Name())
+	return path.Join(cfg.OutputDir, filename)
+}
+
 func (cfg Config) MonthOutputFilePath(cal Calendar) string {
 	filename := fmt.Sprintf("%s-%04d-%02d.%s", cfg.Language.Read("calendar"), cfg.Year, cal.Month, cfg.Renderer.Name())
 	return path.Join(cfg.OutputDir, filename)
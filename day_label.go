@@ -0,0 +1,98 @@
+package galendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DayLabel is the date of another calendar shown in the corner of the cells
+type DayLabel string
+
+const (
+	DayLabelNone    DayLabel = "none"
+	DayLabelHijri   DayLabel = "hijri"   // day and month of the islamic calendar, see HijriFromTime
+	DayLabelHebrew  DayLabel = "hebrew"  // day and month of the hebrew calendar, see HebrewFromTime
+	DayLabelChinese DayLabel = "chinese" // day of the chinese calendar, its month on the 1st and the solar terms, see ChineseFromTime
+)
+
+// ParseDayLabel parses a day label, an empty string means none
+func ParseDayLabel(s string) (DayLabel, error) {
+	switch label := DayLabel(strings.ToLower(strings.TrimSpace(s))); label {
+	case "":
+		return DayLabelNone, nil
+	case DayLabelNone, DayLabelHijri, DayLabelHebrew, DayLabelChinese:
+		return label, nil
+	default:
+		return "", fmt.Errorf("invalid day label: %q (must be none, hijri, hebrew or chinese)", s)
+	}
+}
+
+// dayLabelText is the day label of a day laid out on a page, in millimeters:
+// a line of text in the bottom right corner of the cell
+type dayLabelText struct {
+	right, y float64 // baseline, right is the end of the text
+	size     float64
+	color    Color
+	text     string
+}
+
+// dayLabel returns the date of day in the calendar of config.DayLabel, empty
+// if the cells show none
+func (config Config) dayLabel(day Day) string {
+	switch config.DayLabel {
+	case DayLabelHijri:
+		date := HijriFromTime(day.Date, config.HijriAdjustment)
+		return fmt.Sprintf("%d %s", date.Day, date.MonthName(config.Language))
+	case DayLabelHebrew:
+		date := HebrewFromTime(day.Date)
+		return fmt.Sprintf("%d %s", date.Day, date.MonthName(config.Language))
+	case DayLabelChinese:
+		// As in traditional calendars the solar terms replace the days and
+		// the months replace their first day
+		if term, ok := solarTermOn(daysOfDate(day.Date)); ok {
+			return config.Language.Read(solarTerms[term].name)
+		}
+		date := ChineseFromTime(day.Date)
+		if date.Day == 1 {
+			return date.MonthName(config.Language)
+		}
+		return strconv.Itoa(date.Day)
+	default:
+		return ""
+	}
+}
+
+// dayLabelHeight returns the height taken by the day label at the bottom of
+// the cells, 0 if the cells don't show it
+func (config Config) dayLabelHeight() float64 {
+	if config.DayLabel == DayLabelNone || config.DayLabel == "" {
+		return 0
+	}
+	return config.Theme.DayLabel.TextSize * mmPerPoint * noteLineSpacing
+}
+
+// cellFooterHeight returns the height taken at the bottom of the cells by
+// the sun on the left and the day label on the right
+func (config Config) cellFooterHeight() float64 {
+	return max(config.sunHeight(), config.dayLabelHeight())
+}
+
+// layoutDayLabel lays out the day label of day for a cell with its top left
+// corner at x, y and size cellWidth, rowHeight
+func layoutDayLabel(config Config, day Day, x, y, cellWidth, rowHeight float64) (dayLabelText, bool) {
+	text := config.dayLabel(day)
+	if text == "" || !day.IsCurrentMonth {
+		return dayLabelText{}, false
+	}
+
+	theme := config.Theme
+	height := config.dayLabelHeight()
+	return dayLabelText{
+		right: x + cellWidth - theme.Notes.Padding,
+		y:     lineBaseline(y+rowHeight-theme.Notes.Padding-height, height, 0),
+		size:  theme.DayLabel.TextSize,
+		color: theme.DayLabel.Color,
+		text:  text,
+	}, true
+}
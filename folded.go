@@ -0,0 +1,79 @@
+package galendar
+
+import "strconv"
+
+// foldedHalf is the layout of one of the two days of a folded cell, in
+// millimeters
+type foldedHalf struct {
+	day              Day
+	box              pageRect // the box with the number, filled as a day box
+	number           pageText
+	notesX, notesTop float64
+	icons            []cellIcon
+	moon             *moonGlyph // nil if the day shows no moon, see layoutMoon
+}
+
+// layoutFoldedCell lays out the cell of days, a day and the day folded into
+// it, with its top left corner at x, y. A diagonal from the top right to the
+// bottom left corner splits the cell: the first day has its number box at the
+// top left corner and its notes below it, the second day has its notes at the
+// bottom right quarter and its number box below them, at the bottom right
+// corner. The moons go beside the number boxes and the icons are in rows
+// centered at the top and bottom borders, or after the moons. The QR codes,
+// suns and day labels of folded days are not drawn, the corners where they go
+// have the number box and notes of the second day
+func layoutFoldedCell(config Config, days []Day, x, y, w, h float64) [2]foldedHalf {
+	theme := config.Theme
+	style := theme.Folded
+	boxWidth := w * theme.DayBox.Width
+	iconSize := w * style.IconSize
+
+	var halves [2]foldedHalf
+	for i, day := range days[:2] {
+		half := foldedHalf{
+			day:      day,
+			box:      pageRect{x: x, y: y, w: boxWidth, h: style.Header, fill: theme.dayBoxFill(day)},
+			notesX:   x + theme.Notes.Padding,
+			notesTop: y + style.Header + theme.Notes.Gap,
+		}
+		iconY := y + theme.Icon.Padding
+		if i == 1 {
+			half.box.x, half.box.y = x+w-boxWidth, y+h-style.Header
+			half.notesX, half.notesTop = x+w/2, y+h/2
+			iconY = y + h - theme.Icon.Padding - iconSize
+		}
+
+		icons := day.Icons()
+		iconsWidth := float64(len(icons))*(iconSize+theme.Icon.Padding) - theme.Icon.Padding
+		iconX := x + (w-iconsWidth)/2
+		if glyph, ok := layoutMoon(config, day, x, y, w, boxWidth); ok {
+			// The time of the second day goes above its moon, below it is
+			// the bottom border
+			cx := half.box.x + boxWidth + theme.Notes.Padding + glyph.r
+			if i == 1 {
+				cx = half.box.x - theme.Notes.Padding - glyph.r
+			}
+			glyph = glyph.moved(cx-glyph.cx, half.box.y+style.Header/2-glyph.cy)
+			if i == 1 && glyph.time != nil {
+				glyph.time.y = glyph.cy - glyph.r - 0.3*theme.Moon.TextSize*mmPerPoint
+			}
+			half.moon = &glyph
+
+			iconX = glyph.cx + glyph.r + theme.Icon.Padding
+			if i == 1 {
+				iconX = glyph.cx - glyph.r - theme.Icon.Padding - iconsWidth
+			}
+		}
+		for j, icon := range icons {
+			half.icons = append(half.icons, cellIcon{icon: icon, x: iconX + float64(j)*(iconSize+theme.Icon.Padding), y: iconY, size: iconSize})
+		}
+
+		half.number = pageText{
+			x: half.box.x + boxWidth/2, y: centeredBaseline(half.box.y, style.Header, style.NumberSize),
+			font: FontDays, size: style.NumberSize, color: theme.dayNumberColor(day), text: strconv.Itoa(day.DayNumber),
+		}
+		halves[i] = half
+	}
+
+	return halves
+}
@@ -0,0 +1,306 @@
+package galendar
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adrg/sysfont"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontMetrics measures text using the glyph advances and kerning of a font
+// file, a nil *fontMetrics measures with an approximation
+type fontMetrics struct {
+	font       *sfnt.Font
+	unitsPerEm float64
+	buf        sfnt.Buffer
+	family     string
+	data       []byte // content of the font file, nil for font collections
+}
+
+// fontCache are the metrics and variants of the fonts of a document, loaded
+// once by document so renders never share them
+type fontCache struct {
+	metrics  map[string]*fontMetrics
+	variants map[string]string // by font and style
+}
+
+func newFontCache() *fontCache {
+	return &fontCache{
+		metrics:  map[string]*fontMetrics{},
+		variants: map[string]string{},
+	}
+}
+
+// metricsForFont returns the metrics of a font given by system name or path
+// (the same values accepted by registerFont). Fonts that can't be loaded are
+// reported once and measured with an approximation
+func (fonts *fontCache) metricsForFont(fontName string) *fontMetrics {
+	if metrics, ok := fonts.metrics[fontName]; ok {
+		return metrics
+	}
+
+	metrics, err := loadFontMetrics(fontName)
+	if err != nil {
+		log.Printf("can't load metrics for font %q, using approximated widths: %v", fontName, err)
+	}
+	fonts.metrics[fontName] = metrics
+
+	return metrics
+}
+
+func loadFontMetrics(fontName string) (*fontMetrics, error) {
+	filename, err := resolveFontFile(fontName)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read font file %q: %w", filename, err)
+	}
+
+	var f *sfnt.Font
+	data := content
+	switch {
+	case isFontCollection(filename):
+		collection, err := sfnt.ParseCollection(content)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse font collection %q: %w", filename, err)
+		}
+		f, err = collection.Font(0)
+		if err != nil {
+			return nil, fmt.Errorf("can't read font collection %q: %w", filename, err)
+		}
+		data = nil
+	default:
+		f, err = sfnt.Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse font file %q: %w", filename, err)
+		}
+	}
+
+	metrics := &fontMetrics{
+		font:       f,
+		unitsPerEm: float64(f.UnitsPerEm()),
+		data:       data,
+	}
+
+	metrics.family, err = f.Name(&metrics.buf, sfnt.NameIDTypographicFamily)
+	if err != nil || metrics.family == "" {
+		metrics.family, _ = f.Name(&metrics.buf, sfnt.NameIDFamily)
+	}
+
+	return metrics, nil
+}
+
+// isFontCollection reports if filename is a collection of fonts (.ttc or
+// .otc), that can be measured and drawn in SVG but not embedded in PDF
+func isFontCollection(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ttc", ".otc":
+		return true
+	}
+	return false
+}
+
+// resolveFontFile returns the file of a font given by system name or path
+func resolveFontFile(fontName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fontName)) {
+	case ".ttf", ".otf", ".ttc", ".otc":
+		return fontName, nil
+	}
+
+	fontsFinder := sysfont.NewFinder(nil)
+	found := fontsFinder.Match(fontName)
+	if found == nil {
+		return "", fmt.Errorf("font %q not found", fontName)
+	}
+
+	return found.Filename, nil
+}
+
+// fontVariant returns the file of the bold, italic or bold italic font of the
+// family of fontName, or an empty string if there is none and the style has
+// to be faked. Files are looked for beside the file of fontName, replacing
+// "Regular" in its name or adding the style to it, as in "Go-Regular.ttf" and
+// "Go-Bold.ttf" or "DejaVuSans.ttf" and "DejaVuSans-Oblique.ttf"
+func (fonts *fontCache) fontVariant(fontName string, style textStyle) string {
+	if !style.bold && !style.italic {
+		return fontName
+	}
+
+	key := fmt.Sprintf("%s|%t|%t", fontName, style.bold, style.italic)
+	if variant, ok := fonts.variants[key]; ok {
+		return variant
+	}
+
+	variant := findFontVariant(fontName, style)
+	fonts.variants[key] = variant
+
+	return variant
+}
+
+func findFontVariant(fontName string, style textStyle) string {
+	var names []string
+	switch {
+	case style.bold && style.italic:
+		names = []string{"BoldItalic", "BoldOblique", "Bold Italic"}
+	case style.bold:
+		names = []string{"Bold"}
+	default:
+		names = []string{"Italic", "Oblique"}
+	}
+
+	filename, err := resolveFontFile(fontName)
+	if err != nil {
+		return ""
+	}
+
+	dir, base := filepath.Split(filename)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for _, name := range names {
+		candidates := []string{stem + "-" + name + ext, stem + name + ext}
+		if strings.Contains(stem, "Regular") {
+			candidates = []string{strings.Replace(stem, "Regular", name, 1) + ext}
+		}
+		for _, candidate := range candidates {
+			if _, err := os.Stat(filepath.Join(dir, candidate)); err == nil {
+				return filepath.Join(dir, candidate)
+			}
+		}
+	}
+
+	// System fonts are matched by name, the match must have the style
+	if fontName == filename {
+		return ""
+	}
+	found := sysfont.NewFinder(nil).Match(fontName + " " + names[0])
+	if found == nil {
+		return ""
+	}
+	lower := strings.ToLower(filepath.Base(found.Filename))
+	hasItalic := strings.Contains(lower, "italic") || strings.Contains(lower, "oblique")
+	if strings.Contains(lower, "bold") != style.bold || hasItalic != style.italic {
+		return ""
+	}
+	return found.Filename
+}
+
+// textWidth returns the width of text rendered at size, in the same unit as
+// size
+func (metrics *fontMetrics) textWidth(text string, size float64) float64 {
+	if metrics == nil {
+		// Approximate average character width (most fonts are roughly 0.6x the font size)
+		return float64(utf8.RuneCountInString(text)) * size * 0.6
+	}
+
+	// measure in font units and scale to size
+	ppem := fixed.Int26_6(metrics.unitsPerEm * 64)
+	width := fixed.Int26_6(0)
+	prev, hasPrev := sfnt.GlyphIndex(0), false
+
+	for _, r := range text {
+		index, err := metrics.font.GlyphIndex(&metrics.buf, r)
+		if err != nil || index == 0 {
+			// missing glyphs are drawn by viewers with some fallback font
+			width += fixed.Int26_6(metrics.unitsPerEm * 0.6 * 64)
+			hasPrev = false
+			continue
+		}
+
+		if hasPrev {
+			kern, err := metrics.font.Kern(&metrics.buf, prev, index, ppem, font.HintingNone)
+			if err == nil {
+				width += kern
+			}
+		}
+
+		advance, err := metrics.font.GlyphAdvance(&metrics.buf, index, ppem, font.HintingNone)
+		if err == nil {
+			width += advance
+		}
+
+		prev, hasPrev = index, true
+	}
+
+	return float64(width) / 64 / metrics.unitsPerEm * size
+}
+
+// measure returns a function that measures text at size, as used by wrapText
+func (metrics *fontMetrics) measure(size float64) func(string) float64 {
+	return func(text string) float64 {
+		return metrics.textWidth(text, size)
+	}
+}
+
+// wrapText breaks text into lines that fit within maxWidth as measured by
+// measure. Explicit line breaks are kept and words longer than a line are
+// broken at the widest prefix that fits
+func wrapText(text string, maxWidth float64, measure func(string) float64) []string {
+	if text == "" {
+		return []string{text}
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		currentLine := ""
+		for _, word := range words {
+			testLine := word
+			if currentLine != "" {
+				testLine = currentLine + " " + word
+			}
+
+			if measure(testLine) <= maxWidth {
+				currentLine = testLine
+				continue
+			}
+
+			if currentLine != "" {
+				lines = append(lines, currentLine)
+			}
+
+			// Break words that don't fit in a line by themselves
+			for measure(word) > maxWidth {
+				prefix := widestPrefix(word, maxWidth, measure)
+				lines = append(lines, prefix)
+				word = word[len(prefix):]
+			}
+			currentLine = word
+		}
+
+		if currentLine != "" {
+			lines = append(lines, currentLine)
+		}
+	}
+
+	return lines
+}
+
+// widestPrefix returns the longest prefix of word (at least one rune) that
+// fits in maxWidth
+func widestPrefix(word string, maxWidth float64, measure func(string) float64) string {
+	_, size := utf8.DecodeRuneInString(word)
+	end := size
+	for end < len(word) {
+		_, size := utf8.DecodeRuneInString(word[end:])
+		if measure(word[:end+size]) > maxWidth {
+			break
+		}
+		end += size
+	}
+	return word[:end]
+}
@@ -0,0 +1,237 @@
+package galendar
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// subsetTrueType returns a copy of a TrueType font where every glyph not
+// needed to draw runes is emptied. Glyph indices don't change, so the cmap,
+// metrics and kerning tables are kept as they are and only glyf and loca are
+// rebuilt. CFF based fonts (OpenType with PostScript outlines) can't be
+// subset this way and are returned unchanged
+func subsetTrueType(data []byte, f *sfnt.Font, runes map[rune]bool) ([]byte, error) {
+	tables, err := readFontTables(data)
+	if err != nil {
+		return nil, err
+	}
+
+	glyf, hasGlyf := tables["glyf"]
+	loca, hasLoca := tables["loca"]
+	head, hasHead := tables["head"]
+	maxp, hasMaxp := tables["maxp"]
+	if !hasGlyf || !hasLoca {
+		return data, nil
+	}
+	if !hasHead || len(head) < 54 || !hasMaxp || len(maxp) < 6 {
+		return nil, fmt.Errorf("invalid font: missing or short head/maxp tables")
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+	offsets := make([]uint32, numGlyphs+1)
+	for i := range offsets {
+		if longLoca {
+			if 4*i+4 > len(loca) {
+				return nil, fmt.Errorf("invalid font: short loca table")
+			}
+			offsets[i] = binary.BigEndian.Uint32(loca[4*i:])
+		} else {
+			if 2*i+2 > len(loca) {
+				return nil, fmt.Errorf("invalid font: short loca table")
+			}
+			offsets[i] = uint32(binary.BigEndian.Uint16(loca[2*i:])) * 2
+		}
+	}
+	glyphData := func(index int) []byte {
+		start, end := offsets[index], offsets[index+1]
+		if end <= start || int(end) > len(glyf) {
+			return nil
+		}
+		return glyf[start:end]
+	}
+
+	// .notdef is always kept, composite glyphs need their components
+	keep := map[int]bool{0: true}
+	var pending []int
+	var buf sfnt.Buffer
+	for r := range runes {
+		index, err := f.GlyphIndex(&buf, r)
+		if err == nil && index != 0 && int(index) < numGlyphs {
+			pending = append(pending, int(index))
+		}
+	}
+	for len(pending) > 0 {
+		index := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if keep[index] {
+			continue
+		}
+		keep[index] = true
+		for _, component := range compositeComponents(glyphData(index)) {
+			if component < numGlyphs && !keep[component] {
+				pending = append(pending, component)
+			}
+		}
+	}
+
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for i := range numGlyphs {
+		binary.BigEndian.PutUint32(newLoca[4*i:], uint32(len(newGlyf)))
+		if keep[i] {
+			newGlyf = append(newGlyf, glyphData(i)...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(len(newGlyf)))
+
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+
+	tables["glyf"] = newGlyf
+	tables["loca"] = newLoca
+	tables["head"] = newHead
+	// signatures are invalid once the font changes
+	delete(tables, "DSIG")
+
+	return writeFontTables(tables), nil
+}
+
+// compositeComponents returns the glyph indices used by a composite glyph
+func compositeComponents(glyph []byte) []int {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+
+	const (
+		argsAreWords    = 0x0001
+		haveScale       = 0x0008
+		moreComponents  = 0x0020
+		haveXYScale     = 0x0040
+		haveTwoByTwo    = 0x0080
+		componentHeader = 4
+	)
+
+	var components []int
+	pos := 10
+	for pos+componentHeader <= len(glyph) {
+		flags := binary.BigEndian.Uint16(glyph[pos:])
+		components = append(components, int(binary.BigEndian.Uint16(glyph[pos+2:])))
+		pos += componentHeader
+		if flags&argsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			pos += 2
+		case flags&haveXYScale != 0:
+			pos += 4
+		case flags&haveTwoByTwo != 0:
+			pos += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+
+	return components
+}
+
+func readFontTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("invalid font: too short")
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, fmt.Errorf("invalid font: short table directory")
+	}
+
+	tables := map[string][]byte{}
+	for i := range numTables {
+		record := data[12+16*i:]
+		tag := string(record[:4])
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("invalid font: table %q out of bounds", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	tables["\x00version"] = data[:4]
+
+	return tables, nil
+}
+
+// writeFontTables builds a font file with the given tables, computing the
+// checksums of the tables and the checksum adjustment of the head table
+func writeFontTables(tables map[string][]byte) []byte {
+	version := tables["\x00version"]
+	delete(tables, "\x00version")
+
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	header := make([]byte, 12+16*numTables)
+	copy(header, version)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(numTables*16-searchRange))
+
+	out := header
+	headOffset := -1
+	for i, tag := range tags {
+		table := tables[tag]
+		if tag == "head" {
+			table = append([]byte(nil), table...)
+			binary.BigEndian.PutUint32(table[8:], 0)
+			headOffset = len(out)
+		}
+
+		record := out[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], fontChecksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-fontChecksum(out))
+	}
+
+	return out
+}
+
+func fontChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -2,12 +2,17 @@ module github.com/unkiwii/galendar
 
 go 1.24.3
 
-require github.com/jung-kurt/gofpdf v1.16.2
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/adrg/sysfont v0.1.2
+	github.com/jung-kurt/gofpdf v1.16.2
+	github.com/spf13/pflag v1.0.10
+	github.com/spf13/viper v1.21.0
+	golang.org/x/image v0.25.0
+)
 
 require (
-	github.com/BurntSushi/toml v1.6.0 // indirect
 	github.com/adrg/strutil v0.2.2 // indirect
-	github.com/adrg/sysfont v0.1.2 // indirect
 	github.com/adrg/xdg v0.3.0 // indirect
 	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
@@ -16,8 +21,6 @@ require (
 	github.com/sourcegraph/conc v0.3.1-0.20240121214520-5f936abd7ae8 // indirect
 	github.com/spf13/afero v1.15.0 // indirect
 	github.com/spf13/cast v1.10.0 // indirect
-	github.com/spf13/pflag v1.0.10 // indirect
-	github.com/spf13/viper v1.21.0 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/sys v0.29.0 // indirect
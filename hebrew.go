@@ -0,0 +1,201 @@
+package galendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HebrewDate is a date of the hebrew calendar. Months are numbered from
+// Nisan, 1, to Adar, 12, and Adar II, 13, in leap years; years start on the
+// 1st of Tishrei, 7
+type HebrewDate struct {
+	Year, Month, Day int
+}
+
+// Hebrew months, by number
+const (
+	hebrewNisan   = 1
+	hebrewTishrei = 7
+	hebrewAdar    = 12 // Adar I in leap years
+	hebrewAdarII  = 13 // only in leap years
+)
+
+// hebrewEpoch is the 1st of Tishrei of the year 1, October 7th of 3761 BCE
+// in the julian calendar, in days since 1970-01-01
+const hebrewEpoch = -2092590
+
+// hebrewMonthNames are the names of the hebrew months from Nisan, to be
+// translated, Adar is Adar I in leap years
+var hebrewMonthNames = []string{
+	"Nisan", "Iyar", "Sivan", "Tammuz", "Av", "Elul",
+	"Tishrei", "Cheshvan", "Kislev", "Tevet", "Shevat", "Adar", "Adar II",
+}
+
+// hebrewMonthsByName are the months of the names accepted in special days,
+// lowercase: Adar is -1, Adar II in leap years as the holidays of Adar, and
+// Adar I and Adar II are Adar in common years
+var hebrewMonthsByName = map[string]int{
+	"nisan": 1, "iyar": 2, "iyyar": 2, "sivan": 3, "tammuz": 4, "tamuz": 4, "av": 5, "elul": 6,
+	"tishrei": 7, "tishri": 7, "cheshvan": 8, "heshvan": 8, "marcheshvan": 8, "kislev": 9,
+	"tevet": 10, "teveth": 10, "shevat": 11, "shvat": 11,
+	"adar": -1, "adar i": 12, "adar 1": 12, "adar ii": 13, "adar 2": 13,
+}
+
+// HebrewFromTime returns the hebrew date of the day of t. Hebrew days start
+// at sunset, this is the date that ends at the sunset of the day
+func HebrewFromTime(t time.Time) HebrewDate {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(date.Unix() / 86400)
+
+	// The mean hebrew year is 35975351/98496 days
+	year := (days - hebrewEpoch) * 98496 / 35975351
+	for hebrewNewYear(year+1) <= days {
+		year++
+	}
+
+	month := hebrewNisan
+	if days < (HebrewDate{year, hebrewNisan, 1}).days() {
+		month = hebrewTishrei
+	}
+	for (HebrewDate{year, month, hebrewMonthLength(year, month)}).days() < days {
+		month++
+	}
+
+	return HebrewDate{Year: year, Month: month, Day: days - (HebrewDate{year, month, 1}).days() + 1}
+}
+
+// Time returns the day of date in UTC at midnight
+func (date HebrewDate) Time() time.Time {
+	return time.Unix(int64(date.days())*86400, 0).UTC()
+}
+
+// MonthName returns the name of the month of date in lang, Adar is Adar I in
+// leap years
+func (date HebrewDate) MonthName(lang Language) string {
+	if date.Month == hebrewAdar && hebrewLeapYear(date.Year) {
+		return lang.Read("Adar I")
+	}
+	return lang.Read(hebrewMonthNames[date.Month-1])
+}
+
+// days returns the days from 1970-01-01 to date
+func (date HebrewDate) days() int {
+	days := hebrewNewYear(date.Year) + date.Day - 1
+	if date.Month < hebrewTishrei {
+		for month := hebrewTishrei; month <= hebrewMonths(date.Year); month++ {
+			days += hebrewMonthLength(date.Year, month)
+		}
+		for month := hebrewNisan; month < date.Month; month++ {
+			days += hebrewMonthLength(date.Year, month)
+		}
+	} else {
+		for month := hebrewTishrei; month < date.Month; month++ {
+			days += hebrewMonthLength(date.Year, month)
+		}
+	}
+	return days
+}
+
+// hebrewLeapYear reports if year has Adar II, 7 of every 19 years do
+func hebrewLeapYear(year int) bool {
+	return (7*year+1)%19 < 7
+}
+
+// hebrewMonths returns the number of the last month of year
+func hebrewMonths(year int) int {
+	if hebrewLeapYear(year) {
+		return hebrewAdarII
+	}
+	return hebrewAdar
+}
+
+// hebrewElapsedDays returns the days from the epoch to the molad of Tishrei
+// of year, postponed a day if it falls on a sunday, wednesday or friday (lo
+// ADU Rosh) or at noon or later (molad zaken)
+func hebrewElapsedDays(year int) int {
+	months := (235*year - 234) / 19
+	parts := 12084 + 13753*months
+	days := 29*months + parts/25920
+	if (3*(days+1))%7 < 3 {
+		days++
+	}
+	return days
+}
+
+// hebrewNewYear returns the days from 1970-01-01 to the 1st of Tishrei of
+// year, after the postponements that keep the years from having 356 days
+// (GaTaRaD) or the year before 382 (BeTU'TaKPaT)
+func hebrewNewYear(year int) int {
+	days := hebrewElapsedDays(year)
+	switch {
+	case hebrewElapsedDays(year+1)-days == 356:
+		days += 2
+	case days-hebrewElapsedDays(year-1) == 382:
+		days++
+	}
+	return hebrewEpoch + days
+}
+
+// hebrewMonthLength returns the days of month in year: Cheshvan and Kislev
+// have 30 and 29 days in years of 355 and 353 days, plus 30 in leap years
+func hebrewMonthLength(year, month int) int {
+	yearLength := hebrewNewYear(year+1) - hebrewNewYear(year)
+	switch {
+	case month == 2 || month == 4 || month == 6 || month == 10 || month == hebrewAdarII:
+		return 29
+	case month == hebrewAdar && !hebrewLeapYear(year):
+		return 29
+	case month == 8 && yearLength%10 != 5:
+		return 29
+	case month == 9 && yearLength%10 == 3:
+		return 29
+	default:
+		return 30
+	}
+}
+
+// parseHebrewDayMonth parses the day and month name of a hebrew date, such
+// as "15 nisan", see hebrewMonthsByName
+func parseHebrewDayMonth(s string) (day, month int, err error) {
+	d, name, _ := strings.Cut(strings.TrimSpace(s), " ")
+	day, err = strconv.Atoi(d)
+	month, ok := hebrewMonthsByName[strings.Join(strings.Fields(strings.ToLower(name)), " ")]
+	if err != nil || !ok {
+		return 0, 0, fmt.Errorf("can't parse %q as day and month name, such as 15 nisan", s)
+	}
+	if day < 1 || day > 30 {
+		return 0, 0, fmt.Errorf("invalid hebrew day: %d (must be 1-30)", day)
+	}
+	return day, month, nil
+}
+
+// hebrewDaysOfYear returns the days of year, in the gregorian calendar, that
+// are the day of month of the hebrew calendar, see parseHebrewDayMonth. Days
+// at the start or end of the year can happen twice or not at all
+func hebrewDaysOfYear(year, month, day int) []time.Time {
+	first := HebrewFromTime(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+	last := HebrewFromTime(time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+
+	var dates []time.Time
+	for hebrewYear := first.Year; hebrewYear <= last.Year; hebrewYear++ {
+		m := month
+		switch {
+		case m == -1 && hebrewLeapYear(hebrewYear):
+			m = hebrewAdarII
+		case m == -1 || m == hebrewAdarII && !hebrewLeapYear(hebrewYear):
+			m = hebrewAdar
+		}
+
+		// The 30th of a month of 29 days doesn't exist
+		if day > hebrewMonthLength(hebrewYear, m) {
+			continue
+		}
+		if date := (HebrewDate{hebrewYear, m, day}).Time(); date.Year() == year {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
@@ -0,0 +1,100 @@
+package galendar_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestHebrewFromTime(t *testing.T) {
+	for _, tc := range []struct {
+		date     time.Time
+		expected galendar.HebrewDate
+	}{
+		{time.Date(2025, time.April, 13, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5785, Month: 1, Day: 15}},    // Passover
+		{time.Date(2025, time.September, 23, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5786, Month: 7, Day: 1}}, // Rosh Hashanah
+		{time.Date(2025, time.October, 2, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5786, Month: 7, Day: 10}},   // Yom Kippur
+		{time.Date(2025, time.December, 15, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5786, Month: 9, Day: 25}}, // Hanukkah
+		{time.Date(2024, time.March, 24, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5784, Month: 13, Day: 14}},   // Purim of a leap year
+		{time.Date(2023, time.September, 16, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5784, Month: 7, Day: 1}},
+		{time.Date(1948, time.May, 14, 0, 0, 0, 0, time.UTC), galendar.HebrewDate{Year: 5708, Month: 2, Day: 5}},
+	} {
+		got := galendar.HebrewFromTime(tc.date)
+		if got != tc.expected {
+			t.Errorf("Expected %+v for %s, got %+v", tc.expected, tc.date.Format(time.DateOnly), got)
+		}
+		if back := got.Time(); !back.Equal(tc.date) {
+			t.Errorf("Expected %+v to be %s, got %s", got, tc.date.Format(time.DateOnly), back.Format(time.DateOnly))
+		}
+	}
+
+	for date, expected := range map[galendar.HebrewDate]string{
+		{Year: 5784, Month: 12, Day: 1}: "Adar I",
+		{Year: 5784, Month: 13, Day: 1}: "Adar II",
+		{Year: 5785, Month: 12, Day: 1}: "Adar",
+		{Year: 5785, Month: 8, Day: 1}:  "Jeshván",
+	} {
+		lang := galendar.English
+		if date.Month == 8 {
+			lang = galendar.Spanish
+		}
+		if name := date.MonthName(lang); name != expected {
+			t.Errorf("Expected the month of %+v to be %q, got %q", date, expected, name)
+		}
+	}
+}
+
+func TestLoadSpecialDaysFromFile_Hebrew(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "hebrew:15 nisan"
+text = "Passover"
+holiday = true
+
+[[day]]
+when = "hebrew:1 Tishrei"
+text = "Rosh Hashanah"
+
+[[day]]
+when = "hebrew:14 adar"
+text = "Purim"
+
+[[day]]
+when = "hebrew:30 kislev"
+text = "Hanukkah"
+`)
+	defer os.Remove(tmpFile)
+
+	for _, tc := range []struct {
+		year     int
+		expected map[string]string
+	}{
+		{2024, map[string]string{"2024-04-23": "Passover", "2024-10-03": "Rosh Hashanah", "2024-03-24": "Purim", "2024-12-31": "Hanukkah"}},
+		{2025, map[string]string{"2025-04-13": "Passover", "2025-09-23": "Rosh Hashanah", "2025-03-14": "Purim", "2025-12-20": "Hanukkah"}},
+	} {
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, galendar.Config{Year: tc.year, Month: 1})
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+		if len(specialDays) != len(tc.expected) {
+			t.Errorf("Expected %d special days in %d, got %d", len(tc.expected), tc.year, len(specialDays))
+		}
+		for date, text := range tc.expected {
+			at, _ := time.Parse(time.DateOnly, date)
+			if day := specialDays.At(at); day == nil || day.Note.Text != text {
+				t.Errorf("Expected %s on %s, got %+v", text, date, day)
+			}
+		}
+	}
+
+	for _, when := range []string{"hebrew:15", "hebrew:15 january", "hebrew:31 nisan"} {
+		tmpFile := createTempSpecialDaysFile(t, "[[day]]\nwhen = \""+when+"\"\ntext = \"Invalid\"\n")
+		defer os.Remove(tmpFile)
+		if _, err := galendar.LoadSpecialDaysFromFile(tmpFile, galendar.Config{Year: 2025, Month: 1}); err == nil {
+			t.Errorf("Expected an error for %q", when)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package galendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// HijriDate is a date of the tabular islamic calendar: 12 months of 30 and 29
+// days alternating, the last one of 30 days in the 11 leap years of every 30
+type HijriDate struct {
+	Year, Month, Day int
+}
+
+// hijriEpoch is the 1st of Muharram of the year 1, July 16th of 622 in the
+// julian calendar, in days since 1970-01-01
+const hijriEpoch = -492148
+
+// hijriMonthNames are the names of the islamic months, to be translated
+var hijriMonthNames = []string{
+	"Muharram", "Safar", "Rabi al-Awwal", "Rabi al-Thani", "Jumada al-Awwal", "Jumada al-Thani",
+	"Rajab", "Shaban", "Ramadan", "Shawwal", "Dhu al-Qadah", "Dhu al-Hijjah",
+}
+
+// MaxHijriAdjustment is the most days the islamic calendar can be moved from
+// the tabular one, to follow the sighting of the moon of a country
+const MaxHijriAdjustment = 2
+
+// HijriFromTime returns the islamic date of the day of t, moved adjustment
+// days: with an adjustment of 1 the islamic months start a day earlier than
+// in the tabular calendar
+func HijriFromTime(t time.Time, adjustment int) HijriDate {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(date.Unix()/86400) + adjustment - hijriEpoch
+
+	year := (30*days + 10646) / 10631
+	dayOfYear := days - hijriYearStart(year)
+	month := 12
+	for hijriMonthStart(month) > dayOfYear {
+		month--
+	}
+
+	return HijriDate{Year: year, Month: month, Day: dayOfYear - hijriMonthStart(month) + 1}
+}
+
+// Time returns the day of date in UTC at midnight, moved adjustment days as
+// in HijriFromTime
+func (date HijriDate) Time(adjustment int) time.Time {
+	days := hijriYearStart(date.Year) + hijriMonthStart(date.Month) + date.Day - 1
+	return time.Unix(int64(days+hijriEpoch-adjustment)*86400, 0).UTC()
+}
+
+// MonthName returns the name of the month of date in lang
+func (date HijriDate) MonthName(lang Language) string {
+	return lang.Read(hijriMonthNames[date.Month-1])
+}
+
+// hijriYearStart returns the days from the epoch to the start of year
+func hijriYearStart(year int) int {
+	return (year-1)*354 + (3+11*year)/30
+}
+
+// hijriMonthStart returns the days from the start of a year to the start of
+// month
+func hijriMonthStart(month int) int {
+	return (59*(month-1) + 1) / 2
+}
+
+// hijriDaysOfYear returns the days of year, in the gregorian calendar, that
+// are the day of month of the islamic calendar: one or two, as islamic years
+// are 11 days shorter
+func hijriDaysOfYear(year, month, day, adjustment int) ([]time.Time, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("invalid hijri month: %d (must be 1-12)", month)
+	}
+	if day < 1 || day > 30 {
+		return nil, fmt.Errorf("invalid hijri day: %d (must be 1-30)", day)
+	}
+
+	first := HijriFromTime(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), adjustment)
+	last := HijriFromTime(time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC), adjustment)
+
+	var dates []time.Time
+	for hijriYear := first.Year; hijriYear <= last.Year; hijriYear++ {
+		// The 30th of a month of 29 days doesn't exist, it's the 1st of the next
+		if day == 30 && HijriFromTime(HijriDate{hijriYear, month, 30}.Time(adjustment), adjustment).Day != 30 {
+			continue
+		}
+		if date := (HijriDate{hijriYear, month, day}).Time(adjustment); date.Year() == year {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates, nil
+}
@@ -0,0 +1,100 @@
+package galendar_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestHijriFromTime(t *testing.T) {
+	for _, tc := range []struct {
+		date       time.Time
+		adjustment int
+		expected   galendar.HijriDate
+	}{
+		{time.Date(622, time.July, 19, 0, 0, 0, 0, time.UTC), 0, galendar.HijriDate{Year: 1, Month: 1, Day: 1}},
+		{time.Date(1979, time.November, 21, 0, 0, 0, 0, time.UTC), 0, galendar.HijriDate{Year: 1400, Month: 1, Day: 1}},
+		{time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), 0, galendar.HijriDate{Year: 1446, Month: 9, Day: 1}},
+		{time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC), 0, galendar.HijriDate{Year: 1446, Month: 9, Day: 30}},
+		{time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC), 1, galendar.HijriDate{Year: 1446, Month: 10, Day: 1}},
+		{time.Date(2025, time.March, 30, 23, 0, 0, 0, time.FixedZone("AST", 3*60*60)), -1, galendar.HijriDate{Year: 1446, Month: 9, Day: 29}},
+	} {
+		got := galendar.HijriFromTime(tc.date, tc.adjustment)
+		if got != tc.expected {
+			t.Errorf("Expected %+v for %s with adjustment %d, got %+v", tc.expected, tc.date.Format(time.DateOnly), tc.adjustment, got)
+		}
+		if back := got.Time(tc.adjustment); back.Format(time.DateOnly) != tc.date.Format(time.DateOnly) {
+			t.Errorf("Expected %+v to be %s with adjustment %d, got %s", got, tc.date.Format(time.DateOnly), tc.adjustment, back.Format(time.DateOnly))
+		}
+	}
+
+	if name := (galendar.HijriDate{Year: 1446, Month: 9, Day: 1}).MonthName(galendar.Spanish); name != "Ramadán" {
+		t.Errorf("Expected Ramadán, got %q", name)
+	}
+}
+
+func TestParseDayLabel(t *testing.T) {
+	for input, expected := range map[string]galendar.DayLabel{
+		"":        galendar.DayLabelNone,
+		"none":    galendar.DayLabelNone,
+		" Hijri":  galendar.DayLabelHijri,
+		"hebrew":  galendar.DayLabelHebrew,
+		"CHINESE": galendar.DayLabelChinese,
+	} {
+		got, err := galendar.ParseDayLabel(input)
+		if err != nil {
+			t.Errorf("ParseDayLabel(%q) failed: %v", input, err)
+		} else if got != expected {
+			t.Errorf("ParseDayLabel(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+
+	if _, err := galendar.ParseDayLabel("mayan"); err == nil {
+		t.Errorf("Expected an error for an unknown day label")
+	}
+}
+
+func TestLoadSpecialDaysFromFile_Hijri(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "hijri:1/9"
+text = "Ramadan ((year))"
+
+[[day]]
+when = "hijri:1/10"
+text = "Eid al-Fitr"
+holiday = true
+`)
+	defer os.Remove(tmpFile)
+
+	// Ramadan starts twice in 2030, and Eid al-Fitr moves a day earlier with
+	// the adjustment
+	cfg := galendar.Config{Year: 2030, Month: 1, HijriAdjustment: 1}
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	for _, date := range []time.Time{
+		time.Date(2030, time.January, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2030, time.December, 25, 0, 0, 0, 0, time.UTC),
+	} {
+		if day := specialDays.At(date); day == nil || day.Note.Text != "Ramadan 2030" {
+			t.Errorf("Expected the start of Ramadan on %s, got %+v", date.Format(time.DateOnly), day)
+		}
+	}
+	if day := specialDays.At(time.Date(2030, time.February, 4, 0, 0, 0, 0, time.UTC)); day == nil || !day.Holiday {
+		t.Errorf("Expected Eid al-Fitr on 2030-02-04, got %+v", day)
+	}
+
+	for _, when := range []string{"hijri:1/13", "hijri:31/1", "hijri:ramadan"} {
+		tmpFile := createTempSpecialDaysFile(t, "[[day]]\nwhen = \""+when+"\"\ntext = \"Invalid\"\n")
+		defer os.Remove(tmpFile)
+		if _, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg); err == nil {
+			t.Errorf("Expected an error for %q", when)
+		}
+	}
+}
@@ -18,63 +18,197 @@ func init() {
 	i18nStrings = map[Language]map[string]string{}
 
 	i18nStrings[English] = map[string]string{
-		"Sunday":    "Sunday",
-		"Sun":       "Sun",
-		"Monday":    "Monday",
-		"Mon":       "Mon",
-		"Tuesday":   "Tuesday",
-		"Tue":       "Tue",
-		"Wednesday": "Wednesday",
-		"Wed":       "Wed",
-		"Thursday":  "Thursday",
-		"Thu":       "Thu",
-		"Friday":    "Friday",
-		"Fri":       "Fri",
-		"Saturday":  "Saturday",
-		"Sat":       "Sat",
-		"January":   "January",
-		"February":  "February",
-		"March":     "March",
-		"April":     "April",
-		"May":       "May",
-		"June":      "June",
-		"July":      "July",
-		"August":    "August",
-		"September": "September",
-		"October":   "October",
-		"November":  "November",
-		"December":  "December",
-		"calendar":  "calendar",
+		"Sunday":          "Sunday",
+		"Sun":             "Sun",
+		"Monday":          "Monday",
+		"Mon":             "Mon",
+		"Tuesday":         "Tuesday",
+		"Tue":             "Tue",
+		"Wednesday":       "Wednesday",
+		"Wed":             "Wed",
+		"Thursday":        "Thursday",
+		"Thu":             "Thu",
+		"Friday":          "Friday",
+		"Fri":             "Fri",
+		"Saturday":        "Saturday",
+		"Sat":             "Sat",
+		"January":         "January",
+		"February":        "February",
+		"March":           "March",
+		"April":           "April",
+		"May":             "May",
+		"June":            "June",
+		"July":            "July",
+		"August":          "August",
+		"September":       "September",
+		"October":         "October",
+		"November":        "November",
+		"December":        "December",
+		"calendar":        "calendar",
+		"page":            "Page",
+		"row":             "Row",
+		"column":          "Column",
+		"Calendar":        "Calendar",
+		"Index":           "Index",
+		"Special days":    "Special days",
+		"Holiday":         "Holiday",
+		"holiday":         "holiday",
+		"Spring":          "Spring",
+		"Summer":          "Summer",
+		"Autumn":          "Autumn",
+		"Winter":          "Winter",
+		"Spring equinox":  "Spring equinox",
+		"Summer solstice": "Summer solstice",
+		"Autumn equinox":  "Autumn equinox",
+		"Winter solstice": "Winter solstice",
+		"Muharram":        "Muharram",
+		"Safar":           "Safar",
+		"Rabi al-Awwal":   "Rabi al-Awwal",
+		"Rabi al-Thani":   "Rabi al-Thani",
+		"Jumada al-Awwal": "Jumada al-Awwal",
+		"Jumada al-Thani": "Jumada al-Thani",
+		"Rajab":           "Rajab",
+		"Shaban":          "Shaban",
+		"Ramadan":         "Ramadan",
+		"Shawwal":         "Shawwal",
+		"Dhu al-Qadah":    "Dhu al-Qadah",
+		"Dhu al-Hijjah":   "Dhu al-Hijjah",
+		"Nisan":           "Nisan",
+		"Iyar":            "Iyar",
+		"Sivan":           "Sivan",
+		"Tammuz":          "Tammuz",
+		"Av":              "Av",
+		"Elul":            "Elul",
+		"Tishrei":         "Tishrei",
+		"Cheshvan":        "Cheshvan",
+		"Kislev":          "Kislev",
+		"Tevet":           "Tevet",
+		"Shevat":          "Shevat",
+		"Adar":            "Adar",
+		"Adar I":          "Adar I",
+		"Adar II":         "Adar II",
+		"Month":           "Month",
+		"Leap month":      "Leap month",
+		"…and %d more":    "…and %d more",
+
+		// Solar terms of the chinese calendar, the equinoxes and solstices are above
+		"Minor cold":           "Minor cold",
+		"Major cold":           "Major cold",
+		"Start of spring":      "Start of spring",
+		"Rain water":           "Rain water",
+		"Awakening of insects": "Awakening of insects",
+		"Pure brightness":      "Pure brightness",
+		"Grain rain":           "Grain rain",
+		"Start of summer":      "Start of summer",
+		"Grain buds":           "Grain buds",
+		"Grain in ear":         "Grain in ear",
+		"Minor heat":           "Minor heat",
+		"Major heat":           "Major heat",
+		"Start of autumn":      "Start of autumn",
+		"End of heat":          "End of heat",
+		"White dew":            "White dew",
+		"Cold dew":             "Cold dew",
+		"Frost descent":        "Frost descent",
+		"Start of winter":      "Start of winter",
+		"Minor snow":           "Minor snow",
+		"Major snow":           "Major snow",
 	}
 
 	i18nStrings[Spanish] = map[string]string{
-		"Sunday":    "Domingo",
-		"Sun":       "D",
-		"Monday":    "Lunes",
-		"Mon":       "L",
-		"Tuesday":   "Martes",
-		"Tue":       "M",
-		"Wednesday": "Miércoles",
-		"Wed":       "M",
-		"Thursday":  "Jueves",
-		"Thu":       "J",
-		"Friday":    "Viernes",
-		"Fri":       "V",
-		"Saturday":  "Sábado",
-		"Sat":       "S",
-		"January":   "Enero",
-		"February":  "Febrero",
-		"March":     "Marzo",
-		"April":     "Abril",
-		"May":       "Mayo",
-		"June":      "Junio",
-		"July":      "Julio",
-		"August":    "Agosto",
-		"September": "Septiembre",
-		"October":   "Octubre",
-		"November":  "Noviembre",
-		"December":  "Diciembre",
-		"calendar":  "calendar",
+		"Sunday":          "Domingo",
+		"Sun":             "D",
+		"Monday":          "Lunes",
+		"Mon":             "L",
+		"Tuesday":         "Martes",
+		"Tue":             "M",
+		"Wednesday":       "Miércoles",
+		"Wed":             "M",
+		"Thursday":        "Jueves",
+		"Thu":             "J",
+		"Friday":          "Viernes",
+		"Fri":             "V",
+		"Saturday":        "Sábado",
+		"Sat":             "S",
+		"January":         "Enero",
+		"February":        "Febrero",
+		"March":           "Marzo",
+		"April":           "Abril",
+		"May":             "Mayo",
+		"June":            "Junio",
+		"July":            "Julio",
+		"August":          "Agosto",
+		"September":       "Septiembre",
+		"October":         "Octubre",
+		"November":        "Noviembre",
+		"December":        "Diciembre",
+		"calendar":        "calendar",
+		"page":            "Página",
+		"row":             "Fila",
+		"column":          "Columna",
+		"Calendar":        "Calendario",
+		"Index":           "Índice",
+		"Special days":    "Días especiales",
+		"Holiday":         "Feriado",
+		"holiday":         "feriado",
+		"Spring":          "Primavera",
+		"Summer":          "Verano",
+		"Autumn":          "Otoño",
+		"Winter":          "Invierno",
+		"Spring equinox":  "Equinoccio de primavera",
+		"Summer solstice": "Solsticio de verano",
+		"Autumn equinox":  "Equinoccio de otoño",
+		"Winter solstice": "Solsticio de invierno",
+		"Muharram":        "Muharram",
+		"Safar":           "Safar",
+		"Rabi al-Awwal":   "Rabi al-Awal",
+		"Rabi al-Thani":   "Rabi al-Thani",
+		"Jumada al-Awwal": "Yumada al-Awal",
+		"Jumada al-Thani": "Yumada al-Thani",
+		"Rajab":           "Rayab",
+		"Shaban":          "Shabán",
+		"Ramadan":         "Ramadán",
+		"Shawwal":         "Shawwal",
+		"Dhu al-Qadah":    "Dhu al-Qada",
+		"Dhu al-Hijjah":   "Dhu al-Hiyya",
+		"Nisan":           "Nisán",
+		"Iyar":            "Iyar",
+		"Sivan":           "Siván",
+		"Tammuz":          "Tamuz",
+		"Av":              "Av",
+		"Elul":            "Elul",
+		"Tishrei":         "Tishréi",
+		"Cheshvan":        "Jeshván",
+		"Kislev":          "Kislev",
+		"Tevet":           "Tevet",
+		"Shevat":          "Shevat",
+		"Adar":            "Adar",
+		"Adar I":          "Adar I",
+		"Adar II":         "Adar II",
+		"Month":           "Mes",
+		"Leap month":      "Mes intercalar",
+		"…and %d more":    "…y %d más",
+
+		// Solar terms of the chinese calendar, the equinoxes and solstices are above
+		"Minor cold":           "Frío menor",
+		"Major cold":           "Frío mayor",
+		"Start of spring":      "Inicio de la primavera",
+		"Rain water":           "Agua de lluvia",
+		"Awakening of insects": "Despertar de los insectos",
+		"Pure brightness":      "Claridad pura",
+		"Grain rain":           "Lluvia de grano",
+		"Start of summer":      "Inicio del verano",
+		"Grain buds":           "Grano lleno",
+		"Grain in ear":         "Grano en espiga",
+		"Minor heat":           "Calor menor",
+		"Major heat":           "Calor mayor",
+		"Start of autumn":      "Inicio del otoño",
+		"End of heat":          "Fin del calor",
+		"White dew":            "Rocío blanco",
+		"Cold dew":             "Rocío frío",
+		"Frost descent":        "Descenso de la escarcha",
+		"Start of winter":      "Inicio del invierno",
+		"Minor snow":           "Nieve menor",
+		"Major snow":           "Nieve mayor",
 	}
 }
 
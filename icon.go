@@ -0,0 +1,1349 @@
+package galendar
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/vector"
+)
+
+// vectorIcon is an SVG icon translated into a list of shapes with absolute
+// coordinates in the viewBox space of the icon, ready to be drawn by renderers
+// that can't use the SVG directly
+type vectorIcon struct {
+	viewBox [4]float64
+	shapes  []iconShape
+}
+
+// iconShape is a single path of an icon with its paint already resolved
+type iconShape struct {
+	ops           []iconPathOp
+	fill          *iconPaint
+	stroke        *iconPaint
+	strokeWidth   float64
+	evenOdd       bool
+	fillOpacity   float64
+	strokeOpacity float64
+	clips         [][]iconPathOp
+}
+
+// iconPathOp is a path operation: 'M' (move), 'L' (line), 'C' (cubic bezier,
+// using all three points) or 'Z' (close)
+type iconPathOp struct {
+	op  byte
+	pts [3]iconPoint
+}
+
+type iconPoint struct {
+	x, y float64
+}
+
+// iconPaint is a solid color or a gradient, toGradient maps a point in the
+// viewBox space to the gradient vector space
+type iconPaint struct {
+	color      color.RGBA
+	stops      []iconGradientStop
+	radial     bool
+	x1, y1     float64
+	x2, y2     float64
+	toGradient iconMatrix
+}
+
+type iconGradientStop struct {
+	offset float64
+	color  color.RGBA
+}
+
+// iconMatrix is an affine transform as in SVG: [a b c d e f]
+type iconMatrix [6]float64
+
+var identityMatrix = iconMatrix{1, 0, 0, 1, 0, 0}
+
+func (m iconMatrix) multiply(n iconMatrix) iconMatrix {
+	return iconMatrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m iconMatrix) apply(p iconPoint) iconPoint {
+	return iconPoint{
+		x: m[0]*p.x + m[2]*p.y + m[4],
+		y: m[1]*p.x + m[3]*p.y + m[5],
+	}
+}
+
+func (m iconMatrix) inverse() iconMatrix {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return identityMatrix
+	}
+	return iconMatrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}
+}
+
+func (m iconMatrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// needsRaster reports if the icon uses features (gradients and clip paths)
+// that can't be translated to basic vector drawing operations
+func (icon *vectorIcon) needsRaster() bool {
+	for _, shape := range icon.shapes {
+		if len(shape.clips) > 0 {
+			return true
+		}
+		if shape.fill != nil && len(shape.fill.stops) > 0 {
+			return true
+		}
+		if shape.stroke != nil && len(shape.stroke.stops) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isRasterIcon reports if the icon file is a PNG or JPEG image
+func isRasterIcon(iconPath string) bool {
+	switch strings.ToLower(filepath.Ext(iconPath)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
+
+// svgNode is a parsed SVG element
+type svgNode struct {
+	name     string
+	attrs    map[string]string
+	children []*svgNode
+}
+
+func (node *svgNode) attr(name string) string {
+	return node.attrs[name]
+}
+
+// loadVectorIcon reads an SVG file and translates it into a vectorIcon
+func loadVectorIcon(svgPath string) (*vectorIcon, error) {
+	content, err := readIcon(svgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SVG file %s: %w", svgPath, err)
+	}
+
+	root, err := parseSVGTree(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SVG file %s: %w", svgPath, err)
+	}
+
+	icon := &vectorIcon{}
+	if vb := parseNumbers(root.attr("viewBox")); len(vb) == 4 {
+		copy(icon.viewBox[:], vb)
+	} else {
+		icon.viewBox = [4]float64{0, 0, parseLength(root.attr("width"), 100), parseLength(root.attr("height"), 100)}
+	}
+	if icon.viewBox[2] <= 0 || icon.viewBox[3] <= 0 {
+		return nil, fmt.Errorf("invalid viewBox in SVG file %s", svgPath)
+	}
+
+	ids := map[string]*svgNode{}
+	collectSVGIds(root, ids)
+
+	builder := iconBuilder{icon: icon, ids: ids}
+	builder.walk(root, identityMatrix, defaultIconStyle(), nil)
+
+	return icon, nil
+}
+
+func parseSVGTree(r io.Reader) (*svgNode, error) {
+	decoder := xml.NewDecoder(r)
+	var stack []*svgNode
+	var root *svgNode
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &svgNode{name: t.Name.Local, attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				name := attr.Name.Local
+				if attr.Name.Space == "http://www.w3.org/1999/xlink" || attr.Name.Space == "xlink" {
+					name = "xlink:" + name
+				} else if attr.Name.Space != "" {
+					continue
+				}
+				node.attrs[name] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if root == nil || root.name != "svg" {
+		return nil, fmt.Errorf("missing <svg> root element")
+	}
+
+	return root, nil
+}
+
+func collectSVGIds(node *svgNode, ids map[string]*svgNode) {
+	if id := node.attr("id"); id != "" {
+		ids[id] = node
+	}
+	for _, child := range node.children {
+		collectSVGIds(child, ids)
+	}
+}
+
+// iconStyle holds the inherited presentation properties of an element
+type iconStyle struct {
+	fill          string
+	stroke        string
+	strokeWidth   float64
+	fillRule      string
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64
+}
+
+func defaultIconStyle() iconStyle {
+	return iconStyle{
+		fill:          "black",
+		stroke:        "none",
+		strokeWidth:   1,
+		fillRule:      "nonzero",
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		opacity:       1,
+	}
+}
+
+func (style iconStyle) apply(node *svgNode) (iconStyle, bool) {
+	props := map[string]string{}
+	for _, name := range []string{"fill", "stroke", "stroke-width", "fill-rule", "fill-opacity", "stroke-opacity", "opacity", "display", "visibility"} {
+		if value, ok := node.attrs[name]; ok {
+			props[name] = value
+		}
+	}
+	for _, decl := range strings.Split(node.attr("style"), ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if ok {
+			props[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	visible := props["display"] != "none" && props["visibility"] != "hidden"
+	if value, ok := props["fill"]; ok {
+		style.fill = value
+	}
+	if value, ok := props["stroke"]; ok {
+		style.stroke = value
+	}
+	if value, ok := props["stroke-width"]; ok {
+		style.strokeWidth = parseLength(value, style.strokeWidth)
+	}
+	if value, ok := props["fill-rule"]; ok {
+		style.fillRule = value
+	}
+	if value, ok := props["fill-opacity"]; ok {
+		style.fillOpacity = parseLength(value, 1)
+	}
+	if value, ok := props["stroke-opacity"]; ok {
+		style.strokeOpacity = parseLength(value, 1)
+	}
+	if value, ok := props["opacity"]; ok {
+		style.opacity *= parseLength(value, 1)
+	}
+
+	return style, visible
+}
+
+type iconBuilder struct {
+	icon *vectorIcon
+	ids  map[string]*svgNode
+}
+
+func (b *iconBuilder) walk(node *svgNode, ctm iconMatrix, style iconStyle, clips [][]iconPathOp) {
+	switch node.name {
+	case "defs", "clipPath", "mask", "symbol", "marker", "pattern", "metadata",
+		"title", "desc", "style", "script", "namedview", "linearGradient", "radialGradient":
+		return
+	}
+
+	style, visible := style.apply(node)
+	if !visible {
+		return
+	}
+
+	ctm = ctm.multiply(parseTransform(node.attr("transform")))
+
+	if ref := urlReference(node.attr("clip-path")); ref != "" {
+		if clipNode, ok := b.ids[ref]; ok {
+			clips = append(clips[:len(clips):len(clips)], b.clipOps(clipNode, ctm))
+		}
+	}
+
+	switch node.name {
+	case "svg", "g", "a", "switch":
+		for _, child := range node.children {
+			b.walk(child, ctm, style, clips)
+		}
+		return
+	}
+
+	local := shapeOps(node)
+	if len(local) == 0 {
+		return
+	}
+
+	shape := iconShape{
+		ops:           transformOps(local, ctm),
+		strokeWidth:   style.strokeWidth * ctm.scale(),
+		evenOdd:       style.fillRule == "evenodd",
+		fillOpacity:   style.fillOpacity * style.opacity,
+		strokeOpacity: style.strokeOpacity * style.opacity,
+		clips:         clips,
+	}
+	shape.fill = b.paint(style.fill, ctm, local)
+	shape.stroke = b.paint(style.stroke, ctm, local)
+
+	if shape.fill != nil || shape.stroke != nil {
+		b.icon.shapes = append(b.icon.shapes, shape)
+	}
+}
+
+func (b *iconBuilder) clipOps(clipNode *svgNode, ctm iconMatrix) []iconPathOp {
+	ctm = ctm.multiply(parseTransform(clipNode.attr("transform")))
+
+	var ops []iconPathOp
+	var collect func(node *svgNode, m iconMatrix)
+	collect = func(node *svgNode, m iconMatrix) {
+		m = m.multiply(parseTransform(node.attr("transform")))
+		if node.name == "g" {
+			for _, child := range node.children {
+				collect(child, m)
+			}
+			return
+		}
+		ops = append(ops, transformOps(shapeOps(node), m)...)
+	}
+	for _, child := range clipNode.children {
+		collect(child, ctm)
+	}
+
+	return ops
+}
+
+// paint resolves a fill or stroke value, returning nil for "none"
+func (b *iconBuilder) paint(value string, ctm iconMatrix, local []iconPathOp) *iconPaint {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "none" || value == "transparent" {
+		return nil
+	}
+
+	if ref := urlReference(value); ref != "" {
+		node, ok := b.ids[ref]
+		if !ok || (node.name != "linearGradient" && node.name != "radialGradient") {
+			// use the fallback color if there is one: url(#id) color
+			_, fallback, _ := strings.Cut(value, ")")
+			return b.paint(fallback, ctm, local)
+		}
+		return b.gradient(node, ctm, local)
+	}
+
+	c, ok := parseColor(value)
+	if !ok {
+		return nil
+	}
+	return &iconPaint{color: c}
+}
+
+func (b *iconBuilder) gradient(node *svgNode, ctm iconMatrix, local []iconPathOp) *iconPaint {
+	// gradients can inherit attributes and stops through xlink:href chains
+	attr := func(name string) string {
+		for n, depth := node, 0; n != nil && depth < 8; depth++ {
+			if value, ok := n.attrs[name]; ok {
+				return value
+			}
+			n = b.ids[strings.TrimPrefix(hrefOf(n), "#")]
+		}
+		return ""
+	}
+
+	var stopNodes []*svgNode
+	for n, depth := node, 0; n != nil && depth < 8 && len(stopNodes) == 0; depth++ {
+		for _, child := range n.children {
+			if child.name == "stop" {
+				stopNodes = append(stopNodes, child)
+			}
+		}
+		n = b.ids[strings.TrimPrefix(hrefOf(n), "#")]
+	}
+	if len(stopNodes) == 0 {
+		return nil
+	}
+
+	paint := &iconPaint{radial: node.name == "radialGradient"}
+	for _, stopNode := range stopNodes {
+		props := map[string]string{
+			"stop-color":   stopNode.attr("stop-color"),
+			"stop-opacity": stopNode.attr("stop-opacity"),
+		}
+		for _, decl := range strings.Split(stopNode.attr("style"), ";") {
+			name, value, ok := strings.Cut(decl, ":")
+			if ok {
+				props[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+		c, ok := parseColor(props["stop-color"])
+		if !ok {
+			c = color.RGBA{0, 0, 0, 255}
+		}
+		c.A = uint8(math.Round(255 * clamp01(parseLength(props["stop-opacity"], 1))))
+		paint.stops = append(paint.stops, iconGradientStop{
+			offset: clamp01(parseLength(stopNode.attr("offset"), 0)),
+			color:  c,
+		})
+	}
+	paint.color = paint.stops[0].color
+
+	userSpace := attr("gradientUnits") == "userSpaceOnUse"
+	unit := 1.0
+	if userSpace {
+		unit = 0
+	}
+	if paint.radial {
+		paint.x1 = parseCoordinate(attr("cx"), 0.5)
+		paint.y1 = parseCoordinate(attr("cy"), 0.5)
+		paint.x2 = parseCoordinate(attr("r"), 0.5)
+	} else {
+		paint.x1 = parseCoordinate(attr("x1"), 0)
+		paint.y1 = parseCoordinate(attr("y1"), 0)
+		paint.x2 = parseCoordinate(attr("x2"), unit)
+		paint.y2 = parseCoordinate(attr("y2"), 0)
+	}
+
+	toUser := ctm
+	if !userSpace {
+		minX, minY, maxX, maxY := opsBounds(local)
+		toUser = toUser.multiply(iconMatrix{maxX - minX, 0, 0, maxY - minY, minX, minY})
+	}
+	toUser = toUser.multiply(parseTransform(attr("gradientTransform")))
+	paint.toGradient = toUser.inverse()
+
+	return paint
+}
+
+// colorAt returns the color of the paint at the point p (in viewBox space)
+func (paint *iconPaint) colorAt(p iconPoint) color.RGBA {
+	if len(paint.stops) == 0 {
+		return paint.color
+	}
+
+	g := paint.toGradient.apply(p)
+	var t float64
+	if paint.radial {
+		if paint.x2 > 0 {
+			t = math.Hypot(g.x-paint.x1, g.y-paint.y1) / paint.x2
+		}
+	} else {
+		dx, dy := paint.x2-paint.x1, paint.y2-paint.y1
+		if length := dx*dx + dy*dy; length > 0 {
+			t = ((g.x-paint.x1)*dx + (g.y-paint.y1)*dy) / length
+		}
+	}
+	t = clamp01(t)
+
+	stops := paint.stops
+	if t <= stops[0].offset {
+		return stops[0].color
+	}
+	for i := 1; i < len(stops); i++ {
+		if t <= stops[i].offset {
+			prev, next := stops[i-1], stops[i]
+			f := 0.0
+			if next.offset > prev.offset {
+				f = (t - prev.offset) / (next.offset - prev.offset)
+			}
+			return color.RGBA{
+				R: lerp8(prev.color.R, next.color.R, f),
+				G: lerp8(prev.color.G, next.color.G, f),
+				B: lerp8(prev.color.B, next.color.B, f),
+				A: lerp8(prev.color.A, next.color.A, f),
+			}
+		}
+	}
+	return stops[len(stops)-1].color
+}
+
+// shapeOps returns the path of a basic shape element in its own coordinates
+func shapeOps(node *svgNode) []iconPathOp {
+	num := func(name string) float64 { return parseLength(node.attr(name), 0) }
+
+	switch node.name {
+	case "path":
+		return parsePathData(node.attr("d"))
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, ry := num("rx"), num("ry")
+		if rx == 0 {
+			rx = ry
+		}
+		if ry == 0 {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx == 0 {
+			return []iconPathOp{
+				{op: 'M', pts: [3]iconPoint{{x, y}}},
+				{op: 'L', pts: [3]iconPoint{{x + w, y}}},
+				{op: 'L', pts: [3]iconPoint{{x + w, y + h}}},
+				{op: 'L', pts: [3]iconPoint{{x, y + h}}},
+				{op: 'Z'},
+			}
+		}
+		var p pathBuilder
+		p.moveTo(x+rx, y)
+		p.lineTo(x+w-rx, y)
+		p.arcTo(rx, ry, 0, false, true, x+w, y+ry)
+		p.lineTo(x+w, y+h-ry)
+		p.arcTo(rx, ry, 0, false, true, x+w-rx, y+h)
+		p.lineTo(x+rx, y+h)
+		p.arcTo(rx, ry, 0, false, true, x, y+h-ry)
+		p.lineTo(x, y+ry)
+		p.arcTo(rx, ry, 0, false, true, x+rx, y)
+		p.close()
+		return p.ops
+	case "circle", "ellipse":
+		cx, cy := num("cx"), num("cy")
+		rx, ry := num("rx"), num("ry")
+		if node.name == "circle" {
+			rx, ry = num("r"), num("r")
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		var p pathBuilder
+		p.moveTo(cx+rx, cy)
+		p.arcTo(rx, ry, 0, false, true, cx-rx, cy)
+		p.arcTo(rx, ry, 0, false, true, cx+rx, cy)
+		p.close()
+		return p.ops
+	case "line":
+		var p pathBuilder
+		p.moveTo(num("x1"), num("y1"))
+		p.lineTo(num("x2"), num("y2"))
+		return p.ops
+	case "polyline", "polygon":
+		values := parseNumbers(node.attr("points"))
+		if len(values) < 4 {
+			return nil
+		}
+		var p pathBuilder
+		p.moveTo(values[0], values[1])
+		for i := 2; i+1 < len(values); i += 2 {
+			p.lineTo(values[i], values[i+1])
+		}
+		if node.name == "polygon" {
+			p.close()
+		}
+		return p.ops
+	}
+
+	return nil
+}
+
+func transformOps(ops []iconPathOp, m iconMatrix) []iconPathOp {
+	transformed := make([]iconPathOp, len(ops))
+	for i, op := range ops {
+		transformed[i].op = op.op
+		for j := range op.pts {
+			transformed[i].pts[j] = m.apply(op.pts[j])
+		}
+	}
+	return transformed
+}
+
+func opsBounds(ops []iconPathOp) (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, op := range ops {
+		n := 0
+		switch op.op {
+		case 'M', 'L':
+			n = 1
+		case 'C':
+			n = 3
+		}
+		for _, p := range op.pts[:n] {
+			minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+			minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return 0, 0, 0, 0
+	}
+	return minX, minY, maxX, maxY
+}
+
+// pathBuilder accumulates path operations converting every curve to cubic
+// beziers
+type pathBuilder struct {
+	ops     []iconPathOp
+	current iconPoint
+	start   iconPoint
+}
+
+func (p *pathBuilder) moveTo(x, y float64) {
+	p.current = iconPoint{x, y}
+	p.start = p.current
+	p.ops = append(p.ops, iconPathOp{op: 'M', pts: [3]iconPoint{p.current}})
+}
+
+func (p *pathBuilder) lineTo(x, y float64) {
+	p.current = iconPoint{x, y}
+	p.ops = append(p.ops, iconPathOp{op: 'L', pts: [3]iconPoint{p.current}})
+}
+
+func (p *pathBuilder) cubicTo(x1, y1, x2, y2, x, y float64) {
+	p.current = iconPoint{x, y}
+	p.ops = append(p.ops, iconPathOp{op: 'C', pts: [3]iconPoint{{x1, y1}, {x2, y2}, p.current}})
+}
+
+func (p *pathBuilder) quadTo(qx, qy, x, y float64) {
+	x0, y0 := p.current.x, p.current.y
+	p.cubicTo(x0+2.0/3.0*(qx-x0), y0+2.0/3.0*(qy-y0), x+2.0/3.0*(qx-x), y+2.0/3.0*(qy-y), x, y)
+}
+
+func (p *pathBuilder) close() {
+	p.ops = append(p.ops, iconPathOp{op: 'Z'})
+	p.current = p.start
+}
+
+// arcTo converts an SVG elliptical arc to cubic beziers, following the
+// endpoint to center conversion of the SVG specification (appendix B.2.4)
+func (p *pathBuilder) arcTo(rx, ry, rotation float64, largeArc, sweep bool, x, y float64) {
+	x0, y0 := p.current.x, p.current.y
+	if x0 == x && y0 == y {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.lineTo(x, y)
+		return
+	}
+
+	phi := rotation * math.Pi / 180
+	sinPhi, cosPhi := math.Sin(phi), math.Cos(phi)
+	dx, dy := (x0-x)/2, (y0-y)/2
+	x1p := cosPhi*dx + sinPhi*dy
+	y1p := -sinPhi*dx + cosPhi*dy
+
+	lambda := (x1p*x1p)/(rx*rx) + (y1p*y1p)/(ry*ry)
+	if lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	coef := 0.0
+	if den != 0 && num > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cxp, cyp := coef*rx*y1p/ry, -coef*ry*x1p/rx
+	cx := cosPhi*cxp - sinPhi*cyp + (x0+x)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y0+y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1p-cxp)/rx, (y1p-cyp)/ry)
+	delta := angle((x1p-cxp)/rx, (y1p-cyp)/ry, (-x1p-cxp)/rx, (-y1p-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	segments := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(segments)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	point := func(t float64) (float64, float64) {
+		return cx + rx*math.Cos(t)*cosPhi - ry*math.Sin(t)*sinPhi,
+			cy + rx*math.Cos(t)*sinPhi + ry*math.Sin(t)*cosPhi
+	}
+	derivative := func(t float64) (float64, float64) {
+		return -rx*math.Sin(t)*cosPhi - ry*math.Cos(t)*sinPhi,
+			-rx*math.Sin(t)*sinPhi + ry*math.Cos(t)*cosPhi
+	}
+
+	for i := range segments {
+		t1 := theta + float64(i)*step
+		t2 := t1 + step
+		sx, sy := point(t1)
+		ex, ey := point(t2)
+		d1x, d1y := derivative(t1)
+		d2x, d2y := derivative(t2)
+		if i == segments-1 {
+			ex, ey = x, y
+		}
+		p.cubicTo(sx+k*d1x, sy+k*d1y, ex-k*d2x, ey-k*d2y, ex, ey)
+	}
+}
+
+// parsePathData parses the "d" attribute of a <path>, invalid data stops the
+// parsing keeping everything parsed until that point, as SVG viewers do
+func parsePathData(d string) []iconPathOp {
+	var p pathBuilder
+	var cmd byte
+	var lastControl iconPoint
+	var lastCmd byte
+	s := pathScanner{data: d}
+
+	for {
+		s.skipSeparators()
+		if s.done() {
+			break
+		}
+
+		if c := s.peek(); isPathCommand(c) {
+			cmd = c
+			s.pos++
+		} else if cmd == 0 {
+			break
+		}
+
+		relative := cmd >= 'a' && cmd <= 'z'
+		ox, oy := 0.0, 0.0
+		if relative {
+			ox, oy = p.current.x, p.current.y
+		}
+
+		ok := true
+		switch cmd {
+		case 'Z', 'z':
+			p.close()
+		case 'M', 'm':
+			var x, y float64
+			if x, y, ok = s.pair(); ok {
+				p.moveTo(ox+x, oy+y)
+				// subsequent pairs are implicit lineto commands
+				if cmd == 'M' {
+					cmd = 'L'
+				} else {
+					cmd = 'l'
+				}
+			}
+		case 'L', 'l':
+			var x, y float64
+			if x, y, ok = s.pair(); ok {
+				p.lineTo(ox+x, oy+y)
+			}
+		case 'H', 'h':
+			var x float64
+			if x, ok = s.number(); ok {
+				p.lineTo(ox+x, p.current.y)
+			}
+		case 'V', 'v':
+			var y float64
+			if y, ok = s.number(); ok {
+				p.lineTo(p.current.x, oy+y)
+			}
+		case 'C', 'c':
+			var v []float64
+			if v, ok = s.numbers(6); ok {
+				p.cubicTo(ox+v[0], oy+v[1], ox+v[2], oy+v[3], ox+v[4], oy+v[5])
+				lastControl = iconPoint{ox + v[2], oy + v[3]}
+			}
+		case 'S', 's':
+			var v []float64
+			if v, ok = s.numbers(4); ok {
+				c1 := p.current
+				if lastCmd == 'C' || lastCmd == 'S' {
+					c1 = iconPoint{2*p.current.x - lastControl.x, 2*p.current.y - lastControl.y}
+				}
+				p.cubicTo(c1.x, c1.y, ox+v[0], oy+v[1], ox+v[2], oy+v[3])
+				lastControl = iconPoint{ox + v[0], oy + v[1]}
+			}
+		case 'Q', 'q':
+			var v []float64
+			if v, ok = s.numbers(4); ok {
+				p.quadTo(ox+v[0], oy+v[1], ox+v[2], oy+v[3])
+				lastControl = iconPoint{ox + v[0], oy + v[1]}
+			}
+		case 'T', 't':
+			var x, y float64
+			if x, y, ok = s.pair(); ok {
+				q := p.current
+				if lastCmd == 'Q' || lastCmd == 'T' {
+					q = iconPoint{2*p.current.x - lastControl.x, 2*p.current.y - lastControl.y}
+				}
+				p.quadTo(q.x, q.y, ox+x, oy+y)
+				lastControl = q
+			}
+		case 'A', 'a':
+			var rx, ry, rotation, x, y float64
+			var largeArc, sweep bool
+			if rx, ok = s.number(); ok {
+				if ry, ok = s.number(); ok {
+					if rotation, ok = s.number(); ok {
+						if largeArc, ok = s.flag(); ok {
+							if sweep, ok = s.flag(); ok {
+								if x, y, ok = s.pair(); ok {
+									p.arcTo(rx, ry, rotation, largeArc, sweep, ox+x, oy+y)
+								}
+							}
+						}
+					}
+				}
+			}
+		default:
+			ok = false
+		}
+
+		if !ok {
+			break
+		}
+		lastCmd = upper(cmd)
+	}
+
+	return p.ops
+}
+
+func isPathCommand(c byte) bool {
+	return strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+type pathScanner struct {
+	data string
+	pos  int
+}
+
+func (s *pathScanner) done() bool {
+	return s.pos >= len(s.data)
+}
+
+func (s *pathScanner) peek() byte {
+	return s.data[s.pos]
+}
+
+func (s *pathScanner) skipSeparators() {
+	for !s.done() {
+		switch s.peek() {
+		case ' ', '\t', '\n', '\r', ',':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *pathScanner) number() (float64, bool) {
+	s.skipSeparators()
+	start := s.pos
+	if !s.done() && (s.peek() == '-' || s.peek() == '+') {
+		s.pos++
+	}
+	seenDot, seenDigit := false, false
+	for !s.done() {
+		c := s.peek()
+		if c >= '0' && c <= '9' {
+			seenDigit = true
+		} else if c == '.' && !seenDot {
+			seenDot = true
+		} else {
+			break
+		}
+		s.pos++
+	}
+	if !seenDigit {
+		s.pos = start
+		return 0, false
+	}
+	if !s.done() && (s.peek() == 'e' || s.peek() == 'E') {
+		mark := s.pos
+		s.pos++
+		if !s.done() && (s.peek() == '-' || s.peek() == '+') {
+			s.pos++
+		}
+		digits := s.pos
+		for !s.done() && s.peek() >= '0' && s.peek() <= '9' {
+			s.pos++
+		}
+		if s.pos == digits {
+			s.pos = mark
+		}
+	}
+	value, err := strconv.ParseFloat(s.data[start:s.pos], 64)
+	return value, err == nil
+}
+
+func (s *pathScanner) pair() (float64, float64, bool) {
+	x, ok := s.number()
+	if !ok {
+		return 0, 0, false
+	}
+	y, ok := s.number()
+	return x, y, ok
+}
+
+func (s *pathScanner) numbers(n int) ([]float64, bool) {
+	values := make([]float64, n)
+	for i := range values {
+		value, ok := s.number()
+		if !ok {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+// flag reads an arc flag, which can be written without separators ("a1 1 0 01 1 1")
+func (s *pathScanner) flag() (bool, bool) {
+	s.skipSeparators()
+	if s.done() {
+		return false, false
+	}
+	switch s.peek() {
+	case '0':
+		s.pos++
+		return false, true
+	case '1':
+		s.pos++
+		return true, true
+	}
+	return false, false
+}
+
+// parseTransform parses the value of a transform attribute
+func parseTransform(value string) iconMatrix {
+	m := identityMatrix
+	value = strings.TrimSpace(value)
+
+	for value != "" {
+		open := strings.IndexByte(value, '(')
+		end := strings.IndexByte(value, ')')
+		if open < 0 || end < open {
+			break
+		}
+		name := strings.Trim(strings.TrimSpace(value[:open]), ",")
+		args := parseNumbers(value[open+1 : end])
+		value = strings.TrimSpace(value[end+1:])
+
+		t := identityMatrix
+		switch strings.TrimSpace(name) {
+		case "matrix":
+			if len(args) == 6 {
+				copy(t[:], args)
+			}
+		case "translate":
+			if len(args) >= 1 {
+				t[4] = args[0]
+			}
+			if len(args) >= 2 {
+				t[5] = args[1]
+			}
+		case "scale":
+			if len(args) >= 1 {
+				t[0], t[3] = args[0], args[0]
+			}
+			if len(args) >= 2 {
+				t[3] = args[1]
+			}
+		case "rotate":
+			if len(args) >= 1 {
+				a := args[0] * math.Pi / 180
+				t = iconMatrix{math.Cos(a), math.Sin(a), -math.Sin(a), math.Cos(a), 0, 0}
+				if len(args) == 3 {
+					t = iconMatrix{1, 0, 0, 1, args[1], args[2]}.multiply(t).multiply(iconMatrix{1, 0, 0, 1, -args[1], -args[2]})
+				}
+			}
+		case "skewX":
+			if len(args) == 1 {
+				t[2] = math.Tan(args[0] * math.Pi / 180)
+			}
+		case "skewY":
+			if len(args) == 1 {
+				t[1] = math.Tan(args[0] * math.Pi / 180)
+			}
+		}
+		m = m.multiply(t)
+	}
+
+	return m
+}
+
+func parseNumbers(value string) []float64 {
+	var values []float64
+	s := pathScanner{data: value}
+	for {
+		s.skipSeparators()
+		if s.done() {
+			break
+		}
+		number, ok := s.number()
+		if !ok {
+			break
+		}
+		values = append(values, number)
+	}
+	return values
+}
+
+// parseLength parses a number ignoring its unit, returning def if it can't
+// be parsed
+func parseLength(value string, def float64) float64 {
+	values := parseNumbers(strings.TrimRight(strings.TrimSpace(value), "abcdefghijklmnopqrstuvwxyz%"))
+	if len(values) == 0 {
+		return def
+	}
+	return values[0]
+}
+
+// parseCoordinate parses a gradient coordinate, percentages are converted to
+// fractions
+func parseCoordinate(value string, def float64) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return def
+	}
+	if strings.HasSuffix(value, "%") {
+		return parseLength(value, def*100) / 100
+	}
+	return parseLength(value, def)
+}
+
+func urlReference(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "url(") {
+		return ""
+	}
+	end := strings.IndexByte(value, ')')
+	if end < 0 {
+		return ""
+	}
+	ref := strings.Trim(strings.TrimSpace(value[4:end]), `"'`)
+	return strings.TrimPrefix(ref, "#")
+}
+
+func hrefOf(node *svgNode) string {
+	if href := node.attr("xlink:href"); href != "" {
+		return href
+	}
+	return node.attr("href")
+}
+
+var namedColors = map[string]color.RGBA{
+	"black":        {0, 0, 0, 255},
+	"white":        {255, 255, 255, 255},
+	"red":          {255, 0, 0, 255},
+	"green":        {0, 128, 0, 255},
+	"blue":         {0, 0, 255, 255},
+	"yellow":       {255, 255, 0, 255},
+	"orange":       {255, 165, 0, 255},
+	"purple":       {128, 0, 128, 255},
+	"gray":         {128, 128, 128, 255},
+	"grey":         {128, 128, 128, 255},
+	"brown":        {165, 42, 42, 255},
+	"pink":         {255, 192, 203, 255},
+	"currentcolor": {0, 0, 0, 255},
+}
+
+// parseColor parses #rgb, #rrggbb, rgb(r,g,b) and a few named colors
+func parseColor(value string) (color.RGBA, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if c, ok := namedColors[value]; ok {
+		return c, true
+	}
+
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return color.RGBA{}, false
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.RGBA{}, false
+		}
+		return color.RGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 255}, true
+	}
+
+	if args, ok := strings.CutPrefix(value, "rgb("); ok {
+		parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+		if len(parts) != 3 {
+			return color.RGBA{}, false
+		}
+		var rgb [3]uint8
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			v := parseLength(part, 0)
+			if strings.HasSuffix(part, "%") {
+				v = v * 255 / 100
+			}
+			rgb[i] = uint8(math.Round(math.Max(0, math.Min(255, v))))
+		}
+		return color.RGBA{rgb[0], rgb[1], rgb[2], 255}, true
+	}
+
+	return color.RGBA{}, false
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func lerp8(a, b uint8, f float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*f))
+}
+
+// rasterize draws the icon into a PNG image of the given size in pixels
+// (keeping the aspect ratio of the viewBox), supporting gradients and clip
+// paths that can't be drawn with basic vector operations
+func (icon *vectorIcon) rasterize(size int) ([]byte, error) {
+	vbX, vbY, vbW, vbH := icon.viewBox[0], icon.viewBox[1], icon.viewBox[2], icon.viewBox[3]
+	scale := float64(size) / math.Max(vbW, vbH)
+	width := max(1, int(math.Ceil(vbW*scale)))
+	height := max(1, int(math.Ceil(vbH*scale)))
+	toPixels := iconMatrix{scale, 0, 0, scale, -vbX * scale, -vbY * scale}
+	bounds := image.Rect(0, 0, width, height)
+
+	dst := image.NewRGBA(bounds)
+	for _, shape := range icon.shapes {
+		var mask *image.Alpha
+		for _, clip := range shape.clips {
+			clipMask := image.NewAlpha(bounds)
+			r := vector.NewRasterizer(width, height)
+			addOpsToRasterizer(r, transformOps(clip, toPixels))
+			r.Draw(clipMask, bounds, image.Opaque, image.Point{})
+			mask = intersectMasks(mask, clipMask)
+		}
+
+		if shape.fill != nil {
+			r := vector.NewRasterizer(width, height)
+			addOpsToRasterizer(r, transformOps(shape.ops, toPixels))
+			src := &paintImage{paint: shape.fill, opacity: shape.fillOpacity, mask: mask, fromPixels: toPixels.inverse(), bounds: bounds}
+			r.Draw(dst, bounds, src, image.Point{})
+		}
+
+		if shape.stroke != nil && shape.strokeWidth > 0 {
+			r := vector.NewRasterizer(width, height)
+			addStrokeToRasterizer(r, transformOps(shape.ops, toPixels), shape.strokeWidth*scale)
+			src := &paintImage{paint: shape.stroke, opacity: shape.strokeOpacity, mask: mask, fromPixels: toPixels.inverse(), bounds: bounds}
+			r.Draw(dst, bounds, src, image.Point{})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, fmt.Errorf("can't encode icon: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func addOpsToRasterizer(r *vector.Rasterizer, ops []iconPathOp) {
+	open := false
+	for _, op := range ops {
+		switch op.op {
+		case 'M':
+			if open {
+				r.ClosePath()
+			}
+			r.MoveTo(float32(op.pts[0].x), float32(op.pts[0].y))
+			open = true
+		case 'L':
+			r.LineTo(float32(op.pts[0].x), float32(op.pts[0].y))
+		case 'C':
+			r.CubeTo(float32(op.pts[0].x), float32(op.pts[0].y), float32(op.pts[1].x), float32(op.pts[1].y), float32(op.pts[2].x), float32(op.pts[2].y))
+		case 'Z':
+			if open {
+				r.ClosePath()
+				open = false
+			}
+		}
+	}
+	if open {
+		r.ClosePath()
+	}
+}
+
+// addStrokeToRasterizer approximates a stroke by adding a quad of the given
+// width for each segment of the flattened path, all with the same winding
+func addStrokeToRasterizer(r *vector.Rasterizer, ops []iconPathOp, width float64) {
+	for _, line := range flattenOps(ops) {
+		for i := 1; i < len(line); i++ {
+			p0, p1 := line[i-1], line[i]
+			dx, dy := p1.x-p0.x, p1.y-p0.y
+			length := math.Hypot(dx, dy)
+			if length == 0 {
+				continue
+			}
+			nx, ny := -dy/length*width/2, dx/length*width/2
+			r.MoveTo(float32(p0.x+nx), float32(p0.y+ny))
+			r.LineTo(float32(p1.x+nx), float32(p1.y+ny))
+			r.LineTo(float32(p1.x-nx), float32(p1.y-ny))
+			r.LineTo(float32(p0.x-nx), float32(p0.y-ny))
+			r.ClosePath()
+		}
+	}
+}
+
+// flattenOps converts a path to polylines, one for each subpath
+func flattenOps(ops []iconPathOp) [][]iconPoint {
+	var lines [][]iconPoint
+	var line []iconPoint
+	var start iconPoint
+	for _, op := range ops {
+		switch op.op {
+		case 'M':
+			if len(line) > 1 {
+				lines = append(lines, line)
+			}
+			start = op.pts[0]
+			line = []iconPoint{start}
+		case 'L':
+			line = append(line, op.pts[0])
+		case 'C':
+			if len(line) == 0 {
+				line = []iconPoint{start}
+			}
+			p0 := line[len(line)-1]
+			const steps = 16
+			for i := 1; i <= steps; i++ {
+				t := float64(i) / steps
+				u := 1 - t
+				line = append(line, iconPoint{
+					x: u*u*u*p0.x + 3*u*u*t*op.pts[0].x + 3*u*t*t*op.pts[1].x + t*t*t*op.pts[2].x,
+					y: u*u*u*p0.y + 3*u*u*t*op.pts[0].y + 3*u*t*t*op.pts[1].y + t*t*t*op.pts[2].y,
+				})
+			}
+		case 'Z':
+			line = append(line, start)
+			if len(line) > 1 {
+				lines = append(lines, line)
+			}
+			line = []iconPoint{start}
+		}
+	}
+	if len(line) > 1 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func intersectMasks(a, b *image.Alpha) *image.Alpha {
+	if a == nil {
+		return b
+	}
+	for i := range a.Pix {
+		a.Pix[i] = uint8(uint16(a.Pix[i]) * uint16(b.Pix[i]) / 255)
+	}
+	return a
+}
+
+// paintImage is an image.Image with the color of a paint at every pixel,
+// multiplied by an opacity and an optional clip mask
+type paintImage struct {
+	paint      *iconPaint
+	opacity    float64
+	mask       *image.Alpha
+	fromPixels iconMatrix
+	bounds     image.Rectangle
+}
+
+func (img *paintImage) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (img *paintImage) Bounds() image.Rectangle {
+	return img.bounds
+}
+
+func (img *paintImage) At(x, y int) color.Color {
+	c := img.paint.colorAt(img.fromPixels.apply(iconPoint{float64(x) + 0.5, float64(y) + 0.5}))
+	alpha := float64(c.A) * clamp01(img.opacity)
+	if img.mask != nil {
+		alpha = alpha * float64(img.mask.AlphaAt(x, y).A) / 255
+	}
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: uint8(math.Round(alpha))}
+}
+
+// cellIcon is an icon of a day laid out on a page, in millimeters
+type cellIcon struct {
+	icon       string
+	x, y, size float64
+}
+
+// layoutCellIcons lays out the icons of day for a cell with its top left
+// corner at x, y and width cellWidth: in a row from the top right corner to
+// the left
+func layoutCellIcons(theme Theme, day Day, x, y, cellWidth float64) []cellIcon {
+	size := cellWidth * theme.Icon.Size
+	var icons []cellIcon
+	for i, icon := range day.Icons() {
+		icons = append(icons, cellIcon{
+			icon: icon,
+			x:    x + cellWidth - float64(i+1)*(size+theme.Icon.Padding),
+			y:    y + theme.Icon.Padding,
+			size: size,
+		})
+	}
+	return icons
+}
+
+// cellIconsWidth returns the width taken by the icons of day at the right of
+// a cell of width cellWidth, at least the width of one icon so the other
+// elements of the cells line up
+func cellIconsWidth(theme Theme, day Day, cellWidth float64) float64 {
+	return float64(max(1, len(day.Icons()))) * (cellWidth*theme.Icon.Size + theme.Icon.Padding)
+}
@@ -0,0 +1,159 @@
+package galendar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Imposition is how the pages of a PDF are arranged on sheets to be printed
+// duplex and folded into a saddle-stitched booklet
+type Imposition string
+
+const (
+	// ImpositionNone writes a page per sheet, in order
+	ImpositionNone Imposition = "none"
+	// ImpositionTwoUp writes two pages, one above the other, on each side of
+	// a portrait A4 sheet, folded in half across into a landscape A5 booklet
+	ImpositionTwoUp Imposition = "2-up"
+	// ImpositionFourUp writes four pages on each side of an A4 sheet, cut in
+	// half across and folded into an A6 booklet: the halves of every sheet
+	// carry the sheets of the first and the second half of the booklet, so
+	// the pile of top halves goes over the pile of bottom halves
+	ImpositionFourUp Imposition = "4-up"
+)
+
+// ParseImposition parses an imposition, an empty string means none
+func ParseImposition(s string) (Imposition, error) {
+	switch imposition := Imposition(strings.ToLower(strings.TrimSpace(s))); imposition {
+	case "":
+		return ImpositionNone, nil
+	case ImpositionNone, ImpositionTwoUp, ImpositionFourUp:
+		return imposition, nil
+	default:
+		return "", fmt.Errorf("invalid imposition: %q (must be none, 2-up or 4-up)", s)
+	}
+}
+
+// bookletSide is a side of a sheet of a booklet: the pages at the left and
+// right of the fold, 0 for blank pages
+type bookletSide struct {
+	left, right int
+}
+
+// bookletSheets returns the sides of the sheets of a booklet of pages pages,
+// front and back of the outermost sheet first. The count of pages is padded
+// with blank pages to a multiple of four
+func bookletSheets(pages int) [][2]bookletSide {
+	padded := (pages + 3) / 4 * 4
+	page := func(n int) int {
+		if n > pages {
+			return 0
+		}
+		return n
+	}
+
+	sheets := make([][2]bookletSide, padded/4)
+	for i := range sheets {
+		sheets[i] = [2]bookletSide{
+			{left: page(padded - 2*i), right: page(2*i + 1)},
+			{left: page(2*i + 2), right: page(padded - 2*i - 1)},
+		}
+	}
+
+	return sheets
+}
+
+// imposePDF draws the pages queued by drawPDFPage on the sheets of the
+// imposition of config, pages of inner sheets move toward the fold by the
+// creep to compensate for the paper pushed out by the sheets around them.
+// Posters are split into tiles instead
+func imposePDF(pdf *pdfDocument, config Config) error {
+	opts := config.Print
+	pages := pdf.imposedPages
+	pdf.imposedPages = nil
+	if opts.tiled() {
+		return tilePDF(pdf, config, pages)
+	}
+	if !opts.imposed() {
+		return nil
+	}
+
+	sheets := bookletSheets(len(pages))
+
+	// 2-up stacks the landscape A5 pages of a booklet sheet on a portrait A4
+	// sheet, folded across. 4-up puts them side by side on each row of a
+	// landscape A4 sheet, every row with a booklet sheet
+	stacked := opts.Imposition == ImpositionTwoUp
+	rows := 2
+	slotWidth, slotHeight := pdfPageWidth/2, pdfPageHeight/2
+	if stacked {
+		rows = 1
+		slotWidth, slotHeight = pdfPageHeight, pdfPageWidth/2
+	}
+	printed := (len(sheets) + rows - 1) / rows
+
+	for i := range printed {
+		for side := range 2 {
+			if stacked {
+				pdf.AddPageFormat("P", gofpdf.SizeType{Wd: pdfPageHeight, Ht: pdfPageWidth})
+			} else {
+				pdf.AddPage()
+			}
+			for row := range rows {
+				sheet := i + row*printed
+				if sheet >= len(sheets) {
+					continue
+				}
+
+				// The left page of a booklet side goes on top when stacked, both
+				// pages move toward the fold by the creep
+				creep := opts.Creep * float64(sheet)
+				first := pageRect{x: 0, y: float64(row) * slotHeight, w: slotWidth, h: slotHeight}
+				second := pageRect{x: slotWidth, y: first.y, w: slotWidth, h: slotHeight}
+				creepX, creepY := creep, 0.0
+				if stacked {
+					second = pageRect{x: 0, y: slotHeight, w: slotWidth, h: slotHeight}
+					creepX, creepY = 0, creep
+				}
+				for _, slot := range []struct {
+					page           int
+					area           pageRect
+					shiftX, shiftY float64
+				}{
+					{sheets[sheet][side].left, first, creepX, creepY},
+					{sheets[sheet][side].right, second, -creepX, -creepY},
+				} {
+					if slot.page == 0 {
+						continue
+					}
+					if err := drawImposedPage(pdf, pages[slot.page-1], slot.area, slot.shiftX, slot.shiftY); err != nil {
+						return fmt.Errorf("can't impose page %d: %w", slot.page, err)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// drawImposedPage draws a page scaled to fit in area, moved by shiftX and
+// shiftY and clipped to area
+func drawImposedPage(pdf *pdfDocument, draw func() error, area pageRect, shiftX, shiftY float64) error {
+	placed := ImageFitFit.place(pdfPageWidth, pdfPageHeight, area)
+	scale := placed.w / pdfPageWidth
+
+	pdf.ClipRect(area.x, area.y, area.w, area.h, false)
+	pdf.TransformBegin()
+	pdf.TransformTranslate(placed.x+shiftX, placed.y+shiftY)
+	pdf.TransformScale(scale*100, scale*100, 0, 0)
+
+	err := draw()
+
+	pdf.TransformEnd()
+	pdf.ClipEnd()
+
+	return err
+}
@@ -0,0 +1,418 @@
+package galendar
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// JSONSchemaVersion is the version of the calendar model written by
+// JSONRenderer, it changes only when the schema changes in an incompatible way
+const JSONSchemaVersion = 1
+
+// JSONCalendar is the public, stable schema of a rendered calendar
+type JSONCalendar struct {
+	Version   int         `json:"version"`
+	Year      int         `json:"year"`
+	WeekStart string      `json:"week_start"`
+	Language  string      `json:"language,omitempty"`
+	Months    []JSONMonth `json:"months"`
+}
+
+// JSONMonth is a single month of a JSONCalendar
+type JSONMonth struct {
+	Year  int        `json:"year"`
+	Month int        `json:"month"`
+	Name  string     `json:"name,omitempty"`
+	Weeks []JSONWeek `json:"weeks"`
+}
+
+// JSONWeek is a row of a JSONMonth, Number is the ISO 8601 week number of the
+// row (the week that contains the Thursday of the row)
+type JSONWeek struct {
+	Number int       `json:"number"`
+	Days   []JSONDay `json:"days"`
+}
+
+// JSONDay is a single cell of a JSONWeek, Date is formatted as YYYY-MM-DD
+type JSONDay struct {
+	Date         string     `json:"date"`
+	CurrentMonth bool       `json:"current_month"`
+	Holiday      bool       `json:"holiday"`
+	Notes        []JSONNote `json:"notes,omitempty"`
+	Icons        []string   `json:"icons,omitempty"`
+	QR           string     `json:"qr,omitempty"`
+	Sun          *JSONSun   `json:"sun,omitempty"`
+}
+
+// JSONSun is the sun of a JSONDay at the location of the calendar, it is
+// computed and ignored when the model is read. Times are RFC 3339 in the time
+// zone of the calendar, missing when the sun doesn't rise or set, and
+// lengths are in seconds
+type JSONSun struct {
+	Sunrise        string `json:"sunrise,omitempty"`
+	Sunset         string `json:"sunset,omitempty"`
+	CivilDawn      string `json:"civil_dawn,omitempty"`
+	CivilDusk      string `json:"civil_dusk,omitempty"`
+	DayLength      int    `json:"day_length"`
+	DayLengthDelta int    `json:"day_length_delta"`
+}
+
+// JSONNote is a note attached to a JSONDay
+type JSONNote struct {
+	Text string  `json:"text"`
+	Font string  `json:"font,omitempty"`
+	Size float64 `json:"size,omitempty"`
+	URL  string  `json:"url,omitempty"`
+}
+
+// JSONRenderer handles JSON calendar model generation
+type JSONRenderer struct{}
+
+func init() {
+	RegisterRenderer(JSONRenderer{})
+}
+
+func (r JSONRenderer) Name() string {
+	return "json"
+}
+
+// RenderMonth renders a single month calendar model to JSON
+func (r JSONRenderer) RenderMonth(config Config, cal Calendar) error {
+	model := NewJSONCalendar(config, cal)
+	return writeJSONCalendar(config.MonthOutputFilePath(cal), model)
+}
+
+// RenderYear renders a full year calendar model (12 months, or the months of
+// a calendar from a model) to a single JSON file
+func (r JSONRenderer) RenderYear(config Config, cal Calendar) error {
+	months, err := cal.months()
+	if err != nil {
+		return err
+	}
+
+	model := NewJSONCalendar(config, months...)
+	return writeJSONCalendar(config.YearOutputFilePath(), model)
+}
+
+// NewJSONCalendar builds the public model of the given months
+func NewJSONCalendar(config Config, months ...Calendar) JSONCalendar {
+	model := JSONCalendar{
+		Version:   JSONSchemaVersion,
+		Year:      config.Year,
+		WeekStart: strings.ToLower(config.WeekStart.String()),
+		Language:  string(config.Language),
+		Months:    make([]JSONMonth, 0, len(months)),
+	}
+
+	for _, cal := range months {
+		// The model has a row for every week, folding them is up to the renderers
+		cal = cal.unfolded()
+		if model.Year == 0 {
+			model.Year = cal.Year
+		}
+		model.WeekStart = strings.ToLower(cal.WeekStart.String())
+
+		month := JSONMonth{
+			Year:  cal.Year,
+			Month: cal.Month,
+			Name:  config.Language.MonthName(cal.Month),
+			Weeks: make([]JSONWeek, 0, len(cal.Weeks)),
+		}
+
+		for _, week := range cal.Weeks {
+			jsonWeek := JSONWeek{
+				Number: weekNumber(week),
+				Days:   make([]JSONDay, 0, len(week)),
+			}
+
+			for _, day := range week {
+				jsonDay := JSONDay{
+					Date:         day.Name(),
+					CurrentMonth: day.IsCurrentMonth,
+					Holiday:      day.IsHoliday(),
+				}
+				if note := day.Note(); note != nil && (note.Text != "" || note.URL != "") {
+					jsonDay.Notes = append(jsonDay.Notes, JSONNote{
+						Text: note.Text,
+						Font: note.Font,
+						Size: note.Size,
+						URL:  note.URL,
+					})
+				}
+				jsonDay.Icons = day.Icons()
+				jsonDay.QR = day.QR()
+				if sun := day.Sun; sun != nil {
+					jsonDay.Sun = newJSONSun(*sun)
+				}
+				jsonWeek.Days = append(jsonWeek.Days, jsonDay)
+			}
+
+			month.Weeks = append(month.Weeks, jsonWeek)
+		}
+
+		model.Months = append(model.Months, month)
+	}
+
+	return model
+}
+
+func newJSONSun(sun Sun) *JSONSun {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	return &JSONSun{
+		Sunrise:        format(sun.Sunrise),
+		Sunset:         format(sun.Sunset),
+		CivilDawn:      format(sun.CivilDawn),
+		CivilDusk:      format(sun.CivilDusk),
+		DayLength:      int(sun.DayLength.Seconds()),
+		DayLengthDelta: int(sun.DayLengthDelta.Seconds()),
+	}
+}
+
+// LoadJSONCalendarFromFile reads a calendar model previously written by
+// JSONRenderer (and maybe edited by other tools)
+func LoadJSONCalendarFromFile(filename string) (JSONCalendar, error) {
+	var model JSONCalendar
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return model, fmt.Errorf("can't read file %q: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(content, &model); err != nil {
+		return model, fmt.Errorf("can't decode json file %q: %w", filename, err)
+	}
+
+	if model.Version != JSONSchemaVersion {
+		return model, fmt.Errorf("unsupported schema version %d (expected %d)", model.Version, JSONSchemaVersion)
+	}
+
+	if len(model.Months) == 0 {
+		return model, fmt.Errorf("no months in file %q", filename)
+	}
+
+	return model, nil
+}
+
+// WeekStartDay returns the parsed week start of the model
+func (model JSONCalendar) WeekStartDay() (time.Weekday, error) {
+	if model.WeekStart == "" {
+		return DefaultWeekStart, nil
+	}
+	return ParseWeekday(model.WeekStart)
+}
+
+// SpecialDays rebuilds the special days from the days of the model, only
+// current month days are used. Weekends are always holidays, so a weekend
+// marked as non holiday is still one for the months not in the model, see
+// Calendar
+func (model JSONCalendar) SpecialDays() (SpecialDays, error) {
+	days := SpecialDays{}
+
+	for _, month := range model.Months {
+		for _, week := range month.Weeks {
+			for _, day := range week.Days {
+				if !day.CurrentMonth {
+					continue
+				}
+
+				date, err := time.Parse(time.DateOnly, day.Date)
+				if err != nil {
+					return nil, fmt.Errorf("invalid date %q: %w", day.Date, err)
+				}
+
+				weekday := date.Weekday()
+				isWeekend := weekday == time.Saturday || weekday == time.Sunday
+				special, err := day.specialDay(date, day.Holiday && !isWeekend)
+				if err != nil {
+					return nil, err
+				}
+				if special != nil {
+					days[specialDaysKeyFromTime(date)] = *special
+				}
+			}
+		}
+	}
+
+	return days, nil
+}
+
+// Calendar returns the calendar of the first month of the model, with the
+// months of the model as its year, see Renderer.RenderYear. The days of the
+// months are the ones of the model, with their holidays, notes, icons and QR
+// codes; the moon and the sun are computed. Week numbers are the ISO weeks of
+// the days, they can't be edited
+func (model JSONCalendar) Calendar() (Calendar, error) {
+	if len(model.Months) == 0 {
+		return Calendar{}, fmt.Errorf("no months in calendar model")
+	}
+
+	weekStart, err := model.WeekStartDay()
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	specialDays, err := model.SpecialDays()
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	months := make([]Calendar, 0, len(model.Months))
+	for _, month := range model.Months {
+		cal, err := month.calendar(weekStart, specialDays)
+		if err != nil {
+			return Calendar{}, fmt.Errorf("invalid month %d of %d: %w", month.Month, month.Year, err)
+		}
+		for _, other := range months {
+			if other.Year == cal.Year && other.Month == cal.Month {
+				return Calendar{}, fmt.Errorf("month %d of %d is repeated", month.Month, month.Year)
+			}
+		}
+		months = append(months, cal)
+	}
+
+	return linkMonths(months)[0], nil
+}
+
+// calendar returns the calendar of the weeks of month, which start on
+// weekStart and have consecutive days
+func (month JSONMonth) calendar(weekStart time.Weekday, specialDays SpecialDays) (Calendar, error) {
+	cal := Calendar{
+		Year:        month.Year,
+		Month:       month.Month,
+		WeekStart:   weekStart,
+		SpecialDays: specialDays,
+	}
+
+	if month.Month < 1 || month.Month > 12 {
+		return cal, fmt.Errorf("invalid month: %d (must be 1-12)", month.Month)
+	}
+	if len(month.Weeks) == 0 {
+		return cal, fmt.Errorf("no weeks")
+	}
+
+	var next time.Time
+	for _, week := range month.Weeks {
+		if len(week.Days) != 7 {
+			return cal, fmt.Errorf("week %d has %d days (must be 7)", week.Number, len(week.Days))
+		}
+
+		days := make([]Day, 0, len(week.Days))
+		for _, jsonDay := range week.Days {
+			date, err := time.Parse(time.DateOnly, jsonDay.Date)
+			if err != nil {
+				return cal, fmt.Errorf("invalid date %q: %w", jsonDay.Date, err)
+			}
+			switch {
+			case next.IsZero() && date.Weekday() != weekStart:
+				return cal, fmt.Errorf("invalid date %q: weeks start on %s", jsonDay.Date, strings.ToLower(weekStart.String()))
+			case !next.IsZero() && !date.Equal(next):
+				return cal, fmt.Errorf("invalid date %q (must be %s, the day after the one before)", jsonDay.Date, next.Format(time.DateOnly))
+			}
+			next = date.AddDate(0, 0, 1)
+
+			special, err := jsonDay.specialDay(date, jsonDay.Holiday)
+			if err != nil {
+				return cal, err
+			}
+			holiday := jsonDay.Holiday
+			days = append(days, Day{
+				Date:           date,
+				DayNumber:      date.Day(),
+				IsCurrentMonth: jsonDay.CurrentMonth,
+				special:        special,
+				holiday:        &holiday,
+			})
+		}
+
+		if number := weekNumber(days); week.Number != number {
+			return cal, fmt.Errorf("invalid week number %d (must be %d, the ISO week of its days)", week.Number, number)
+		}
+		cal.Weeks = append(cal.Weeks, days)
+	}
+
+	return cal.withSky(time.UTC, nil), nil
+}
+
+// specialDay returns the notes, icons and QR code of day as a special day on
+// date, nil if it has none and isn't a holiday
+func (day JSONDay) specialDay(date time.Time, holiday bool) (*SpecialDay, error) {
+	if !holiday && len(day.Notes) == 0 && len(day.Icons) == 0 && day.QR == "" {
+		return nil, nil
+	}
+
+	note, err := day.note()
+	if err != nil {
+		return nil, err
+	}
+
+	special := &SpecialDay{
+		Date:    date,
+		Holiday: holiday,
+		QR:      day.QR,
+		Note:    note,
+	}
+	if len(day.Icons) > 0 {
+		special.Icon = day.Icons[0]
+		special.Icons = day.Icons[1:]
+	}
+	return special, nil
+}
+
+// note returns the notes of day as a single note, each in its own line. The
+// notes can't have different fonts, sizes or urls
+func (day JSONDay) note() (SpecialDayNote, error) {
+	var note SpecialDayNote
+	texts := make([]string, 0, len(day.Notes))
+	for _, jsonNote := range day.Notes {
+		if jsonNote.Text != "" {
+			texts = append(texts, jsonNote.Text)
+		}
+		if (note.Font != "" && jsonNote.Font != "" && jsonNote.Font != note.Font) ||
+			(note.Size != 0 && jsonNote.Size != 0 && jsonNote.Size != note.Size) ||
+			(note.URL != "" && jsonNote.URL != "" && jsonNote.URL != note.URL) {
+			return note, fmt.Errorf("can't merge the notes of %s: they have different fonts, sizes or urls", day.Date)
+		}
+		note.Font = cmp.Or(note.Font, jsonNote.Font)
+		note.Size = cmp.Or(note.Size, jsonNote.Size)
+		note.URL = cmp.Or(note.URL, jsonNote.URL)
+	}
+	note.Text = strings.Join(texts, "\n")
+	return note, nil
+}
+
+func writeJSONCalendar(filename string, model JSONCalendar) error {
+	content, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't encode calendar: %w", err)
+	}
+
+	content = append(content, '\n')
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return fmt.Errorf("can't output file: %w", err)
+	}
+
+	return nil
+}
+
+// weekNumber returns the ISO 8601 week number of a row of days, using the
+// Thursday of the row because it always belongs to the ISO week
+func weekNumber(week []Day) int {
+	for _, day := range week {
+		if day.Date.Weekday() == time.Thursday {
+			_, number := day.Date.ISOWeek()
+			return number
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,189 @@
+package galendar_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestJSONRenderer_RoundTrip(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "25/5"
+holiday = true
+text = "Revolución de Mayo"
+icon = "builtin:birthday"
+qr = "https://example.com/mayo"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := galendar.Config{
+		Year:      2026,
+		Month:     5,
+		WeekStart: time.Monday,
+		Renderer:  galendar.JSONRenderer{},
+		OutputDir: t.TempDir(),
+		Language:  galendar.Spanish,
+	}
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	model, err := galendar.LoadJSONCalendarFromFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("LoadJSONCalendarFromFile failed: %v", err)
+	}
+
+	if len(model.Months) != 1 {
+		t.Fatalf("Expected 1 month, got %d", len(model.Months))
+	}
+
+	// May 2026 starts on a Friday, so the first row is ISO week 18
+	firstWeek := model.Months[0].Weeks[0]
+	if firstWeek.Number != 18 {
+		t.Errorf("Expected first week number 18, got %d", firstWeek.Number)
+	}
+	if firstWeek.Days[0].Date != "2026-04-27" || firstWeek.Days[0].CurrentMonth {
+		t.Errorf("Expected first day to be 2026-04-27 outside current month, got %+v", firstWeek.Days[0])
+	}
+
+	weekStart, err := model.WeekStartDay()
+	if err != nil || weekStart != time.Monday {
+		t.Errorf("Expected week start Monday, got %v (%v)", weekStart, err)
+	}
+
+	loaded, err := model.SpecialDays()
+	if err != nil {
+		t.Fatalf("SpecialDays failed: %v", err)
+	}
+
+	day := loaded.At(time.Date(2026, time.May, 25, 0, 0, 0, 0, time.UTC))
+	if day == nil {
+		t.Fatalf("Expected to find special day for May 25, 2026")
+	}
+	if !day.Holiday || day.Note.Text != "Revolución de Mayo" || day.Icon != "builtin:birthday" || day.QR != "https://example.com/mayo" {
+		t.Errorf("Unexpected special day %+v", day)
+	}
+	if filepath.Ext(cfg.MonthOutputFilePath(cal)) != ".json" {
+		t.Errorf("Expected .json output, got %q", cfg.MonthOutputFilePath(cal))
+	}
+}
+
+func TestJSONCalendar_RendersOnlyModelMonths(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 0
+
+	march, err := galendar.NewCalendar(2025, 3, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	july, err := march.CloneAt(7)
+	if err != nil {
+		t.Fatalf("CloneAt failed: %v", err)
+	}
+
+	cal, err := galendar.NewJSONCalendar(cfg, march, july).Calendar()
+	if err != nil {
+		t.Fatalf("Calendar failed: %v", err)
+	}
+
+	for _, renderer := range []galendar.Renderer{galendar.SVGRenderer{}, galendar.JSONRenderer{}} {
+		cfg.Renderer = renderer
+		if err := renderer.RenderYear(cfg, cal); err != nil {
+			t.Fatalf("%s RenderYear failed: %v", renderer.Name(), err)
+		}
+	}
+
+	entries, err := os.ReadDir(cfg.OutputDir)
+	if err != nil {
+		t.Fatalf("Failed to read output dir: %v", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	expected := []string{"calendar-2025-03.svg", "calendar-2025-07.svg", "calendar-2025.json"}
+	if !slices.Equal(files, expected) {
+		t.Errorf("Expected files %v, got %v", expected, files)
+	}
+
+	model, err := galendar.LoadJSONCalendarFromFile(filepath.Join(cfg.OutputDir, "calendar-2025.json"))
+	if err != nil {
+		t.Fatalf("LoadJSONCalendarFromFile failed: %v", err)
+	}
+	if len(model.Months) != 2 || model.Months[0].Month != 3 || model.Months[1].Month != 7 {
+		t.Errorf("Expected months 3 and 7, got %+v", model.Months)
+	}
+}
+
+func TestJSONCalendar_KeepsEdits(t *testing.T) {
+	cfg := testConfig(t, galendar.JSONRenderer{})
+	cfg.Year, cfg.Month = 2025, 3
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	model := galendar.NewJSONCalendar(cfg, cal)
+
+	// The 1st of March 2025 is a Saturday, in the first row
+	saturday := &model.Months[0].Weeks[0].Days[6]
+	saturday.Holiday = false
+	saturday.Notes = []galendar.JSONNote{{Text: "Open"}, {Text: "C:\\work", URL: "https://example.com"}}
+	saturday.Icons = []string{"builtin:birthday", "builtin:christmas_tree"}
+	model.Months[0].Weeks[0].Days[5].CurrentMonth = true
+
+	edited, err := model.Calendar()
+	if err != nil {
+		t.Fatalf("Calendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderMonth(cfg, edited); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	rendered, err := galendar.LoadJSONCalendarFromFile(cfg.MonthOutputFilePath(edited))
+	if err != nil {
+		t.Fatalf("LoadJSONCalendarFromFile failed: %v", err)
+	}
+
+	day := rendered.Months[0].Weeks[0].Days[6]
+	if day.Holiday {
+		t.Errorf("Expected the 1st to stay a working day")
+	}
+	if len(day.Notes) != 1 || day.Notes[0].Text != "Open\nC:\\work" || day.Notes[0].URL != "https://example.com" {
+		t.Errorf("Expected both notes, got %+v", day.Notes)
+	}
+	if !slices.Equal(day.Icons, saturday.Icons) {
+		t.Errorf("Expected icons %v, got %v", saturday.Icons, day.Icons)
+	}
+	if !rendered.Months[0].Weeks[0].Days[5].CurrentMonth {
+		t.Errorf("Expected the 28th of February to stay in the current month")
+	}
+
+	model.Months[0].Weeks[1].Number++
+	if _, err := model.Calendar(); err == nil {
+		t.Errorf("Expected an error for an edited week number")
+	}
+
+	model.Months[0].Weeks[1].Number--
+	saturday.Notes[0].URL = "https://example.org"
+	if _, err := model.Calendar(); err == nil {
+		t.Errorf("Expected an error for notes with different urls")
+	}
+}
@@ -0,0 +1,110 @@
+package galendar
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// pageText is a line of text laid out on a page, in millimeters
+type pageText struct {
+	x, y  float64 // baseline, x is the center of the text
+	font  string  // one of AllFonts
+	size  float64
+	color Color
+	text  string
+}
+
+// pageRect is a rectangle laid out on a page, in millimeters
+type pageRect struct {
+	x, y, w, h float64
+	fill       Color
+}
+
+// miniMonthRows are the rows of a mini month: name, weekdays and 6 weeks,
+// always 6 so both mini months have the same size
+const miniMonthRows = 8
+
+// layoutMiniMonths lays out the grids of the months before and after cal
+// beside the title starting at top, at the left and right margins of a page
+// of pageWidth
+func layoutMiniMonths(config Config, cal Calendar, pageWidth, top float64) ([]pageText, []pageRect, error) {
+	theme := config.Theme
+
+	// Mini months have a row for every week, folded or not
+	cal = cal.unfolded()
+	previous, err := cal.CloneAt(cal.Month - 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't clone calendar at previous month: %w", err)
+	}
+	next, err := cal.CloneAt(cal.Month + 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't clone calendar at next month: %w", err)
+	}
+
+	var texts []pageText
+	var rects []pageRect
+	for _, mini := range []struct {
+		cal Calendar
+		x   float64
+	}{
+		{previous, theme.Page.Margin},
+		{next, pageWidth - theme.Page.Margin - theme.MiniMonth.Width},
+	} {
+		miniTexts, miniRects := layoutMiniMonth(config, mini.cal, mini.x, top, 1)
+		texts = append(texts, miniTexts...)
+		rects = append(rects, miniRects...)
+	}
+
+	return texts, rects, nil
+}
+
+// layoutMiniMonth lays out the grid of cal with its top left corner at x, y
+// and the height of the title row, scaled by scale
+func layoutMiniMonth(config Config, cal Calendar, x, y, scale float64) ([]pageText, []pageRect) {
+	style := config.Theme.MiniMonth
+	style.Width *= scale
+	style.TitleSize *= scale
+	style.Size *= scale
+	columnWidth := style.Width / 7
+	rowHeight := config.Theme.Title.Height * scale / miniMonthRows
+
+	texts := []pageText{{
+		x: x + style.Width/2, y: centeredBaseline(y, rowHeight, style.TitleSize),
+		font: FontMonths, size: style.TitleSize, color: style.Color,
+		text: fmt.Sprintf("%s %d", config.Language.MonthName(cal.Month), cal.Year),
+	}}
+	var rects []pageRect
+
+	for i, name := range config.Language.WeekdayAbbreviations(cal.WeekStart) {
+		_, size := utf8.DecodeRuneInString(name)
+		texts = append(texts, pageText{
+			x: x + (float64(i)+0.5)*columnWidth, y: centeredBaseline(y+rowHeight, rowHeight, style.Size),
+			font: FontWeekdays, size: style.Size, color: style.Color, text: name[:size],
+		})
+	}
+
+	for weekIdx, week := range cal.Weeks {
+		top := y + float64(weekIdx+2)*rowHeight
+		for dayIdx, day := range week {
+			if !day.IsCurrentMonth {
+				continue
+			}
+
+			left := x + float64(dayIdx)*columnWidth
+			color := style.Color
+			if day.IsHoliday() {
+				color = style.HolidayColor
+				if style.HolidayFill.Valid {
+					rects = append(rects, pageRect{x: left, y: top, w: columnWidth, h: rowHeight, fill: style.HolidayFill})
+				}
+			}
+
+			texts = append(texts, pageText{
+				x: left + columnWidth/2, y: centeredBaseline(top, rowHeight, style.Size),
+				font: FontDays, size: style.Size, color: color, text: fmt.Sprintf("%d", day.DayNumber),
+			})
+		}
+	}
+
+	return texts, rects
+}
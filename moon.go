@@ -0,0 +1,337 @@
+package galendar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// MoonPhase is a principal phase of the moon
+type MoonPhase string
+
+const (
+	MoonNew          MoonPhase = "new"
+	MoonFirstQuarter MoonPhase = "first-quarter"
+	MoonFull         MoonPhase = "full"
+	MoonLastQuarter  MoonPhase = "last-quarter"
+)
+
+// moonPhases are the principal phases in the order of a lunation, a quarter
+// of a lunation apart
+var moonPhases = []MoonPhase{MoonNew, MoonFirstQuarter, MoonFull, MoonLastQuarter}
+
+// Moon is the moon of a day
+type Moon struct {
+	Phase        MoonPhase // principal phase reached during the day, empty on the other days
+	PhaseTime    time.Time // time of Phase, in the location of the calendar
+	Illumination float64   // fraction of the disk lit at noon, from 0 to 1
+	Waxing       bool      // the illumination grows, from new to full moon
+}
+
+// IlluminationPercent returns the illumination of moon as a percentage
+func (moon Moon) IlluminationPercent() int {
+	return int(math.Round(moon.Illumination * 100))
+}
+
+// MoonDisplay tells the renderers which days show the moon
+type MoonDisplay string
+
+const (
+	MoonDisplayNone   MoonDisplay = "none"
+	MoonDisplayPhases MoonDisplay = "phases" // days of principal phases, with the time of the phase
+	MoonDisplayDaily  MoonDisplay = "daily"  // every day, and the time of principal phases
+)
+
+// ParseMoonDisplay parses the days that show the moon, an empty string means
+// none
+func ParseMoonDisplay(s string) (MoonDisplay, error) {
+	switch display := MoonDisplay(strings.ToLower(strings.TrimSpace(s))); display {
+	case "":
+		return MoonDisplayNone, nil
+	case MoonDisplayNone, MoonDisplayPhases, MoonDisplayDaily:
+		return display, nil
+	default:
+		return "", fmt.Errorf("invalid moon: %q (must be none, phases or daily)", s)
+	}
+}
+
+// Constants of the computation of the moon, from "Astronomical Algorithms"
+// by Jean Meeus, chapters 47 to 49
+const (
+	synodicMonth  = 29.530588861 // mean days between new moons
+	jdUnixEpoch   = 2440587.5    // julian day of 1970-01-01 00:00 UTC
+	jdJ2000       = 2451545.0
+	jdNewMoon2000 = 2451550.09766 // mean new moon of 2000-01-06, lunation 0
+)
+
+// moonEvent is a principal phase of the moon at an instant
+type moonEvent struct {
+	phase MoonPhase
+	time  time.Time
+}
+
+// moonOfDays returns the moon of the days from start to end, both included,
+// for the calendar days of loc: principal phases belong to the day they
+// happen in loc and the illumination is the one of noon in loc
+func moonOfDays(start, end time.Time, loc *time.Location) map[time.Time]Moon {
+	dayStart := func(date time.Time) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	}
+
+	events := moonEventsBetween(dayStart(start), dayStart(end).AddDate(0, 0, 1))
+
+	moons := map[time.Time]Moon{}
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		noon := dayStart(date).Add(12 * time.Hour)
+		illumination, waxing := moonIllumination(noon)
+		moon := Moon{Illumination: illumination, Waxing: waxing}
+		for _, event := range events {
+			local := event.time.In(loc)
+			if local.Year() == date.Year() && local.Month() == date.Month() && local.Day() == date.Day() {
+				moon.Phase, moon.PhaseTime = event.phase, local
+			}
+		}
+		moons[date] = moon
+	}
+
+	return moons
+}
+
+// moonEventsBetween returns the principal phases of the moon from start to
+// end, in order
+func moonEventsBetween(start, end time.Time) []moonEvent {
+	k := math.Floor((julianDay(start)-jdNewMoon2000)/synodicMonth) - 1
+
+	var events []moonEvent
+	for ; ; k += 0.25 {
+		event := moonEventAt(k)
+		if event.time.After(end) {
+			return events
+		}
+		if !event.time.Before(start) {
+			events = append(events, event)
+		}
+	}
+}
+
+// moonEventAt returns the principal phase of lunation k since the new moon of
+// January 2000: integers are new moons, .25 first quarters, .5 full moons
+// and .75 last quarters (Meeus, chapter 49)
+func moonEventAt(k float64) moonEvent {
+	t := k / 1236.85
+	t2, t3, t4 := t*t, t*t*t, t*t*t*t
+
+	jde := jdNewMoon2000 + synodicMonth*k + 0.00015437*t2 - 0.000000150*t3 + 0.00000000073*t4
+	e := 1 - 0.002516*t - 0.0000074*t2
+	m := radians(2.5534 + 29.10535670*k - 0.0000014*t2 - 0.00000011*t3)
+	mm := radians(201.5643 + 385.81693528*k + 0.0107582*t2 + 0.00001238*t3 - 0.000000058*t4)
+	f := radians(160.7108 + 390.67050284*k - 0.0016118*t2 - 0.00000227*t3 + 0.000000011*t4)
+	omega := radians(124.7746 - 1.56375588*k + 0.0020672*t2 + 0.00000215*t3)
+
+	phase := moonPhases[int(math.Round((k-math.Floor(k))*4))%4]
+	sin := math.Sin
+
+	switch phase {
+	case MoonNew, MoonFull:
+		c := []float64{-0.40720, 0.17241, 0.01608, 0.01039, 0.00739, -0.00514, 0.00208}
+		if phase == MoonFull {
+			c = []float64{-0.40614, 0.17302, 0.01614, 0.01043, 0.00734, -0.00515, 0.00209}
+		}
+		jde += c[0]*sin(mm) + c[1]*e*sin(m) + c[2]*sin(2*mm) + c[3]*sin(2*f) +
+			c[4]*e*sin(mm-m) + c[5]*e*sin(mm+m) + c[6]*e*e*sin(2*m) -
+			0.00111*sin(mm-2*f) - 0.00057*sin(mm+2*f) + 0.00056*e*sin(2*mm+m) -
+			0.00042*sin(3*mm) + 0.00042*e*sin(m+2*f) + 0.00038*e*sin(m-2*f) -
+			0.00024*e*sin(2*mm-m) - 0.00017*sin(omega) - 0.00007*sin(mm+2*m) +
+			0.00004*sin(2*mm-2*f) + 0.00004*sin(3*m) + 0.00003*sin(mm+m-2*f) +
+			0.00003*sin(2*mm+2*f) - 0.00003*sin(mm+m+2*f) + 0.00003*sin(mm-m+2*f) -
+			0.00002*sin(mm-m-2*f) - 0.00002*sin(3*mm+m) + 0.00002*sin(4*mm)
+	default:
+		jde += -0.62801*sin(mm) + 0.17172*e*sin(m) - 0.01183*e*sin(mm+m) +
+			0.00862*sin(2*mm) + 0.00804*sin(2*f) + 0.00454*e*sin(mm-m) +
+			0.00204*e*e*sin(2*m) - 0.00180*sin(mm-2*f) - 0.00070*sin(mm+2*f) -
+			0.00040*sin(3*mm) - 0.00034*e*sin(2*mm-m) + 0.00032*e*sin(m+2*f) +
+			0.00032*e*sin(m-2*f) - 0.00028*e*e*sin(mm+2*m) + 0.00027*e*sin(2*mm+m) -
+			0.00017*sin(omega) - 0.00005*sin(mm-m-2*f) + 0.00004*sin(2*mm+2*f) -
+			0.00004*sin(mm+m+2*f) + 0.00004*sin(mm-2*m) + 0.00003*sin(mm+m-2*f) +
+			0.00003*sin(3*m) + 0.00002*sin(2*mm-2*f) + 0.00002*sin(mm-m+2*f) -
+			0.00002*sin(3*mm+m)
+
+		cos := math.Cos
+		w := 0.00306 - 0.00038*e*cos(m) + 0.00026*cos(mm) - 0.00002*cos(mm-m) +
+			0.00002*cos(mm+m) + 0.00002*cos(2*f)
+		if phase == MoonFirstQuarter {
+			jde += w
+		} else {
+			jde -= w
+		}
+	}
+
+	// Corrections for the perturbations of the planets, the same for all phases
+	planetary := []struct{ coefficient, base, rate float64 }{
+		{0.000325, 299.77, 0.107408}, {0.000165, 251.88, 0.016321},
+		{0.000164, 251.83, 26.651886}, {0.000126, 349.42, 36.412478},
+		{0.000110, 84.66, 18.206239}, {0.000062, 141.74, 53.303771},
+		{0.000060, 207.14, 2.453732}, {0.000056, 154.84, 7.306860},
+		{0.000047, 34.52, 27.261239}, {0.000042, 207.19, 0.121824},
+		{0.000040, 291.34, 1.844379}, {0.000037, 161.72, 24.198154},
+		{0.000035, 239.56, 25.513099}, {0.000023, 331.55, 3.592518},
+	}
+	for i, term := range planetary {
+		angle := term.base + term.rate*k
+		if i == 0 {
+			angle -= 0.009173 * t2
+		}
+		jde += term.coefficient * sin(radians(angle))
+	}
+
+	return moonEvent{phase: phase, time: timeOfJulianEphemerisDay(jde)}
+}
+
+// moonIllumination returns the fraction of the disk of the moon lit at t and
+// whether it is waxing, from its phase angle (Meeus, chapter 48)
+func moonIllumination(t time.Time) (float64, bool) {
+	c := (julianEphemerisDay(t) - jdJ2000) / 36525
+	c2, c3, c4 := c*c, c*c*c, c*c*c*c
+
+	d := radians(297.8501921 + 445267.1114034*c - 0.0018819*c2 + c3/545868 - c4/113065000)
+	m := radians(357.5291092 + 35999.0502909*c - 0.0001536*c2 + c3/24490000)
+	mm := radians(134.9633964 + 477198.8675055*c + 0.0087414*c2 + c3/69699 - c4/14712000)
+
+	// Elongation of the moon, 180 degrees minus its phase angle
+	elongation := d + radians(6.289*math.Sin(mm)-2.100*math.Sin(m)+1.274*math.Sin(2*d-mm)+
+		0.658*math.Sin(2*d)+0.214*math.Sin(2*mm)+0.110*math.Sin(d))
+
+	illumination := (1 - math.Cos(elongation)) / 2
+	return illumination, math.Mod(math.Mod(elongation, 2*math.Pi)+2*math.Pi, 2*math.Pi) < math.Pi
+}
+
+// julianDay returns the julian day of t, in universal time
+func julianDay(t time.Time) float64 {
+	return jdUnixEpoch + float64(t.UnixMilli())/86400000
+}
+
+// julianEphemerisDay returns the julian day of t in terrestrial time, the
+// time scale of the positions of the moon
+func julianEphemerisDay(t time.Time) float64 {
+	return julianDay(t) + deltaT(t.Year())/86400
+}
+
+// timeOfJulianEphemerisDay returns the instant of a julian day in
+// terrestrial time, to the second
+func timeOfJulianEphemerisDay(jde float64) time.Time {
+	year := 2000 + int((jde-jdJ2000)/365.25)
+	seconds := (jde-jdUnixEpoch)*86400 - deltaT(year)
+	return time.Unix(int64(math.Round(seconds)), 0).UTC()
+}
+
+// deltaT returns the difference in seconds between terrestrial and universal
+// time in year, with the polynomial of Espenak and Meeus for 2005 to 2050
+// that is within a minute of it for the years around them
+func deltaT(year int) float64 {
+	y := float64(year - 2000)
+	return 62.92 + 0.32217*y + 0.005589*y*y
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// pagePoint is a point laid out on a page, in millimeters
+type pagePoint struct {
+	x, y float64
+}
+
+// moonGlyph is the moon of a day laid out on a page, in millimeters: a disk
+// in the shadow color with the lit part over it, the outline and the time of
+// the phase below it
+type moonGlyph struct {
+	cx, cy, r float64
+	lit       []pagePoint // polygon of the lit part, empty on new moons
+	time      *pageText   // time of the principal phase, if any
+}
+
+const (
+	moonGlyphSegments = 24  // points of each edge of the lit part
+	moonOutlineWidth  = 0.2 // in millimeters
+)
+
+// moved returns glyph moved dx, dy millimeters
+func (glyph moonGlyph) moved(dx, dy float64) moonGlyph {
+	glyph.cx += dx
+	glyph.cy += dy
+	lit := make([]pagePoint, len(glyph.lit))
+	for i, point := range glyph.lit {
+		lit[i] = pagePoint{point.x + dx, point.y + dy}
+	}
+	glyph.lit = lit
+	if glyph.time != nil {
+		text := *glyph.time
+		text.x += dx
+		text.y += dy
+		glyph.time = &text
+	}
+	return glyph
+}
+
+// layoutMoon lays out the moon of day for a cell with its top left corner at
+// x, y, if the days shown by config.Moon include it. The moon goes between
+// the day box and the icons, at the top of the cell
+func layoutMoon(config Config, day Day, x, y, cellWidth, dayBoxWidth float64) (moonGlyph, bool) {
+	moon := day.Moon
+	if !day.IsCurrentMonth || config.Moon == MoonDisplayNone || config.Moon == "" ||
+		(config.Moon == MoonDisplayPhases && moon.Phase == "") {
+		return moonGlyph{}, false
+	}
+
+	theme := config.Theme
+	left := x + dayBoxWidth
+	right := x + cellWidth - cellIconsWidth(theme, day, cellWidth)
+	glyph := moonGlyph{
+		cx: (left + max(left, right)) / 2,
+		cy: y + theme.Notes.Padding + theme.Moon.Size/2,
+		r:  theme.Moon.Size / 2,
+	}
+
+	// The principal phases are drawn as they are at their time, not at noon
+	illumination, waxing := moon.Illumination, moon.Waxing
+	switch moon.Phase {
+	case MoonNew:
+		illumination = 0
+	case MoonFirstQuarter:
+		illumination, waxing = 0.5, true
+	case MoonFull:
+		illumination = 1
+	case MoonLastQuarter:
+		illumination, waxing = 0.5, false
+	}
+
+	// The lit limb is a half circle and the terminator a half ellipse, on the
+	// right of the disk while waxing as seen from the northern hemisphere and
+	// on the left as seen from the southern one
+	if illumination > 0 {
+		side := 1.0
+		if waxing == (config.Hemisphere == HemisphereSouth) {
+			side = -1
+		}
+		terminator := 1 - 2*illumination
+		for i := 0; i <= moonGlyphSegments; i++ {
+			angle := math.Pi * (float64(i)/moonGlyphSegments - 0.5)
+			glyph.lit = append(glyph.lit, pagePoint{glyph.cx + side*glyph.r*math.Cos(angle), glyph.cy + glyph.r*math.Sin(angle)})
+		}
+		for i := moonGlyphSegments; i >= 0; i-- {
+			angle := math.Pi * (float64(i)/moonGlyphSegments - 0.5)
+			glyph.lit = append(glyph.lit, pagePoint{glyph.cx + side*terminator*glyph.r*math.Cos(angle), glyph.cy + glyph.r*math.Sin(angle)})
+		}
+	}
+
+	if moon.Phase != "" {
+		glyph.time = &pageText{
+			x: glyph.cx, y: glyph.cy + glyph.r + theme.Moon.TextSize*mmPerPoint*1.2,
+			font: FontNotes, size: theme.Moon.TextSize, color: theme.Moon.Color,
+			text: moon.PhaseTime.Format("15:04"),
+		}
+	}
+
+	return glyph, true
+}
@@ -0,0 +1,115 @@
+package galendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestParseMoonDisplay(t *testing.T) {
+	for input, expected := range map[string]galendar.MoonDisplay{
+		"":        galendar.MoonDisplayNone,
+		"none":    galendar.MoonDisplayNone,
+		"Phases":  galendar.MoonDisplayPhases,
+		" daily ": galendar.MoonDisplayDaily,
+	} {
+		display, err := galendar.ParseMoonDisplay(input)
+		if err != nil {
+			t.Errorf("ParseMoonDisplay(%q) failed: %v", input, err)
+		} else if display != expected {
+			t.Errorf("ParseMoonDisplay(%q) = %q, expected %q", input, display, expected)
+		}
+	}
+
+	if _, err := galendar.ParseMoonDisplay("weekly"); err == nil {
+		t.Errorf("Expected an error for an invalid moon display")
+	}
+}
+
+// moonDays returns the days of the month of cal by day number
+func moonDays(cal galendar.Calendar) map[int]galendar.Day {
+	days := map[int]galendar.Day{}
+	for _, week := range cal.Weeks {
+		for _, day := range week {
+			if day.IsCurrentMonth {
+				days[day.DayNumber] = day
+			}
+		}
+	}
+	return days
+}
+
+func TestNewCalendar_MoonPhases(t *testing.T) {
+	cal, err := galendar.NewCalendar(2025, 1, time.Sunday, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	days := moonDays(cal)
+
+	// Principal phases of January 2025 published by the US Naval Observatory
+	expected := map[int]struct {
+		phase galendar.MoonPhase
+		time  time.Time
+	}{
+		6:  {galendar.MoonFirstQuarter, time.Date(2025, 1, 6, 23, 56, 0, 0, time.UTC)},
+		13: {galendar.MoonFull, time.Date(2025, 1, 13, 22, 27, 0, 0, time.UTC)},
+		21: {galendar.MoonLastQuarter, time.Date(2025, 1, 21, 20, 31, 0, 0, time.UTC)},
+		29: {galendar.MoonNew, time.Date(2025, 1, 29, 12, 36, 0, 0, time.UTC)},
+	}
+
+	for number, day := range days {
+		want, ok := expected[number]
+		if !ok {
+			if day.Moon.Phase != "" {
+				t.Errorf("Expected no principal phase on day %d, got %s", number, day.Moon.Phase)
+			}
+			continue
+		}
+		if day.Moon.Phase != want.phase {
+			t.Errorf("Expected %s moon on day %d, got %q", want.phase, number, day.Moon.Phase)
+		}
+		if diff := day.Moon.PhaseTime.Sub(want.time).Abs(); diff > 2*time.Minute {
+			t.Errorf("Expected the %s moon at %v, got %v", want.phase, want.time, day.Moon.PhaseTime)
+		}
+	}
+
+	if moon := days[13].Moon; moon.IlluminationPercent() < 99 {
+		t.Errorf("Expected a full moon on day 13, got %d%%", moon.IlluminationPercent())
+	}
+	if moon := days[29].Moon; moon.IlluminationPercent() > 1 {
+		t.Errorf("Expected a new moon on day 29, got %d%%", moon.IlluminationPercent())
+	}
+	if moon := days[6].Moon; moon.IlluminationPercent() < 40 || moon.IlluminationPercent() > 50 || !moon.Waxing {
+		t.Errorf("Expected a waxing moon a bit less than half lit on day 6, got %d%% waxing %t", moon.IlluminationPercent(), moon.Waxing)
+	}
+	if moon := days[21].Moon; moon.Waxing {
+		t.Errorf("Expected a waning moon on day 21")
+	}
+}
+
+func TestCalendar_InMovesMoonPhases(t *testing.T) {
+	cal, err := galendar.NewCalendar(2025, 1, time.Sunday, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	// The first quarter at 23:56 UTC of the 6th is at 08:56 of the 7th in Tokyo
+	tokyo := cal.In(time.FixedZone("JST", 9*60*60))
+	days := moonDays(tokyo)
+	if days[6].Moon.Phase != "" || days[7].Moon.Phase != galendar.MoonFirstQuarter {
+		t.Errorf("Expected the first quarter on day 7 in Tokyo, got %q on day 6 and %q on day 7", days[6].Moon.Phase, days[7].Moon.Phase)
+	}
+	if got := days[7].Moon.PhaseTime.Format("15:04"); got < "08:54" || got > "08:58" {
+		t.Errorf("Expected the first quarter about 08:56 in Tokyo, got %s", got)
+	}
+
+	// Calendars of other months keep the time zone
+	next, err := tokyo.CloneAt(2)
+	if err != nil {
+		t.Fatalf("CloneAt failed: %v", err)
+	}
+	if next.Location != tokyo.Location {
+		t.Errorf("Expected CloneAt to keep the time zone, got %v", next.Location)
+	}
+}
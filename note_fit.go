@@ -0,0 +1,219 @@
+package galendar
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// NoteOverflow tells the renderers what to do with the notes that don't fit
+// in their cells even at the minimum font size
+type NoteOverflow string
+
+const (
+	// NoteOverflowTruncate cuts the note with an ellipsis
+	NoteOverflowTruncate NoteOverflow = "truncate"
+	// NoteOverflowFootnote cuts the note and writes it whole in a numbered
+	// footnote at the bottom of the page
+	NoteOverflowFootnote NoteOverflow = "footnote"
+)
+
+const DefaultNoteMinFontSize = 8.0
+
+const (
+	noteLineSpacing = 1.2 // line height relative to the font size
+	noteShrinkStep  = 0.5 // font size decrement while fitting
+	noteEllipsis    = "…"
+)
+
+// ParseNoteOverflow parses a note overflow mode, an empty string means
+// truncate
+func ParseNoteOverflow(s string) (NoteOverflow, error) {
+	switch mode := NoteOverflow(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return NoteOverflowTruncate, nil
+	case NoteOverflowTruncate, NoteOverflowFootnote:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid note overflow: %q (must be truncate or footnote)", s)
+	}
+}
+
+// noteLayout describes where the notes of a month page go, in the units of
+// the renderer
+type noteLayout struct {
+	gridHeight    float64 // height shared by the rows and the footnotes
+	width         float64 // width of the notes in a cell
+	rowPadding    float64 // height of a row not available for the note
+	foldedWidth   float64 // width of the notes in a half of a folded cell
+	foldedPadding float64 // height of a half row not available for the note
+	footnoteWidth float64
+	qrWidth       float64 // width of the notes taken by the QR code of days that have one
+
+	toUnits         func(size float64) float64                                  // converts a font size to units
+	measure         func(day Day, size float64) func(string, textStyle) float64 // measures the runs of the note of day
+	measureFootnote func(size float64) func(string) float64
+}
+
+// fittedNote is a note wrapped to fit in its cell
+type fittedNote struct {
+	lines      []richLine
+	size       float64
+	lineHeight float64
+}
+
+// monthNotes are the notes of a month page fitted in their cells
+type monthNotes struct {
+	rowHeight  float64
+	notes      map[string]fittedNote // by day name
+	overflowed []string              // names of the days whose note didn't fit
+
+	footnotes          []string // lines of the footnote area
+	footnoteSize       float64
+	footnoteLineHeight float64
+}
+
+// fit fits the notes of cal in their cells. Each note is wrapped at its font
+// size, shrinking it down to config.NoteMinFontSize until it fits, and notes
+// that still don't fit are truncated. Footnotes take their room from the
+// rows, so notes are fitted again until all footnotes fit or take half the
+// grid
+func (layout noteLayout) fit(config Config, cal Calendar) monthNotes {
+	minSize := config.NoteMinFontSize
+	if minSize <= 0 {
+		minSize = DefaultNoteMinFontSize
+	}
+
+	footnoteLineHeight := layout.toUnits(minSize) * noteLineSpacing
+	maxReserved := layout.gridHeight / 2
+
+	reserved := 0.0
+	for {
+		notes := monthNotes{
+			rowHeight:          (layout.gridHeight - reserved) / float64(len(cal.Weeks)),
+			notes:              map[string]fittedNote{},
+			footnoteSize:       minSize,
+			footnoteLineHeight: footnoteLineHeight,
+		}
+
+		var footnotes []string
+		for _, week := range cal.Weeks {
+			for _, cell := range week {
+				width, height := layout.width, notes.rowHeight-layout.rowPadding
+				if cell.Folded != nil {
+					width, height = layout.foldedWidth, notes.rowHeight/2-layout.foldedPadding
+				}
+
+				for _, day := range cell.CellDays() {
+					note := day.Note()
+					if note == nil || note.Text == "" || (!day.IsCurrentMonth && !config.ShowExtraDays) {
+						continue
+					}
+
+					size := config.Theme.Notes.Size
+					if note.Size != 0 {
+						size = note.Size
+					}
+
+					noteWidth := width
+					if cell.Folded == nil && day.QR() != "" {
+						noteWidth -= layout.qrWidth
+					}
+
+					suffix := noteEllipsis
+					if config.NoteOverflow == NoteOverflowFootnote {
+						suffix = fmt.Sprintf("%s [%d]", noteEllipsis, len(footnotes)+1)
+					}
+
+					measure := func(size float64) func(string, textStyle) float64 {
+						return layout.measure(day, size)
+					}
+					fitted, ok := layout.fitNote(note.runs(), size, min(size, minSize), noteWidth, height, suffix, measure)
+					if !ok {
+						notes.overflowed = append(notes.overflowed, day.Name())
+						if config.NoteOverflow == NoteOverflowFootnote {
+							text := strings.Join(strings.Fields(note.plainText()), " ")
+							footnotes = append(footnotes, fmt.Sprintf("[%d] %d: %s", len(footnotes)+1, day.DayNumber, text))
+						}
+					}
+					notes.notes[day.Name()] = fitted
+				}
+			}
+		}
+
+		if len(footnotes) == 0 {
+			return notes
+		}
+
+		// one extra line separates the footnotes from the grid
+		maxLines := int(maxReserved/footnoteLineHeight) - 1
+		notes.footnotes = layout.footnoteLines(config, footnotes, minSize, maxLines)
+		needed := min(float64(len(notes.footnotes)+1)*footnoteLineHeight, maxReserved)
+		if needed <= reserved {
+			return notes
+		}
+		reserved = needed
+	}
+}
+
+// footnoteLines wraps footnotes at size in at most maxLines lines, the
+// footnotes that don't fit are left out and counted in the last line
+func (layout noteLayout) footnoteLines(config Config, footnotes []string, size float64, maxLines int) []string {
+	measure := layout.measureFootnote(size)
+	maxLines = max(1, maxLines)
+
+	var lines []string
+	for i, footnote := range footnotes {
+		wrapped := wrapText(footnote, layout.footnoteWidth, measure)
+		available := maxLines - len(lines)
+		if i < len(footnotes)-1 {
+			available-- // for the count of the ones left out
+		}
+		if len(wrapped) > available {
+			return append(lines, fmt.Sprintf(config.Language.Read("…and %d more"), len(footnotes)-i))
+		}
+		lines = append(lines, wrapped...)
+	}
+	return lines
+}
+
+// fitNote wraps runs shrinking their size down to minSize until they fit in
+// width and height. If they don't fit even at minSize, the lines that fit are
+// returned with the last one ending in suffix and ok is false
+func (layout noteLayout) fitNote(runs []textRun, size, minSize, width, height float64, suffix string, measure func(size float64) func(string, textStyle) float64) (fitted fittedNote, ok bool) {
+	for {
+		fitted = fittedNote{
+			lines:      wrapRuns(runs, width, measure(size)),
+			size:       size,
+			lineHeight: layout.toUnits(size) * noteLineSpacing,
+		}
+		if float64(len(fitted.lines))*fitted.lineHeight <= height {
+			return fitted, true
+		}
+		if size <= minSize {
+			break
+		}
+		size = max(size-noteShrinkStep, minSize)
+	}
+
+	maxLines := max(1, int(height/fitted.lineHeight))
+	fitted.lines = fitted.lines[:min(maxLines, len(fitted.lines))]
+	last := len(fitted.lines) - 1
+	fitted.lines[last] = truncateRichLine(fitted.lines[last], suffix, width, measure(fitted.size))
+
+	return fitted, false
+}
+
+// warnOverflowed reports the days of a month whose notes didn't fit
+func (notes monthNotes) warnOverflowed(config Config, cal Calendar) {
+	if len(notes.overflowed) == 0 {
+		return
+	}
+
+	action := "truncated"
+	if config.NoteOverflow == NoteOverflowFootnote {
+		action = "moved to footnotes"
+	}
+	log.Printf("notes that don't fit in their cells in %04d-%02d were %s: %s",
+		cal.Year, cal.Month, action, strings.Join(notes.overflowed, ", "))
+}
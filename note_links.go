@@ -0,0 +1,39 @@
+package galendar
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// noteLinkPattern matches the [text](url) links of note texts, urls can have
+// balanced parentheses as the ones of wikipedia
+var noteLinkPattern = regexp.MustCompile(`\[([^\[\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)\)`)
+
+// parseNoteLinks returns text with its link replaced by its text, and its
+// url. Notes open a single url, so texts with more links are an error
+func parseNoteLinks(text string) (string, string, error) {
+	matches := noteLinkPattern.FindAllStringSubmatch(text, -1)
+	switch len(matches) {
+	case 0:
+		return text, "", nil
+	case 1:
+		return noteLinkPattern.ReplaceAllString(text, "$1"), matches[0][2], nil
+	default:
+		return "", "", fmt.Errorf("notes can have one link, found %d", len(matches))
+	}
+}
+
+// noteLink returns the url of the note of day and the color of its text.
+// Themes for print only and PDF/X output have no links
+func (config Config) noteLink(day Day) (string, Color) {
+	color := config.Theme.noteColor(day)
+	note := day.Note()
+	if note == nil || note.URL == "" || config.Theme.PrintOnly || config.Print.PDFX {
+		return "", color
+	}
+
+	if config.Theme.Links.Color.Valid && day.IsCurrentMonth {
+		color = config.Theme.Links.Color
+	}
+	return note.URL, color
+}
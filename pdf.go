@@ -5,7 +5,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/adrg/sysfont"
 	"github.com/jung-kurt/gofpdf"
 )
 
@@ -22,17 +21,36 @@ func (r PDFRenderer) Name() string {
 
 // RenderMonth renders a single month calendar to PDF
 func (PDFRenderer) RenderMonth(config Config, cal Calendar) error {
-	pdf, err := createDocument(config)
+	if err := preflightPDF(config, cal); err != nil {
+		return err
+	}
+	config = config.Print.withSafeZone(config)
+	config = config.withFontSizes()
+
+	pdf, err := createDocument(config, config.Theme.title(config, cal))
 	if err != nil {
 		return fmt.Errorf("can't create document: %w", err)
 	}
+	months := []Calendar{cal}
+	newPDFNavigation(pdf, config, months)
 
 	err = renderMonthPage(pdf, config, cal)
 	if err != nil {
 		return fmt.Errorf("failed to render month page %d: %w", cal.Month, err)
 	}
 
-	err = pdf.OutputFileAndClose(config.MonthOutputFilePath(cal))
+	if config.Appendix {
+		if err := renderAppendixPages(pdf, config, months); err != nil {
+			return fmt.Errorf("failed to render appendix: %w", err)
+		}
+	}
+
+	err = imposePDF(pdf, config)
+	if err != nil {
+		return fmt.Errorf("can't impose pages: %w", err)
+	}
+
+	err = outputPDF(pdf, config, config.MonthOutputFilePath(cal))
 	if err != nil {
 		return fmt.Errorf("can't output file: %w", err)
 	}
@@ -40,27 +58,59 @@ func (PDFRenderer) RenderMonth(config Config, cal Calendar) error {
 	return nil
 }
 
-// RenderYear renders a full year calendar (12 months) to a single PDF
+// RenderYear renders a full year calendar (12 months, or the months of a
+// calendar from a model) to a single PDF
 func (PDFRenderer) RenderYear(config Config, cal Calendar) error {
-	pdf, err := createDocument(config)
+	if err := preflightPDF(config, cal); err != nil {
+		return err
+	}
+	config = config.Print.withSafeZone(config)
+	config = config.withFontSizes()
+
+	months, err := cal.months()
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("%s - %s", config.Theme.title(config, months[0]), config.Theme.title(config, months[len(months)-1]))
+	pdf, err := createDocument(config, subject)
 	if err != nil {
 		return fmt.Errorf("can't create document: %w", err)
 	}
+	newPDFNavigation(pdf, config, months)
 
-	// Render each month on a separate page
-	for month := 1; month <= 12; month++ {
-		cal, err = cal.CloneAt(month)
-		if err != nil {
-			return fmt.Errorf("can't clone calendar at month %d: %w", month, err)
+	if config.CoverImage != "" {
+		if err := renderCoverPage(pdf, config); err != nil {
+			return fmt.Errorf("failed to render cover page: %w", err)
 		}
+	}
 
+	if config.YearIndex {
+		if err := renderYearIndexPage(pdf, config, months); err != nil {
+			return fmt.Errorf("failed to render year index: %w", err)
+		}
+	}
+
+	// Render each month on a separate page
+	for _, cal := range months {
 		err := renderMonthPage(pdf, config, cal)
 		if err != nil {
-			return fmt.Errorf("failed to render month page %d: %w", month, err)
+			return fmt.Errorf("failed to render month page %d: %w", cal.Month, err)
 		}
 	}
 
-	err = pdf.OutputFileAndClose(config.YearOutputFilePath())
+	if config.Appendix {
+		if err := renderAppendixPages(pdf, config, months); err != nil {
+			return fmt.Errorf("failed to render appendix: %w", err)
+		}
+	}
+
+	err = imposePDF(pdf, config)
+	if err != nil {
+		return fmt.Errorf("can't impose pages: %w", err)
+	}
+
+	err = outputPDF(pdf, config, config.YearOutputFilePath())
 	if err != nil {
 		return fmt.Errorf("can't output file: %w", err)
 	}
@@ -68,144 +118,489 @@ func (PDFRenderer) RenderYear(config Config, cal Calendar) error {
 	return nil
 }
 
-func renderMonthPage(pdf *gofpdf.Fpdf, config Config, cal Calendar) error {
-	pdf.AddPage()
+func renderMonthPage(pdf *pdfDocument, config Config, cal Calendar) error {
+	// The image of the month goes in its own page before the grid or above it
+	photo, top := photoArea(config, pdfPageWidth, pdfPageHeight)
+	if config.Layout == LayoutSpread {
+		err := drawPDFPage(pdf, config, func() error {
+			markPDFMonthPage(pdf, config, cal)
+			return drawPDFPhoto(pdf, config, config.Images[cal.Month], photo)
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-	pageWidth, pageHeight := pdf.GetPageSize()
-	margin := 16.0
+	return drawPDFPage(pdf, config, func() error {
+		if config.Layout != LayoutSpread {
+			markPDFMonthPage(pdf, config, cal)
+		}
+		if config.Layout == LayoutSplit {
+			if err := drawPDFPhoto(pdf, config, config.Images[cal.Month], photo); err != nil {
+				return err
+			}
+		}
+		return drawPDFMonthGrid(pdf, config, cal, top)
+	})
+}
+
+// drawPDFMonthGrid draws the title, weekdays and grid of cal starting at top
+func drawPDFMonthGrid(pdf *pdfDocument, config Config, cal Calendar, top float64) error {
+	theme := config.Theme
+	pageWidth, pageHeight := pdfPageWidth, pdfPageHeight
+	margin := theme.Page.Margin
 	contentWidth := pageWidth - 2*margin
-	contentHeight := pageHeight - 2*margin
 
 	// Title (Month Year)
-	setFont(pdf, FontMonths, 24)
+	setFont(pdf, FontMonths, theme.Title.Size)
 	if err := pdf.Error(); err != nil {
 		return fmt.Errorf("can't set font %q: %w", FontMonths, err)
 	}
-	pdf.SetTextColor(0, 0, 0)
-	title := fmt.Sprintf("%s %d", config.Language.MonthName(cal.Month), cal.Year)
+	setPDFTextColor(pdf, theme.Title.Color)
+	title := theme.title(config, cal)
 	titleWidth := pdf.GetStringWidth(title)
-	pdf.SetXY((pageWidth/2)-(titleWidth/2), margin)
-	pdf.Cell(titleWidth, 15, title)
+	pdf.Text((pageWidth-titleWidth)/2, centeredBaseline(top, theme.Title.Height, theme.Title.Size), title)
 	if err := pdf.Error(); err != nil {
-		return fmt.Errorf("can't write cell %q: %w", title, err)
+		return fmt.Errorf("can't write title %q: %w", title, err)
+	}
+
+	if config.MiniMonths {
+		if err := drawPDFMiniMonths(pdf, config, cal, pageWidth, top); err != nil {
+			return fmt.Errorf("can't draw mini months: %w", err)
+		}
 	}
 
 	// Weekday headers
-	setFont(pdf, FontWeekdays, 22)
+	setFont(pdf, FontWeekdays, theme.Weekdays.Size)
 	if err := pdf.Error(); err != nil {
 		return fmt.Errorf("can't set font %q: %w", FontWeekdays, err)
 	}
-	pdf.SetTextColor(0, 0, 0)
+	setPDFTextColor(pdf, theme.Weekdays.Color)
 	weekdayNames := config.Language.WeekdayAbbreviations(cal.WeekStart)
 	cellWidth := contentWidth / 7
-	cellHeight := 10.0
-	headerY := margin * 2.2
+	headerY := top + theme.Title.Height
 
 	for i, dayName := range weekdayNames {
 		dayWidth := pdf.GetStringWidth(dayName)
 		x := (margin + float64(i)*cellWidth) + (cellWidth / 2) - (dayWidth / 2)
-
-		pdf.SetTextColor(0, 0, 0)
-		pdf.SetXY(x, headerY)
-		pdf.Cell(cellWidth, cellHeight, dayName)
+		pdf.Text(x, centeredBaseline(headerY, theme.Weekdays.Height, theme.Weekdays.Size), dayName)
 		if err := pdf.Error(); err != nil {
-			return fmt.Errorf("can't write cell %q: %w", dayName, err)
+			return fmt.Errorf("can't write weekday %q: %w", dayName, err)
 		}
 	}
 
 	// Calendar grid
-	gridStartY := headerY + cellHeight
-	rows := len(cal.Weeks)
-	rowHeight := (contentHeight - (gridStartY - margin)) / float64(rows)
+	gridStartY := headerY + theme.Weekdays.Height
+	dayBoxWidth := cellWidth * theme.DayBox.Width
+	gridHeight := pageHeight - margin - gridStartY - config.footerHeight()
+	// Short grids, as the one below an image, have rows lower than the day box
+	dayBoxHeight := min(theme.DayBox.Height, gridHeight/float64(len(cal.Weeks)))
+	qrSize := config.cellQRSize(cellWidth, gridHeight/float64(len(cal.Weeks)), dayBoxHeight)
+
+	for _, week := range cal.Weeks {
+		for _, cell := range week {
+			for _, day := range cell.CellDays() {
+				if note := day.Note(); note != nil && note.Font != "" {
+					if err := registerFont(pdf, day.Name(), note.Font); err != nil {
+						return fmt.Errorf("failed to register font %s: %w", day.Name(), err)
+					}
+				}
+				if err := registerPDFNoteVariants(pdf, config, day); err != nil {
+					return err
+				}
+			}
+		}
+	}
 
-	noteFontSize, noteLineHeight := config.FontSizes[FontNotes], 0.0
-	switch rows {
-	case 4:
-		noteLineHeight = (noteFontSize / 2) - 1
-	case 5:
-		noteFontSize, noteLineHeight = noteFontSize-2, (noteFontSize/2)-1
-	case 6:
-		noteFontSize, noteLineHeight = noteFontSize-4, (noteFontSize/2)-3
+	// Fit the notes in their cells measuring with the registered fonts, with
+	// the same logic as the SVG renderer
+	notes := noteLayout{
+		gridHeight:    gridHeight,
+		width:         cellWidth - 2*theme.Notes.Padding,
+		rowPadding:    dayBoxHeight + theme.Notes.Gap + theme.Notes.Padding + config.cellFooterHeight(),
+		foldedWidth:   cellWidth/2 - theme.Notes.Padding,
+		foldedPadding: theme.Folded.Header + theme.Notes.Gap,
+		footnoteWidth: contentWidth,
+		qrWidth:       qrSize,
+		toUnits:       pdf.PointToUnitConvert,
+		measure: func(day Day, size float64) func(string, textStyle) float64 {
+			return func(text string, style textStyle) float64 {
+				setPDFNoteFont(pdf, config, day, style, size)
+				return pdf.GetStringWidth(text)
+			}
+		},
+		measureFootnote: func(size float64) func(string) float64 {
+			return measurePDFText(pdf, FontNotes, size)
+		},
+	}.fit(config, cal)
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("can't measure notes: %w", err)
 	}
+	notes.warnOverflowed(config, cal)
+	rowHeight := notes.rowHeight
 
 	for weekIdx, week := range cal.Weeks {
 		for dayIdx, day := range week {
 			x := margin + float64(dayIdx)*cellWidth
 			y := gridStartY + float64(weekIdx)*rowHeight
 
-			// Draw cell border
-			pdf.SetDrawColor(150, 150, 150)
-			pdf.Rect(x, y, cellWidth, rowHeight, "D")
+			// Draw cell background and border
+			drawPDFRect(pdf, x, y, cellWidth, rowHeight, theme.cellBackground(day), theme.Cell.Border)
 
-			// Get text and fill colors
-			tr, tg, tb, ta := day.TextColor()
-			if ta == 0 && !config.ShowExtraDays {
+			if !day.IsCurrentMonth && !config.ShowExtraDays {
 				continue
 			}
-			pdf.SetTextColor(tr, tg, tb)
+			if day.Folded == nil {
+				linkPDFDay(pdf, day, pageRect{x: x, y: y, w: cellWidth, h: rowHeight})
+			}
 
-			dayBoxHeight := 12.0
-			dayBoxBottom := y + dayBoxHeight
-			if day.IsCurrentMonth {
-				fr, fg, fb, fa := day.FillColor()
-				fillStyle := "D"
-				if fa != 0 {
-					fillStyle = "FD"
-					pdf.SetFillColor(fr, fg, fb)
+			if day.Folded != nil {
+				if err := drawPDFFoldedCell(pdf, config, notes, day, x, y, cellWidth, rowHeight); err != nil {
+					return err
 				}
+				continue
+			}
 
-				// Draw number box on current month days only
-				pdf.Rect(x, y, cellWidth/3, dayBoxHeight, fillStyle)
+			// Draw number box on current month days only
+			if day.IsCurrentMonth {
+				drawPDFRect(pdf, x, y, dayBoxWidth, dayBoxHeight, theme.dayBoxFill(day), theme.DayBox.Border)
 			}
 
-			// Draw day number
+			// Draw day number centered in the day box
+			setFont(pdf, FontDays, theme.DayNumber.Size)
+			if err := pdf.Error(); err != nil {
+				return fmt.Errorf("can't set font %q: %w", FontDays, err)
+			}
 			dayText := fmt.Sprintf("%d", day.DayNumber)
 			numberWidth := pdf.GetStringWidth(dayText)
-			if len(dayText) >= 2 {
-				numberWidth = 0
-			}
-			setFont(pdf, FontDays, 20)
+			setPDFTextColor(pdf, theme.dayNumberColor(day))
+			pdf.Text(x+(dayBoxWidth-numberWidth)/2, centeredBaseline(y, dayBoxHeight, theme.DayNumber.Size), dayText)
 			if err := pdf.Error(); err != nil {
-				return fmt.Errorf("can't set font %q: %w", FontWeekdays, err)
+				return fmt.Errorf("can't write day number %q: %w", dayText, err)
 			}
-			pdf.SetXY(x+1+(numberWidth/2), y-(rowHeight/2)+8)
-			pdf.Cell(cellWidth-4, rowHeight-4, dayText)
-			if err := pdf.Error(); err != nil {
-				return fmt.Errorf("can't write cell %q: %w", dayText, err)
+
+			// Draw special day icons (same position as in the SVG renderer)
+			if day.IsCurrentMonth {
+				for _, icon := range layoutCellIcons(theme, day, x, y, cellWidth) {
+					if err := drawIcon(pdf, icon.icon, icon.x, icon.y, icon.size); err != nil {
+						return fmt.Errorf("can't draw icon %q on %s: %w", icon.icon, day.Name(), err)
+					}
+				}
+			}
+
+			if glyph, ok := layoutMoon(config, day, x, y, cellWidth, dayBoxWidth); ok {
+				drawPDFMoon(pdf, config, glyph)
 			}
 
-			if note := day.Note(); note != nil {
-				noteSize := noteFontSize
-				noteHeight := noteLineHeight
-				if note.Size != 0 {
-					noteSize = note.Size
-					noteHeight = (noteSize / 2) - 1
+			if note, ok := notes.notes[day.Name()]; ok {
+				if err := drawPDFNote(pdf, config, day, note, x+theme.Notes.Padding, y+dayBoxHeight+theme.Notes.Gap); err != nil {
+					return err
 				}
-				if note.Font != "" {
-					if err := registerFont(pdf, day.Name(), note.Font); err != nil {
-						return fmt.Errorf("failed to register font %s: %w", day.Name(), err)
-					}
-					setFont(pdf, day.Name(), noteSize)
-				} else {
-					setFont(pdf, FontNotes, noteSize)
+			}
+
+			if sun, ok := layoutSun(config, day, x, y, rowHeight); ok {
+				if err := drawPDFSun(pdf, sun); err != nil {
+					return fmt.Errorf("can't write sun of %s: %w", day.Name(), err)
 				}
-				if err := pdf.Error(); err != nil {
-					return fmt.Errorf("can't set font %q: %w", FontNotes, err)
+			}
+
+			if label, ok := layoutDayLabel(config, day, x, y, cellWidth, rowHeight); ok {
+				if err := drawPDFDayLabel(pdf, label); err != nil {
+					return fmt.Errorf("can't write day label of %s: %w", day.Name(), err)
 				}
-				pdf.SetXY(x+1, dayBoxBottom+2)
-				pdf.MultiCell(cellWidth, noteHeight, note.Text, "", "L", false)
-				if err := pdf.Error(); err != nil {
-					return fmt.Errorf("can't write multi cell %q: %w", note.Text, err)
+			}
+
+			if qr := day.QR(); qr != "" && day.IsCurrentMonth {
+				size := min(qrSize, config.cellQRSize(cellWidth, rowHeight, dayBoxHeight))
+				padding := theme.Notes.Padding
+				if err := drawPDFQRCode(pdf, config, qr, x+cellWidth-padding-size, y+rowHeight-padding-size-config.dayLabelHeight(), size); err != nil {
+					return fmt.Errorf("can't draw qr code of %s: %w", day.Name(), err)
 				}
 			}
 		}
 	}
 
+	if config.FooterQR != "" {
+		size := config.qrSize()
+		if err := drawPDFQRCode(pdf, config, config.FooterQR, pageWidth-margin-size, pageHeight-margin-size, size); err != nil {
+			return fmt.Errorf("can't draw footer qr code: %w", err)
+		}
+	}
+
+	// Footnotes with the notes that didn't fit, below the grid
+	if len(notes.footnotes) > 0 {
+		setFont(pdf, FontNotes, notes.footnoteSize)
+		setPDFTextColor(pdf, theme.Footnotes.Color)
+		footnotesTop := gridStartY + float64(len(cal.Weeks))*rowHeight + notes.footnoteLineHeight
+		for i, line := range notes.footnotes {
+			pdf.Text(margin, lineBaseline(footnotesTop, notes.footnoteLineHeight, i), line)
+		}
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("can't write footnotes: %w", err)
+		}
+	}
+
+	return pdf.Error()
+}
+
+// renderCoverPage renders a page with the cover image and the year below it
+func renderCoverPage(pdf *pdfDocument, config Config) error {
+	return drawPDFPage(pdf, config, func() error {
+		area, title := coverLayout(config, pdfPageWidth, pdfPageHeight)
+		if err := drawPDFPhoto(pdf, config, config.CoverImage, area); err != nil {
+			return err
+		}
+
+		setFont(pdf, title.font, title.size)
+		setPDFTextColor(pdf, title.color)
+		pdf.Text(title.x-pdf.GetStringWidth(title.text)/2, title.y, title.text)
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("can't write cover title %q: %w", title.text, err)
+		}
+
+		return nil
+	})
+}
+
+// drawPDFPhoto draws the image at imagePath in area with the border of the
+// theme, months without an image leave the area empty
+func drawPDFPhoto(pdf *pdfDocument, config Config, imagePath string, area pageRect) error {
+	if imagePath == "" {
+		return nil
+	}
+
+	if err := drawImage(pdf, imagePath, area, config.ImageFit); err != nil {
+		return fmt.Errorf("can't draw image %q: %w", imagePath, err)
+	}
+	drawPDFRect(pdf, area.x, area.y, area.w, area.h, Color{}, config.Theme.Photo.Border)
+
 	return pdf.Error()
 }
 
-func createDocument(config Config) (*gofpdf.Fpdf, error) {
-	pdf := gofpdf.New("L", "mm", "A4", "")
+// drawPDFFoldedCell draws the two days of a folded cell, see layoutFoldedCell
+func drawPDFFoldedCell(pdf *pdfDocument, config Config, notes monthNotes, cell Day, x, y, w, h float64) error {
+	theme := config.Theme
+
+	if diagonal := theme.Folded.Diagonal; diagonal.Visible() {
+		setPDFDrawColor(pdf, diagonal.Color)
+		pdf.SetLineWidth(diagonal.Width)
+		pdf.Line(x+w, y, x, y+h)
+	}
+
+	for _, half := range layoutFoldedCell(config, cell.CellDays(), x, y, w, h) {
+		drawPDFRect(pdf, half.box.x, half.box.y, half.box.w, half.box.h, half.box.fill, theme.DayBox.Border)
+		linkPDFDay(pdf, half.day, half.box)
+
+		number := half.number
+		setFont(pdf, number.font, number.size)
+		setPDFTextColor(pdf, number.color)
+		pdf.Text(number.x-pdf.GetStringWidth(number.text)/2, number.y, number.text)
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("can't write day number %q: %w", number.text, err)
+		}
+
+		for _, icon := range half.icons {
+			if err := drawIcon(pdf, icon.icon, icon.x, icon.y, icon.size); err != nil {
+				return fmt.Errorf("can't draw icon %q on %s: %w", icon.icon, half.day.Name(), err)
+			}
+		}
+
+		if half.moon != nil {
+			drawPDFMoon(pdf, config, *half.moon)
+		}
+
+		if note, ok := notes.notes[half.day.Name()]; ok {
+			if err := drawPDFNote(pdf, config, half.day, note, half.notesX, half.notesTop); err != nil {
+				return err
+			}
+		}
+	}
+
+	return pdf.Error()
+}
+
+// drawPDFNote draws the fitted note of day with its first line at top
+func drawPDFNote(pdf *pdfDocument, config Config, day Day, note fittedNote, x, top float64) error {
+	url, color := config.noteLink(day)
+	width := 0.0
+	for i, line := range note.lines {
+		baseline := lineBaseline(top, note.lineHeight, i)
+		runX := x
+		for _, run := range line {
+			runColor := color
+			if run.style.color.Valid {
+				runColor = run.style.color
+			}
+			drawPDFRun(pdf, config, day, run, note.size, runColor, runX, baseline)
+			runX += pdf.GetStringWidth(run.text)
+		}
+		width = max(width, runX-x)
+	}
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("can't write note %q: %w", day.Note().Text, err)
+	}
+
+	if url != "" {
+		addPDFURLLink(pdf, pageRect{x: x, y: top, w: width, h: float64(len(note.lines)) * note.lineHeight}, url)
+	}
+
+	return nil
+}
+
+// Styles faked for fonts without bold or italic variants
+const (
+	fakeBoldStroke = 0.03 // outline of the glyphs relative to the font size
+	fakeItalicSkew = 12.0 // slant of the glyphs, in degrees
+)
+
+// drawPDFRun draws a run of the note of day at x and baseline. Bold and
+// italic are faked, stroking and slanting the glyphs, when the font of the
+// note has no such variant
+func drawPDFRun(pdf *pdfDocument, config Config, day Day, run textRun, size float64, color Color, x, baseline float64) {
+	fake := setPDFNoteFont(pdf, config, day, run.style, size)
+	setPDFTextColor(pdf, color)
+
+	if fake.bold {
+		setPDFDrawColor(pdf, color)
+		pdf.SetLineWidth(pdf.PointToUnitConvert(size) * fakeBoldStroke)
+		pdf.SetTextRenderingMode(2)
+	}
+	if fake.italic {
+		pdf.TransformBegin()
+		pdf.TransformSkewX(fakeItalicSkew, x, baseline)
+	}
+
+	pdf.Text(x, baseline, run.text)
+
+	if fake.italic {
+		pdf.TransformEnd()
+	}
+	if fake.bold {
+		pdf.SetTextRenderingMode(0)
+	}
+}
+
+// drawPDFMiniMonths draws the previous and next months beside the title
+func drawPDFMiniMonths(pdf *pdfDocument, config Config, cal Calendar, pageWidth, top float64) error {
+	texts, rects, err := layoutMiniMonths(config, cal, pageWidth, top)
+	if err != nil {
+		return err
+	}
+
+	drawPDFTexts(pdf, texts, rects)
+	return pdf.Error()
+}
+
+// drawPDFTexts draws rects and texts centered on their x, over the rects
+func drawPDFTexts(pdf *pdfDocument, texts []pageText, rects []pageRect) {
+	for _, rect := range rects {
+		drawPDFRect(pdf, rect.x, rect.y, rect.w, rect.h, rect.fill, Line{})
+	}
+	for _, text := range texts {
+		setFont(pdf, text.font, text.size)
+		setPDFTextColor(pdf, text.color)
+		pdf.Text(text.x-pdf.GetStringWidth(text.text)/2, text.y, text.text)
+	}
+}
+
+// drawPDFQRCode draws the QR code of text as a square of size at x, y
+func drawPDFQRCode(pdf *pdfDocument, config Config, text string, x, y, size float64) error {
+	qr, err := EncodeQR(text, config.QRLevel)
+	if err != nil {
+		return err
+	}
+
+	drawPDFRect(pdf, x, y, size, size, qrLight, Line{})
+	setPDFFillColor(pdf, qrDark)
+	for _, module := range qr.rects(x, y, size) {
+		pdf.Rect(module.x, module.y, module.w, module.h, "F")
+	}
+
+	return pdf.Error()
+}
+
+// drawPDFMoon draws the moon of a day, see layoutMoon
+func drawPDFMoon(pdf *pdfDocument, config Config, glyph moonGlyph) {
+	style := config.Theme.Moon
+
+	if style.Shadow.Valid {
+		setPDFFillColor(pdf, style.Shadow)
+		pdf.Circle(glyph.cx, glyph.cy, glyph.r, "F")
+	}
+	if style.Light.Valid && len(glyph.lit) > 0 {
+		points := make([]gofpdf.PointType, len(glyph.lit))
+		for i, point := range glyph.lit {
+			points[i] = gofpdf.PointType{X: point.x, Y: point.y}
+		}
+		setPDFFillColor(pdf, style.Light)
+		pdf.Polygon(points, "F")
+	}
+	if style.Color.Valid {
+		setPDFDrawColor(pdf, style.Color)
+		pdf.SetLineWidth(moonOutlineWidth)
+		pdf.Circle(glyph.cx, glyph.cy, glyph.r, "D")
+	}
+
+	if glyph.time != nil {
+		drawPDFTexts(pdf, []pageText{*glyph.time}, nil)
+	}
+}
+
+// drawPDFSun draws the sun of a day, see layoutSun
+func drawPDFSun(pdf *pdfDocument, sun sunText) error {
+	setFont(pdf, FontNotes, sun.size)
+	setPDFTextColor(pdf, sun.color)
+	for i, line := range sun.lines {
+		pdf.Text(sun.x, lineBaseline(sun.top, sun.lineHeight, i), line)
+	}
+	return pdf.Error()
+}
+
+// drawPDFDayLabel draws the day label of a day, see layoutDayLabel
+func drawPDFDayLabel(pdf *pdfDocument, label dayLabelText) error {
+	setFont(pdf, FontNotes, label.size)
+	setPDFTextColor(pdf, label.color)
+	pdf.Text(label.right-pdf.GetStringWidth(label.text), label.y, label.text)
+	return pdf.Error()
+}
+
+// drawPDFRect draws a rectangle with the given fill and border, any of them
+// can be missing
+func drawPDFRect(pdf *pdfDocument, x, y, w, h float64, fill Color, border Line) {
+	style := ""
+	if fill.Valid {
+		setPDFFillColor(pdf, fill)
+		style += "F"
+	}
+	if border.Visible() {
+		setPDFDrawColor(pdf, border.Color)
+		pdf.SetLineWidth(border.Width)
+		style += "D"
+	}
+	if style != "" {
+		pdf.Rect(x, y, w, h, style)
+	}
+}
+
+// createDocument creates a document with the fonts of config registered and
+// its metadata, the language is set by outputPDF
+func createDocument(config Config, subject string) (*pdfDocument, error) {
+	pdf := newPrintDocument(config.Print)
+
+	pdf.SetTitle(fmt.Sprintf("%s %d", config.Language.Read("Calendar"), config.Year), true)
+	pdf.SetSubject(subject, true)
+	if config.Author != "" {
+		pdf.SetAuthor(config.Author, true)
+	}
+	pdf.SetCreator("galendar", true)
+
+	// Reproducible output: fixed metadata dates and sorted resources
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(config.OutputDate())
+	pdf.SetModificationDate(config.OutputDate())
+
+	// Pages are laid out to fit, text near the bottom must not add pages
+	pdf.SetAutoPageBreak(false, 0)
 
 	for _, name := range AllFonts {
 		font := config.Fonts[name]
@@ -217,24 +612,22 @@ func createDocument(config Config) (*gofpdf.Fpdf, error) {
 	return pdf, nil
 }
 
-func registerFont(pdf *gofpdf.Fpdf, internalFontName, fontName string) error {
-	ext := strings.ToLower(filepath.Ext(fontName))
-	if ext == ".ttf" || ext == ".otf" {
-		return registerFontFile(pdf, internalFontName, fontName)
-	}
-
-	fontsFinder := sysfont.NewFinder(nil)
-	font := fontsFinder.Match(fontName)
-	if font == nil {
-		return fmt.Errorf("font %s (%q) not found", fontName, internalFontName)
+func registerFont(pdf *pdfDocument, internalFontName, fontName string) error {
+	filename, err := resolveFontFile(fontName)
+	if err != nil {
+		return fmt.Errorf("font %s (%q) not found: %w", fontName, internalFontName, err)
 	}
 
-	return registerFontFile(pdf, internalFontName, font.Filename)
+	return registerFontFile(pdf, internalFontName, filename)
 }
 
-var registeredFontsStyle map[string]string
+func registerFontFile(pdf *pdfDocument, fontName, filename string) error {
+	// gofpdf only parses single fonts, the fonts of a collection have to be
+	// extracted to their own files first
+	if isFontCollection(filename) {
+		return fmt.Errorf("can't embed font collection %q: PDF needs the .ttf or .otf file of one of its fonts", filename)
+	}
 
-func registerFontFile(pdf *gofpdf.Fpdf, fontName, filename string) error {
 	style := ""
 	if strings.Contains(filename, "Italic") || strings.Contains(filename, "Ita") {
 		style += "I"
@@ -243,28 +636,85 @@ func registerFontFile(pdf *gofpdf.Fpdf, fontName, filename string) error {
 		style += "B"
 	}
 
-	if registeredFontsStyle == nil {
-		registeredFontsStyle = map[string]string{}
-	}
-	registeredFontsStyle[fontName] = style
+	pdf.fontStyles[fontName] = style
 
 	pdf.SetFontLocation(filepath.Dir(filename))
 	pdf.AddUTF8Font(fontName, style, filepath.Base(filename))
 	return pdf.Error()
 }
 
+// pdfNoteFont returns the name of the registered font for the note of day
+func pdfNoteFont(day Day) string {
+	if note := day.Note(); note != nil && note.Font != "" {
+		return day.Name()
+	}
+	return FontNotes
+}
+
+// pdfNoteVariant returns the name of the registered font for the runs of
+// the note of day in style
+func pdfNoteVariant(day Day, style textStyle) string {
+	name := pdfNoteFont(day)
+	if style.bold {
+		name += "-bold"
+	}
+	if style.italic {
+		name += "-italic"
+	}
+	return name
+}
+
+// registerPDFNoteVariants registers the bold and italic variants of the font
+// of the note of day used by its runs
+func registerPDFNoteVariants(pdf *pdfDocument, config Config, day Day) error {
+	note := day.Note()
+	if note == nil {
+		return nil
+	}
+
+	for _, run := range note.runs() {
+		style := textStyle{bold: run.style.bold, italic: run.style.italic}
+		variant := pdf.fonts.fontVariant(noteFontName(config, day), style)
+		if style == (textStyle{}) || variant == "" {
+			continue
+		}
+		if err := registerFontFile(pdf, pdfNoteVariant(day, style), variant); err != nil {
+			return fmt.Errorf("failed to register font %q: %w", variant, err)
+		}
+	}
+
+	return nil
+}
+
+// setPDFNoteFont sets the font of the note of day in the bold and italic of
+// style, and returns the part of them the font has no variant for
+func setPDFNoteFont(pdf *pdfDocument, config Config, day Day, style textStyle, size float64) textStyle {
+	style = textStyle{bold: style.bold, italic: style.italic}
+	if style == (textStyle{}) || pdf.fonts.fontVariant(noteFontName(config, day), style) == "" {
+		setFont(pdf, pdfNoteFont(day), size)
+		return style
+	}
+
+	setFont(pdf, pdfNoteVariant(day, style), size)
+	return textStyle{}
+}
+
+// measurePDFText returns a function that measures text with a registered font
+func measurePDFText(pdf *pdfDocument, fontName string, size float64) func(string) float64 {
+	return func(text string) float64 {
+		setFont(pdf, fontName, size)
+		return pdf.GetStringWidth(text)
+	}
+}
+
 // setFont tries to set a font with 3 different styles: Regular, Italic and
 // Bold, it sets the first that doesn't errors out, if all 3 errors
-func setFont(pdf *gofpdf.Fpdf, name string, size float64) error {
+func setFont(pdf *pdfDocument, name string, size float64) error {
 	if pdf.Error() != nil {
 		return pdf.Error()
 	}
 
-	style, ok := registeredFontsStyle[name]
-	if !ok {
-		style = ""
-	}
-	pdf.SetFont(name, style, size)
+	pdf.SetFont(name, pdf.fontStyles[name], size)
 
 	return pdf.Error()
 }
@@ -0,0 +1,218 @@
+package galendar
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// iconRasterSize is the size in pixels of the longest side of icons that have
+// to be rasterized, enough for ~40mm at 300 dpi
+const iconRasterSize = 512
+
+// imageRasterSize is the same for the images of the months, enough for a
+// page at ~170 dpi
+const imageRasterSize = 2048
+
+// drawIcon draws the icon at iconPath inside the square at x, y of the given
+// size, keeping its aspect ratio and centering it as SVG <use> does
+func drawIcon(pdf *pdfDocument, iconPath string, x, y, size float64) error {
+	if isRasterIcon(iconPath) {
+		return drawImageIcon(pdf, iconPath, x, y, size)
+	}
+
+	icon, err := pdf.vectorIcon(iconPath)
+	if err != nil {
+		return err
+	}
+
+	if icon.needsRaster() {
+		return drawRasterizedIcon(pdf, iconPath, icon, x, y, size)
+	}
+
+	drawVectorIcon(pdf, icon, x, y, size)
+	return pdf.Error()
+}
+
+// drawImage draws the image at imagePath (PNG, JPEG or SVG) filling area as
+// fit says, clipped to it
+func drawImage(pdf *pdfDocument, imagePath string, area pageRect, fit ImageFit) error {
+	pdf.ClipRect(area.x, area.y, area.w, area.h, false)
+	defer pdf.ClipEnd()
+
+	if isRasterIcon(imagePath) {
+		w, h, err := registerImageIcon(pdf, imagePath)
+		if err != nil {
+			return err
+		}
+		drawPDFImage(pdf, imagePath, fit.place(w, h, area))
+		return pdf.Error()
+	}
+
+	icon, err := pdf.vectorIcon(imagePath)
+	if err != nil {
+		return err
+	}
+
+	if icon.needsRaster() {
+		name, w, h, err := registerRasterizedIcon(pdf, imagePath, icon, imageRasterSize)
+		if err != nil {
+			return err
+		}
+		drawPDFImage(pdf, name, fit.place(w, h, area))
+		return pdf.Error()
+	}
+
+	drawVectorIconIn(pdf, icon, fit.place(icon.viewBox[2], icon.viewBox[3], area))
+	return pdf.Error()
+}
+
+// vectorIcon returns the icon at iconPath, loaded once by document
+func (pdf *pdfDocument) vectorIcon(iconPath string) (*vectorIcon, error) {
+	if icon, ok := pdf.vectorIcons[iconPath]; ok {
+		return icon, nil
+	}
+
+	icon, err := loadVectorIcon(iconPath)
+	if err != nil {
+		return nil, err
+	}
+	pdf.vectorIcons[iconPath] = icon
+
+	return icon, nil
+}
+
+// drawVectorIcon translates the shapes of the icon into gofpdf path
+// operations
+func drawVectorIcon(pdf *pdfDocument, icon *vectorIcon, x, y, size float64) {
+	drawVectorIconIn(pdf, icon, ImageFitFit.place(icon.viewBox[2], icon.viewBox[3], pageRect{x: x, y: y, w: size, h: size}))
+}
+
+// drawVectorIconIn draws the icon with its viewBox scaled to r, that must
+// have the same aspect ratio
+func drawVectorIconIn(pdf *pdfDocument, icon *vectorIcon, r pageRect) {
+	vbX, vbY, vbW := icon.viewBox[0], icon.viewBox[1], icon.viewBox[2]
+	scale := r.w / vbW
+	toPage := iconMatrix{
+		scale, 0, 0, scale,
+		r.x - vbX*scale,
+		r.y - vbY*scale,
+	}
+
+	lineWidth := pdf.GetLineWidth()
+	defer pdf.SetLineWidth(lineWidth)
+
+	for _, shape := range icon.shapes {
+		style := ""
+		alpha := 1.0
+		if shape.fill != nil {
+			c := shape.fill.color
+			setPDFFillColor(pdf, Color{R: c.R, G: c.G, B: c.B, Valid: true})
+			style += "F"
+			alpha = shape.fillOpacity
+		}
+		if shape.stroke != nil && shape.strokeWidth > 0 {
+			c := shape.stroke.color
+			setPDFDrawColor(pdf, Color{R: c.R, G: c.G, B: c.B, Valid: true})
+			pdf.SetLineWidth(shape.strokeWidth * scale)
+			style += "D"
+			if shape.fill == nil {
+				alpha = shape.strokeOpacity
+			}
+		}
+		if style == "" {
+			continue
+		}
+		if shape.evenOdd && shape.fill != nil {
+			style += "*"
+		}
+
+		// PDF/X-1a has no transparency, translucent shapes are drawn opaque
+		translucent := alpha < 1 && !pdf.print.PDFX
+		if translucent {
+			pdf.SetAlpha(alpha, "Normal")
+		}
+
+		for _, op := range transformOps(shape.ops, toPage) {
+			switch op.op {
+			case 'M':
+				pdf.MoveTo(op.pts[0].x, op.pts[0].y)
+			case 'L':
+				pdf.LineTo(op.pts[0].x, op.pts[0].y)
+			case 'C':
+				pdf.CurveBezierCubicTo(op.pts[0].x, op.pts[0].y, op.pts[1].x, op.pts[1].y, op.pts[2].x, op.pts[2].y)
+			case 'Z':
+				pdf.ClosePath()
+			}
+		}
+		pdf.DrawPath(style)
+
+		if translucent {
+			pdf.SetAlpha(1, "Normal")
+		}
+	}
+}
+
+// drawRasterizedIcon is the fallback for icons that use features that can't
+// be drawn as basic vectors, the icon is rasterized once per document
+func drawRasterizedIcon(pdf *pdfDocument, iconPath string, icon *vectorIcon, x, y, size float64) error {
+	name, w, h, err := registerRasterizedIcon(pdf, iconPath, icon, iconRasterSize)
+	if err != nil {
+		return err
+	}
+
+	drawImageFitted(pdf, name, w, h, x, y, size)
+	return pdf.Error()
+}
+
+// registerRasterizedIcon rasterizes the icon with its longest side of
+// rasterSize pixels, once per document and size, and returns the name of the
+// registered image and its size
+func registerRasterizedIcon(pdf *pdfDocument, iconPath string, icon *vectorIcon, rasterSize int) (string, float64, float64, error) {
+	name := "icon:" + iconPath
+	if rasterSize != iconRasterSize {
+		name = fmt.Sprintf("icon@%d:%s", rasterSize, iconPath)
+	}
+
+	w, h, err := registerPDFImage(pdf, name, "PNG", func() ([]byte, error) {
+		return icon.rasterize(rasterSize)
+	})
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("can't register icon %s: %w", iconPath, err)
+	}
+
+	return name, w, h, nil
+}
+
+// drawImageIcon draws PNG and JPEG icons
+func drawImageIcon(pdf *pdfDocument, iconPath string, x, y, size float64) error {
+	w, h, err := registerImageIcon(pdf, iconPath)
+	if err != nil {
+		return err
+	}
+
+	drawImageFitted(pdf, iconPath, w, h, x, y, size)
+	return pdf.Error()
+}
+
+// registerImageIcon registers a PNG or JPEG icon once per document, by path,
+// and returns its size
+func registerImageIcon(pdf *pdfDocument, iconPath string) (float64, float64, error) {
+	imageType := strings.TrimPrefix(strings.ToUpper(filepath.Ext(iconPath)), ".")
+	w, h, err := registerPDFImage(pdf, iconPath, imageType, func() ([]byte, error) {
+		return readIcon(iconPath)
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't register icon %s: %w", iconPath, err)
+	}
+
+	return w, h, nil
+}
+
+func drawImageFitted(pdf *pdfDocument, name string, w, h, x, y, size float64) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	drawPDFImage(pdf, name, ImageFitFit.place(w, h, pageRect{x: x, y: y, w: size, h: size}))
+}
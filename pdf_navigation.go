@@ -0,0 +1,245 @@
+package galendar
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// pdfNavigation are the internal links of a document: to the first page of
+// every month and to the entry of every special day in the appendix
+type pdfNavigation struct {
+	months map[int]int
+	days   map[string]int // by day name
+}
+
+// Sizes of the pages of the year index and the appendix
+const (
+	appendixSize       = 12.0 // font size of the entries
+	appendixLineHeight = 7.0
+	appendixDayWidth   = 40.0 // of the column of dates
+	indexColumns       = 4
+	indexRows          = 3
+	indexGap           = 8.0 // between mini months
+)
+
+// newPDFNavigation creates the links to the months and special days of
+// months, the pages they point to are set when they are drawn. Documents
+// imposed or tiled have no navigation since their pages are sheets, and
+// PDF/X documents can't have links
+func newPDFNavigation(pdf *pdfDocument, config Config, months []Calendar) {
+	opts := config.Print
+	if opts.imposed() || opts.tiled() || opts.PDFX {
+		return
+	}
+
+	nav := &pdfNavigation{months: map[int]int{}, days: map[string]int{}}
+	for _, cal := range months {
+		nav.months[cal.Month] = pdf.AddLink()
+		if !config.Appendix {
+			continue
+		}
+		for _, day := range specialDaysOf(cal) {
+			nav.days[day.Name()] = pdf.AddLink()
+		}
+	}
+
+	pdf.navigation = nav
+}
+
+// specialDaysOf returns the days of the month of cal listed in the appendix,
+// in order: holidays and days with notes
+func specialDaysOf(cal Calendar) []Day {
+	var days []Day
+	for _, week := range cal.unfolded().Weeks {
+		for _, day := range week {
+			if day.IsCurrentMonth && day.special != nil && (day.special.Holiday || day.special.Note.Text != "") {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+// addPDFBookmark adds an entry to the outline of the document pointing to the
+// top of the current page
+func addPDFBookmark(pdf *pdfDocument, text string, level int) {
+	if pdf.navigation == nil {
+		return
+	}
+
+	// Bookmarks are written in UTF-16 only while a UTF-8 font is set
+	setFont(pdf, FontMonths, appendixSize)
+	pdf.Bookmark(text, level, 0)
+}
+
+// setPDFLinkTarget points link to y of the current page
+func setPDFLinkTarget(pdf *pdfDocument, link int, y float64) {
+	if pdf.navigation == nil {
+		return
+	}
+
+	// Links are placed in the media, outside of the transformation of the trim
+	pdf.SetLink(link, y+pdf.print.slug(), pdf.PageNo())
+}
+
+// addPDFLink makes r a link to link
+func addPDFLink(pdf *pdfDocument, r pageRect, link int) {
+	if pdf.navigation == nil {
+		return
+	}
+
+	slug := pdf.print.slug()
+	pdf.Link(r.x+slug, r.y+slug, r.w, r.h, link)
+}
+
+// addPDFURLLink makes r a link to url
+func addPDFURLLink(pdf *pdfDocument, r pageRect, url string) {
+	if pdf.navigation == nil {
+		return
+	}
+
+	slug := pdf.print.slug()
+	pdf.LinkString(r.x+slug, r.y+slug, r.w, r.h, url)
+}
+
+// linkPDFDay makes r a link to the appendix entry of day, if it has one
+func linkPDFDay(pdf *pdfDocument, day Day, r pageRect) {
+	if nav := pdf.navigation; nav != nil && day.IsCurrentMonth {
+		if link, ok := nav.days[day.Name()]; ok {
+			addPDFLink(pdf, r, link)
+		}
+	}
+}
+
+// markPDFMonthPage makes the current page the target of the links and the
+// bookmark of the month of cal
+func markPDFMonthPage(pdf *pdfDocument, config Config, cal Calendar) {
+	nav := pdf.navigation
+	if nav == nil {
+		return
+	}
+
+	addPDFBookmark(pdf, config.Theme.title(config, cal), 0)
+	setPDFLinkTarget(pdf, nav.months[cal.Month], 0)
+}
+
+// renderYearIndexPage renders a page with the title of the year and a mini
+// month of every month linking to its page
+func renderYearIndexPage(pdf *pdfDocument, config Config, months []Calendar) error {
+	return drawPDFPage(pdf, config, func() error {
+		theme := config.Theme
+		addPDFBookmark(pdf, config.Language.Read("Index"), 0)
+
+		margin := theme.Page.Margin
+		title := strconv.Itoa(config.Year)
+		setFont(pdf, FontMonths, theme.Title.Size)
+		setPDFTextColor(pdf, theme.Title.Color)
+		pdf.Text((pdfPageWidth-pdf.GetStringWidth(title))/2, centeredBaseline(margin, theme.Title.Height, theme.Title.Size), title)
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("can't write title %q: %w", title, err)
+		}
+
+		// Mini months as large as they fit in a cell of the grid of months
+		top := margin + theme.Title.Height
+		cellWidth := (pdfPageWidth - 2*margin) / indexColumns
+		cellHeight := (pdfPageHeight - margin - top) / indexRows
+		scale := min((cellWidth-indexGap)/theme.MiniMonth.Width, (cellHeight-indexGap)/theme.Title.Height)
+		width, height := theme.MiniMonth.Width*scale, theme.Title.Height*scale
+
+		for i, cal := range months {
+			x := margin + float64(i%indexColumns)*cellWidth + (cellWidth-width)/2
+			y := top + float64(i/indexColumns)*cellHeight + (cellHeight-height)/2
+
+			texts, rects := layoutMiniMonth(config, cal, x, y, scale)
+			drawPDFTexts(pdf, texts, rects)
+			if nav := pdf.navigation; nav != nil {
+				addPDFLink(pdf, pageRect{x: x, y: y, w: width, h: height}, nav.months[cal.Month])
+			}
+		}
+
+		return pdf.Error()
+	})
+}
+
+// renderAppendixPages renders the special days of every month of months, a
+// page for each month that has them, the entries link to their months
+func renderAppendixPages(pdf *pdfDocument, config Config, months []Calendar) error {
+	theme := config.Theme
+	margin := theme.Page.Margin
+	first := true
+
+	for _, cal := range months {
+		days := specialDaysOf(cal)
+		if len(days) == 0 {
+			continue
+		}
+
+		// Months with more entries than fit in a page continue in the next ones
+		perPage := max(1, int((pdfPageHeight-2*margin-theme.Title.Height)/appendixLineHeight))
+		for start := 0; start < len(days); start += perPage {
+			page := days[start:min(start+perPage, len(days))]
+			err := drawPDFPage(pdf, config, func() error {
+				if first {
+					addPDFBookmark(pdf, config.Language.Read("Special days"), 0)
+					first = false
+				}
+				if start == 0 {
+					addPDFBookmark(pdf, config.Language.MonthName(cal.Month), 1)
+				}
+				return drawPDFAppendixPage(pdf, config, cal, page)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// drawPDFAppendixPage draws the title of the month of cal and the entries of
+// days below it
+func drawPDFAppendixPage(pdf *pdfDocument, config Config, cal Calendar, days []Day) error {
+	theme := config.Theme
+	margin := theme.Page.Margin
+	nav := pdf.navigation
+
+	title := fmt.Sprintf("%s - %s", config.Language.Read("Special days"), config.Theme.title(config, cal))
+	setFont(pdf, FontMonths, theme.Title.Size)
+	setPDFTextColor(pdf, theme.Title.Color)
+	pdf.Text(margin, centeredBaseline(margin, theme.Title.Height, theme.Title.Size), title)
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("can't write title %q: %w", title, err)
+	}
+
+	top := margin + theme.Title.Height
+	for i, day := range days {
+		y := top + float64(i)*appendixLineHeight
+		if nav != nil {
+			setPDFLinkTarget(pdf, nav.days[day.Name()], y)
+			addPDFLink(pdf, pageRect{x: margin, y: y, w: pdfPageWidth - 2*margin, h: appendixLineHeight}, nav.months[cal.Month])
+		}
+
+		date := fmt.Sprintf("%s %d", config.Language.Read(day.Date.Weekday().String()), day.DayNumber)
+		text := day.Note().plainText()
+		switch {
+		case day.special.Holiday && text == "":
+			text = config.Language.Read("Holiday")
+		case day.special.Holiday:
+			text = fmt.Sprintf("%s (%s)", text, config.Language.Read("holiday"))
+		}
+
+		baseline := centeredBaseline(y, appendixLineHeight, appendixSize)
+		setFont(pdf, FontDays, appendixSize)
+		setPDFTextColor(pdf, theme.dayNumberColor(day))
+		pdf.Text(margin, baseline, date)
+		setFont(pdf, FontNotes, appendixSize)
+		setPDFTextColor(pdf, theme.noteColor(day))
+		pdf.Text(margin+appendixDayWidth, baseline, text)
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("can't write special day %s: %w", day.Name(), err)
+		}
+	}
+
+	return nil
+}
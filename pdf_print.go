@@ -0,0 +1,590 @@
+package galendar
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"encoding/ascii85"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PrintOptions are the print production options of the PDF renderer, all
+// lengths are in millimeters
+type PrintOptions struct {
+	Bleed        float64    // background beyond the trim on every side
+	Marks        bool       // crop and registration marks around the trim
+	SafeZone     float64    // distance to the trim kept free of content, the page margin is never smaller
+	CMYK         bool       // colors and images in CMYK instead of RGB
+	PDFX         bool       // PDF/X-1a:2001 output, implies CMYK and no transparency
+	OutputIntent string     // registered output condition of PDF/X output (e.g. FOGRA39)
+	Preflight    bool       // report the problems of the document for print
+	Imposition   Imposition // arrangement of the pages on sheets for a booklet
+	Creep        float64    // shift toward the fold of the pages of each sheet of a booklet from the one around it
+	Poster       PaperSize  // size the pages are scaled to and split into tiles of TilePaper, none for no tiling
+	TilePaper    PaperSize  // paper of the tiles of posters
+	TileOverlap  float64    // content shared by neighbour tiles
+}
+
+const DefaultOutputIntent = "FOGRA39"
+
+// The trim size of the pages, A4 landscape
+const (
+	pdfPageWidth  = 297.0
+	pdfPageHeight = 210.0
+)
+
+// Crop marks start markOffset away from the trim (or the bleed, if larger)
+// and are markLength long, registration marks are centered in them
+const (
+	markOffset      = 3.0
+	markLength      = 6.0
+	markLineWidth   = 0.1
+	registrationRad = 2.0
+)
+
+// cmyk reports if colors have to be written in CMYK
+func (opts PrintOptions) cmyk() bool {
+	return opts.CMYK || opts.PDFX
+}
+
+// imposed reports if the pages are arranged on sheets for a booklet
+func (opts PrintOptions) imposed() bool {
+	return opts.Imposition != "" && opts.Imposition != ImpositionNone
+}
+
+// tiled reports if the pages are split into tiles of a poster
+func (opts PrintOptions) tiled() bool {
+	return !opts.Poster.IsZero()
+}
+
+// slug returns the space of the media around the trim
+func (opts PrintOptions) slug() float64 {
+	if opts.Marks {
+		return max(opts.Bleed, markOffset) + markLength + 1
+	}
+	return opts.Bleed
+}
+
+// withSafeZone returns config with a page margin of at least the safe zone
+func (opts PrintOptions) withSafeZone(config Config) Config {
+	config.Theme.Page.Margin = max(config.Theme.Page.Margin, opts.SafeZone)
+	return config
+}
+
+// pdfDocument is a document being rendered with the state gofpdf has no
+// room for, so the drawing functions know how to write colors, images and
+// links. Every render creates its own
+type pdfDocument struct {
+	*gofpdf.Fpdf
+
+	print PrintOptions
+
+	// cmykImages are the images registered in CMYK documents by name, they
+	// are written inline when drawn instead of as RGB resources
+	cmykImages map[string]image.Image
+
+	// imposedPages are the pages of imposed and tiled documents, in order,
+	// kept to be drawn on their sheets by imposePDF
+	imposedPages []func() error
+
+	// navigation are the links of the document, nil if it has none, see
+	// newPDFNavigation
+	navigation *pdfNavigation
+
+	fonts       *fontCache
+	fontStyles  map[string]string      // gofpdf style of the registered fonts, by name
+	vectorIcons map[string]*vectorIcon // by path
+}
+
+// newPrintDocument creates a document with the media of opts, the pages are
+// drawn in trim coordinates by drawPDFPage
+func newPrintDocument(opts PrintOptions) *pdfDocument {
+	slug := opts.slug()
+	// Sizes are portrait as the ones of gofpdf, landscape swaps them
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "L",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: pdfPageHeight + 2*slug, Ht: pdfPageWidth + 2*slug},
+	})
+
+	if slug > 0 || opts.PDFX {
+		pdf.SetPageBox("trim", slug, slug, pdfPageWidth, pdfPageHeight)
+	}
+	if opts.Bleed > 0 {
+		pdf.SetPageBox("bleed", slug-opts.Bleed, slug-opts.Bleed, pdfPageWidth+2*opts.Bleed, pdfPageHeight+2*opts.Bleed)
+	}
+
+	return &pdfDocument{
+		Fpdf:        pdf,
+		print:       opts,
+		fonts:       newFontCache(),
+		fontStyles:  map[string]string{},
+		vectorIcons: map[string]*vectorIcon{},
+	}
+}
+
+// drawPDFPage adds a page with the background of the theme, extended into the
+// bleed, and draws its content with draw in trim coordinates. Pages of
+// imposed and tiled documents are queued to be drawn on their sheets by
+// imposePDF
+func drawPDFPage(pdf *pdfDocument, config Config, draw func() error) error {
+	opts := pdf.print
+	slug := opts.slug()
+
+	page := func() error {
+		if background := config.Theme.Page.Background; background.Valid {
+			setPDFFillColor(pdf, background)
+			pdf.Rect(-opts.Bleed, -opts.Bleed, pdfPageWidth+2*opts.Bleed, pdfPageHeight+2*opts.Bleed, "F")
+		}
+		return draw()
+	}
+
+	if opts.imposed() || opts.tiled() {
+		pdf.imposedPages = append(pdf.imposedPages, page)
+		return nil
+	}
+
+	pdf.AddPage()
+	if slug > 0 {
+		pdf.TransformBegin()
+		pdf.TransformTranslate(slug, slug)
+	}
+
+	err := page()
+
+	if slug > 0 {
+		pdf.TransformEnd()
+	}
+	if opts.Marks {
+		drawPDFMarks(pdf, opts)
+	}
+
+	return err
+}
+
+// drawPDFMarks draws crop marks at the corners of the trim and registration
+// marks at the middle of its sides, in the registration color (all the inks)
+func drawPDFMarks(pdf *pdfDocument, opts PrintOptions) {
+	slug := opts.slug()
+	offset := max(opts.Bleed, markOffset)
+	registration := CMYKColor(100, 100, 100, 100)
+	if !opts.cmyk() {
+		registration = Color{Valid: true}
+	}
+
+	setPDFDrawColor(pdf, registration)
+	pdf.SetLineWidth(markLineWidth)
+
+	left, top := slug, slug
+	right, bottom := slug+pdfPageWidth, slug+pdfPageHeight
+	for _, x := range []float64{left, right} {
+		for _, y := range []float64{top, bottom} {
+			dx, dy := offset, offset
+			if x == left {
+				dx = -offset
+			}
+			if y == top {
+				dy = -offset
+			}
+			pdf.Line(x+dx, y, x+dx+sign(dx)*markLength, y)
+			pdf.Line(x, y+dy, x, y+dy+sign(dy)*markLength)
+		}
+	}
+
+	center := offset + markLength/2
+	for _, p := range [][2]float64{
+		{left + pdfPageWidth/2, top - center},
+		{left + pdfPageWidth/2, bottom + center},
+		{left - center, top + pdfPageHeight/2},
+		{right + center, top + pdfPageHeight/2},
+	} {
+		pdf.Circle(p[0], p[1], registrationRad, "D")
+		pdf.Line(p[0]-markLength/2, p[1], p[0]+markLength/2, p[1])
+		pdf.Line(p[0], p[1]-markLength/2, p[0], p[1]+markLength/2)
+	}
+}
+
+func sign(v float64) float64 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// setPDFFillColor sets the color of fills
+func setPDFFillColor(pdf *pdfDocument, c Color) {
+	if pdf.print.cmyk() {
+		// gofpdf only writes RGB colors, fills and text share the nonstroking
+		// color as long as gofpdf's own text and fill colors are never set
+		pdf.RawWriteStr(cmykOperator(c, "k"))
+		return
+	}
+	pdf.SetFillColor(c.RGB())
+}
+
+// setPDFTextColor sets the color of text
+func setPDFTextColor(pdf *pdfDocument, c Color) {
+	if pdf.print.cmyk() {
+		pdf.RawWriteStr(cmykOperator(c, "k"))
+		return
+	}
+	pdf.SetTextColor(c.RGB())
+}
+
+// setPDFDrawColor sets the color of lines
+func setPDFDrawColor(pdf *pdfDocument, c Color) {
+	if pdf.print.cmyk() {
+		pdf.RawWriteStr(cmykOperator(c, "K"))
+		return
+	}
+	pdf.SetDrawColor(c.RGB())
+}
+
+func cmykOperator(c Color, operator string) string {
+	cyan, magenta, yellow, black := c.CMYK()
+	return fmt.Sprintf("%.3f %.3f %.3f %.3f %s", cyan, magenta, yellow, black, operator)
+}
+
+// registerPDFImage registers an image once per document by name and returns
+// its size in pixels. content is only read the first time. Images of CMYK
+// documents are kept decoded to be written inline by drawPDFImage, RGB
+// resources are not allowed in them
+func registerPDFImage(pdf *pdfDocument, name, imageType string, content func() ([]byte, error)) (float64, float64, error) {
+	if !pdf.print.cmyk() {
+		info := pdf.GetImageInfo(name)
+		if info == nil {
+			data, err := content()
+			if err != nil {
+				return 0, 0, err
+			}
+			info = pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+			if err := pdf.Error(); err != nil {
+				return 0, 0, err
+			}
+		}
+		return info.Width(), info.Height(), nil
+	}
+
+	img, ok := pdf.cmykImages[name]
+	if !ok {
+		data, err := content()
+		if err != nil {
+			return 0, 0, err
+		}
+		img, _, err = image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, fmt.Errorf("can't decode image: %w", err)
+		}
+		if pdf.cmykImages == nil {
+			pdf.cmykImages = map[string]image.Image{}
+		}
+		pdf.cmykImages[name] = img
+	}
+
+	bounds := img.Bounds()
+	return float64(bounds.Dx()), float64(bounds.Dy()), nil
+}
+
+// drawPDFImage draws an image registered with registerPDFImage in r
+func drawPDFImage(pdf *pdfDocument, name string, r pageRect) {
+	img, ok := pdf.cmykImages[name]
+	if !ok {
+		pdf.ImageOptions(name, r.x, r.y, r.w, r.h, false, gofpdf.ImageOptions{}, 0, "")
+		return
+	}
+
+	k := pdf.GetConversionRatio()
+	_, pageHeight := pdf.GetPageSize()
+	fmt.Fprintf(pdfRawWriter{pdf}, "q %.2f 0 0 %.2f %.2f %.2f cm", r.w*k, r.h*k, r.x*k, (pageHeight-r.y-r.h)*k)
+	pdf.RawWriteStr(inlineCMYKImage(img))
+	pdf.RawWriteStr("Q")
+}
+
+type pdfRawWriter struct{ pdf *pdfDocument }
+
+func (w pdfRawWriter) Write(p []byte) (int, error) {
+	w.pdf.RawWriteStr(string(p))
+	return len(p), nil
+}
+
+// inlineCMYKImage returns img as an inline image in CMYK, with transparent
+// pixels composed over white since PDF/X-1a has no transparency
+func inlineCMYKImage(img image.Image) string {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy()*4)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			over := func(v uint8) uint8 {
+				return uint8((int(v)*int(c.A) + 255*(255-int(c.A))) / 255)
+			}
+			cyan, magenta, yellow, black := Color{R: over(c.R), G: over(c.G), B: over(c.B), Valid: true}.CMYK()
+			pixels = append(pixels, uint8(cyan*255+0.5), uint8(magenta*255+0.5), uint8(yellow*255+0.5), uint8(black*255+0.5))
+		}
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(pixels)
+	zw.Close()
+
+	encoded := make([]byte, ascii85.MaxEncodedLen(compressed.Len()))
+	encoded = encoded[:ascii85.Encode(encoded, compressed.Bytes())]
+
+	return fmt.Sprintf("BI /W %d /H %d /CS /CMYK /BPC 8 /F [/A85 /Fl] ID\n%s~>\nEI",
+		bounds.Dx(), bounds.Dy(), encoded)
+}
+
+// outputPDF writes the document to filename, with the changes gofpdf can't
+// do: the language of the document, sorted page boxes and PDF/X
+func outputPDF(pdf *pdfDocument, config Config, filename string) error {
+	opts := pdf.print
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return err
+	}
+
+	update := pdfUpdate{catalog: []string{fmt.Sprintf("/Lang (%s)", config.Language)}}
+	if opts.PDFX {
+		update.pdfx = true
+		update.outputIntent = opts.OutputIntent
+	}
+
+	content, err := appendPDFUpdate(sortPageBoxes(buf.Bytes()), update)
+	if err != nil {
+		return fmt.Errorf("can't update document: %w", err)
+	}
+
+	return os.WriteFile(filename, content, 0644)
+}
+
+var pageBoxesPattern = regexp.MustCompile(`(?m)(?:^/(?:Trim|Bleed|Crop|Art)Box \[[^\]]*\]\n){2,}`)
+
+// sortPageBoxes sorts the page boxes of every page, gofpdf writes them in
+// the order of a map. Sorting lines in place keeps every offset of the file
+func sortPageBoxes(content []byte) []byte {
+	return pageBoxesPattern.ReplaceAllFunc(content, func(boxes []byte) []byte {
+		lines := bytes.SplitAfter(boxes, []byte("\n"))
+		sort.Slice(lines, func(i, j int) bool { return bytes.Compare(lines[i], lines[j]) < 0 })
+		return bytes.Join(lines, nil)
+	})
+}
+
+var (
+	trailerRootPattern = regexp.MustCompile(`/Root (\d+) 0 R`)
+	trailerInfoPattern = regexp.MustCompile(`/Info (\d+) 0 R`)
+	startXrefPattern   = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+)
+
+// pdfUpdate are the changes to the catalog of a gofpdf document, and the ones
+// that make it PDF/X-1a:2001: an output intent in the catalog and the PDF/X
+// keys in the info dictionary
+type pdfUpdate struct {
+	catalog      []string // entries added to the catalog
+	pdfx         bool
+	outputIntent string
+}
+
+// appendPDFUpdate appends an incremental update to a gofpdf document with the
+// new catalog and info dictionaries of update and a document id
+func appendPDFUpdate(content []byte, update pdfUpdate) ([]byte, error) {
+	rootMatch := trailerRootPattern.FindAllSubmatch(content, -1)
+	infoMatch := trailerInfoPattern.FindAllSubmatch(content, -1)
+	xrefMatch := startXrefPattern.FindSubmatch(content)
+	if len(rootMatch) == 0 || len(infoMatch) == 0 || xrefMatch == nil {
+		return nil, fmt.Errorf("can't find the trailer")
+	}
+	root, _ := strconv.Atoi(string(rootMatch[len(rootMatch)-1][1]))
+	info, _ := strconv.Atoi(string(infoMatch[len(infoMatch)-1][1]))
+
+	catalogDict, err := pdfObjectDict(content, root)
+	if err != nil {
+		return nil, err
+	}
+	infoDict, err := pdfObjectDict(content, info)
+	if err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("%x", md5.Sum(content))
+	objects := []int{info, root}
+	size := root + 1
+
+	var out bytes.Buffer
+	out.Write(content)
+	offsets := map[int]int{}
+
+	catalog := update.catalog
+	var infoEntries []string
+	intent := 0
+	if update.pdfx {
+		intent = size
+		size++
+		objects = append(objects, intent)
+		catalog = append(catalog, fmt.Sprintf("/OutputIntents [%d 0 R]", intent))
+		infoEntries = append(infoEntries, "/GTS_PDFXVersion (PDF/X-1:2001)", "/GTS_PDFXConformance (PDF/X-1a:2001)", "/Trapped /False")
+	}
+
+	offsets[info] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<<\n%s\n", info, infoDict)
+	for _, entry := range infoEntries {
+		fmt.Fprintf(&out, "%s\n", entry)
+	}
+	fmt.Fprintf(&out, ">>\nendobj\n")
+
+	offsets[root] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<<\n%s\n", root, catalogDict)
+	for _, entry := range catalog {
+		fmt.Fprintf(&out, "%s\n", entry)
+	}
+	fmt.Fprintf(&out, ">>\nendobj\n")
+
+	if update.pdfx {
+		outputIntent := update.outputIntent
+		if outputIntent == "" {
+			outputIntent = DefaultOutputIntent
+		}
+		offsets[intent] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n<< /Type /OutputIntent /S /GTS_PDFX /OutputCondition (%s) /OutputConditionIdentifier (%s) /RegistryName (http://www.color.org) >>\nendobj\n",
+			intent, outputIntent, outputIntent)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 1\n0000000000 65535 f \n")
+	for _, obj := range objects {
+		fmt.Fprintf(&out, "%d 1\n%010d 00000 n \n", obj, offsets[obj])
+	}
+	fmt.Fprintf(&out, "trailer\n<<\n/Size %d\n/Root %d 0 R\n/Info %d 0 R\n/ID [<%s> <%s>]\n/Prev %s\n>>\nstartxref\n%d\n%%%%EOF\n",
+		size, root, info, id, id, xrefMatch[1], xref)
+
+	return out.Bytes(), nil
+}
+
+// pdfObjectDict returns the content of the dictionary of object n, without
+// the << and >>
+func pdfObjectDict(content []byte, n int) ([]byte, error) {
+	start := bytes.Index(content, []byte(fmt.Sprintf("\n%d 0 obj\n<<", n)))
+	if start < 0 {
+		return nil, fmt.Errorf("can't find object %d", n)
+	}
+	start += len(fmt.Sprintf("\n%d 0 obj\n<<", n))
+
+	end := bytes.Index(content[start:], []byte(">>\nendobj"))
+	if end < 0 {
+		return nil, fmt.Errorf("can't find the end of object %d", n)
+	}
+
+	return bytes.TrimSpace(content[start : start+end]), nil
+}
+
+// Embedding bits of the fsType of the OS/2 table of fonts
+const (
+	fsTypeRestricted = 0x0002 // the font must not be embedded
+	fsTypeBitmapOnly = 0x0200 // only bitmaps of the font can be embedded
+)
+
+// preflightPDF reports the print problems of rendering cal with config, when
+// asked to or for PDF/X output. PDF/X requires every font to be embedded so
+// fonts that can't be are an error in that case
+func preflightPDF(config Config, cal Calendar) error {
+	opts := config.Print
+	if !opts.Preflight && !opts.PDFX {
+		return nil
+	}
+
+	var fontProblems []string
+	for _, font := range pdfDocumentFonts(config, cal) {
+		if problem := fontEmbeddingProblem(font); problem != "" {
+			fontProblems = append(fontProblems, problem)
+			log.Printf("preflight: %s", problem)
+		}
+	}
+
+	if margin := config.Theme.Page.Margin; margin < opts.SafeZone {
+		log.Printf("preflight: page margin of %gmm raised to the safe zone of %gmm", margin, opts.SafeZone)
+	}
+	if opts.Bleed > 0 && !config.Theme.Page.Background.Valid {
+		log.Printf("preflight: bleed of %gmm without a page background", opts.Bleed)
+	}
+
+	if opts.PDFX && len(fontProblems) > 0 {
+		return fmt.Errorf("can't make PDF/X: %s", strings.Join(fontProblems, "; "))
+	}
+
+	return nil
+}
+
+// pdfDocumentFonts returns the fonts of config and the ones of the notes of
+// cal, without duplicates
+func pdfDocumentFonts(config Config, cal Calendar) []string {
+	seen := map[string]bool{}
+	var fonts []string
+	add := func(font string) {
+		if font != "" && !seen[font] {
+			seen[font] = true
+			fonts = append(fonts, font)
+		}
+	}
+
+	for _, name := range AllFonts {
+		add(config.Fonts[name])
+	}
+
+	var noteFonts []string
+	for _, day := range cal.SpecialDays {
+		noteFonts = append(noteFonts, day.Note.Font)
+	}
+	sort.Strings(noteFonts)
+	for _, font := range noteFonts {
+		add(font)
+	}
+
+	return fonts
+}
+
+// fontEmbeddingProblem returns why font can't be embedded in a PDF, or an
+// empty string if it can
+func fontEmbeddingProblem(font string) string {
+	filename, err := resolveFontFile(font)
+	if err != nil {
+		return fmt.Sprintf("font %q can't be embedded: %v", font, err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Sprintf("font %q can't be embedded: %v", font, err)
+	}
+
+	tables, err := readFontTables(data)
+	if err != nil {
+		return fmt.Sprintf("font %q can't be embedded: %v", font, err)
+	}
+
+	if _, ok := tables["glyf"]; !ok {
+		return fmt.Sprintf("font %q can't be embedded: only TrueType outlines are supported", font)
+	}
+
+	if os2 := tables["OS/2"]; len(os2) >= 10 {
+		fsType := binary.BigEndian.Uint16(os2[8:])
+		if fsType&fsTypeRestricted != 0 {
+			return fmt.Sprintf("font %q can't be embedded: its license restricts embedding", font)
+		}
+		if fsType&fsTypeBitmapOnly != 0 {
+			return fmt.Sprintf("font %q can't be embedded: its license only allows embedding bitmaps", font)
+		}
+	}
+
+	return ""
+}
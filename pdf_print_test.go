@@ -0,0 +1,287 @@
+package galendar_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unkiwii/galendar"
+)
+
+func renderPrintMonth(t *testing.T, opts galendar.PrintOptions) []byte {
+	t.Helper()
+
+	pngIcon := filepath.Join(t.TempDir(), "dot.png")
+	createTestPNG(t, pngIcon)
+
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "25/12"
+holiday = true
+text = "Navidad"
+icon = "`+pngIcon+`"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2025, 12
+	cfg.Print = opts
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Expected output file: %v", err)
+	}
+	return content
+}
+
+// pdfStreams returns the content of the compressed streams of a PDF file
+func pdfStreams(t *testing.T, content []byte) []byte {
+	t.Helper()
+
+	var streams bytes.Buffer
+	for _, part := range bytes.Split(content, []byte("/FlateDecode"))[1:] {
+		start := bytes.Index(part, []byte("stream\n"))
+		if start < 0 {
+			continue
+		}
+		r, err := zlib.NewReader(bytes.NewReader(part[start+len("stream\n"):]))
+		if err != nil {
+			t.Fatalf("Can't read stream: %v", err)
+		}
+		if _, err := io.Copy(&streams, r); err != nil && err != io.ErrUnexpectedEOF {
+			t.Fatalf("Can't read stream: %v", err)
+		}
+	}
+
+	return streams.Bytes()
+}
+
+func TestPDFRenderer_BleedAndMarks(t *testing.T) {
+	content := renderPrintMonth(t, galendar.PrintOptions{Bleed: 3, Marks: true})
+
+	// Marks need 3mm from the trim, 6mm long marks and 1mm of paper: 10mm
+	for _, want := range []string{
+		"/MediaBox [0 0 898.58 651.97]",
+		"/BleedBox [19.84 19.84 878.74 632.13]\n/TrimBox [28.35 28.35 870.24 623.62]\n",
+	} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("Expected %q in output", want)
+		}
+	}
+}
+
+func TestPDFRenderer_CMYK(t *testing.T) {
+	content := pdfStreams(t, renderPrintMonth(t, galendar.PrintOptions{CMYK: true}))
+
+	if bytes.Contains(content, []byte(" rg\n")) || bytes.Contains(content, []byte(" RG\n")) {
+		t.Errorf("Expected no RGB colors in CMYK output")
+	}
+	if !bytes.Contains(content, []byte(" k\n")) {
+		t.Errorf("Expected CMYK fill colors in output")
+	}
+	if !bytes.Contains(content, []byte("/CS /CMYK")) {
+		t.Errorf("Expected CMYK images in output")
+	}
+}
+
+func TestPDFRenderer_ParallelRenders(t *testing.T) {
+	// Documents rendered at once must keep their own print options
+	for i := range 8 {
+		cmyk := i%2 == 0
+		t.Run(fmt.Sprintf("cmyk=%t/%d", cmyk, i), func(t *testing.T) {
+			t.Parallel()
+
+			content := pdfStreams(t, renderPrintMonth(t, galendar.PrintOptions{CMYK: cmyk}))
+			if got := bytes.Contains(content, []byte("/CS /CMYK")); got != cmyk {
+				t.Errorf("Expected CMYK images %t, got %t", cmyk, got)
+			}
+			if got := bytes.Contains(content, []byte(" k\n")); got != cmyk {
+				t.Errorf("Expected CMYK colors %t, got %t", cmyk, got)
+			}
+		})
+	}
+}
+
+func TestPDFRenderer_PDFX(t *testing.T) {
+	content := renderPrintMonth(t, galendar.PrintOptions{PDFX: true, OutputIntent: "FOGRA39"})
+
+	if !bytes.HasPrefix(content, []byte("%PDF-1.3")) {
+		t.Errorf("Expected a PDF 1.3 file, got %q", content[:8])
+	}
+	for _, want := range []string{
+		"/GTS_PDFXVersion (PDF/X-1:2001)",
+		"/OutputIntents [",
+		"/OutputConditionIdentifier (FOGRA39)",
+		"/TrimBox [",
+		"/ID [<",
+	} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("Expected %q in output", want)
+		}
+	}
+	if bytes.Contains(content, []byte("/SMask")) || bytes.Contains(content, []byte("/ca ")) {
+		t.Errorf("Expected no transparency in PDF/X output")
+	}
+
+	again := renderPrintMonth(t, galendar.PrintOptions{PDFX: true, OutputIntent: "FOGRA39"})
+	if !bytes.Equal(content, again) {
+		t.Errorf("Expected the same PDF/X output for the same inputs")
+	}
+}
+
+func TestPDFRenderer_PDFXRejectsMissingFonts(t *testing.T) {
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2025, 12
+	cfg.Fonts[galendar.FontNotes] = filepath.Join(t.TempDir(), "missing.ttf")
+	cfg.Print = galendar.PrintOptions{PDFX: true}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err == nil {
+		t.Errorf("Expected an error for a font that can't be embedded")
+	}
+}
+
+func TestPDFRenderer_Imposition(t *testing.T) {
+	months := []string{"Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio",
+		"Julio", "Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre"}
+
+	for _, tt := range []struct {
+		imposition galendar.Imposition
+		sides      int
+		order      []int
+		portrait   int
+		scale      string
+	}{
+		// 12 pages on 3 portrait sheets, stacked at about 1/√2 and folded across
+		{galendar.ImpositionTwoUp, 6, []int{12, 1, 2, 11, 10, 3, 4, 9, 8, 5, 6, 7},
+			6, "0.70707 0.00000 0.00000 0.70707"},
+		// the same 3 sheets, 2 per landscape A4 sheet at 1/2: the first and the
+		// third, then the second
+		{galendar.ImpositionFourUp, 4, []int{12, 1, 8, 5, 2, 11, 6, 7, 10, 3, 4, 9},
+			0, "0.50000 0.00000 0.00000 0.50000"},
+	} {
+		t.Run(string(tt.imposition), func(t *testing.T) {
+			cfg := testConfig(t, galendar.PDFRenderer{})
+			cfg.Year = 2026
+			cfg.Print = galendar.PrintOptions{Imposition: tt.imposition, Creep: 0.2}
+
+			cal, err := galendar.NewCalendar(cfg.Year, 1, cfg.WeekStart, nil)
+			if err != nil {
+				t.Fatalf("NewCalendar failed: %v", err)
+			}
+			if err := cfg.Renderer.RenderYear(cfg, cal); err != nil {
+				t.Fatalf("RenderYear failed: %v", err)
+			}
+
+			content, err := os.ReadFile(cfg.YearOutputFilePath())
+			if err != nil {
+				t.Fatalf("Expected output file: %v", err)
+			}
+			if got := bytes.Count(content, []byte("/Type /Page\n")); got != tt.sides {
+				t.Errorf("Expected %d sheet sides, got %d", tt.sides, got)
+			}
+			if got := bytes.Count(content, []byte("/MediaBox [0 0 595.28 841.89]")); got != tt.portrait {
+				t.Errorf("Expected %d portrait sheet sides, got %d", tt.portrait, got)
+			}
+
+			// Titles are written as UTF-16, in the order of the pages on the sheets
+			streams := bytes.ReplaceAll(pdfStreams(t, content), []byte{0}, nil)
+			if !bytes.Contains(streams, []byte(tt.scale+" 0.00000 ")) {
+				t.Errorf("Expected the pages scaled by %s", tt.scale)
+			}
+			last := -1
+			for _, month := range tt.order {
+				title := []byte(months[month-1] + " 2026")
+				at := bytes.Index(streams, title)
+				if at < 0 {
+					t.Fatalf("Expected %s in output", title)
+				}
+				if at < last {
+					t.Errorf("Expected %s after the previous page", title)
+				}
+				last = at
+			}
+		})
+	}
+}
+
+func TestPDFRenderer_PosterTiles(t *testing.T) {
+	poster, err := galendar.ParsePaperSize("A0")
+	if err != nil {
+		t.Fatalf("ParsePaperSize failed: %v", err)
+	}
+	tilePaper, err := galendar.ParsePaperSize("a4")
+	if err != nil {
+		t.Fatalf("ParsePaperSize failed: %v", err)
+	}
+
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2026, 3
+	cfg.Print = galendar.PrintOptions{Poster: poster, TilePaper: tilePaper, TileOverlap: 10}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Expected output file: %v", err)
+	}
+
+	// 1189mm by 841mm on 277mm by 190mm of landscape A4 sheets overlapping by
+	// 10mm: 5 columns and 5 rows
+	if got := bytes.Count(content, []byte("/Type /Page\n")); got != 25 {
+		t.Errorf("Expected 25 tiles, got %d", got)
+	}
+	if !bytes.Contains(content, []byte("/MediaBox [0 0 841.89 595.28]")) {
+		t.Errorf("Expected landscape A4 tiles")
+	}
+
+	streams := bytes.ReplaceAll(pdfStreams(t, content), []byte{0}, nil)
+	for _, label := range []string{"Fila 1, Columna 1", "Fila 5, Columna 5"} {
+		if !bytes.Contains(streams, []byte(label)) {
+			t.Errorf("Expected tile label %q", label)
+		}
+	}
+}
+
+func TestParsePaperSize(t *testing.T) {
+	size, err := galendar.ParsePaperSize("600x400")
+	if err != nil {
+		t.Fatalf("ParsePaperSize failed: %v", err)
+	}
+	if size.Width != 400 || size.Height != 600 {
+		t.Errorf("Expected a portrait 400x600 paper, got %vx%v", size.Width, size.Height)
+	}
+
+	if _, err := galendar.ParsePaperSize("b5"); err == nil {
+		t.Errorf("Expected an error for an unknown paper size")
+	}
+}
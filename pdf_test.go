@@ -0,0 +1,427 @@
+package galendar_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestPDFRenderer_RenderMonthWithIcons(t *testing.T) {
+	pngIcon := filepath.Join(t.TempDir(), "dot.png")
+	createTestPNG(t, pngIcon)
+
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "24/12"
+text = "Nochebuena"
+icon = "builtin:christmas_eve"
+
+[[day]]
+when = "25/12"
+holiday = true
+text = "Navidad"
+icon = "builtin:christmas_tree"
+
+[[day]]
+when = "26/12"
+icon = "builtin:father_day"
+
+[[day]]
+when = "27/12"
+icon = "`+pngIcon+`"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2025, 12
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Expected output file: %v", err)
+	}
+	streams := pdfStreams(t, content)
+
+	// The PNG icon is an image drawn with Do
+	if !bytes.Contains(content, []byte("/Subtype /Image")) {
+		t.Errorf("Expected the PNG icon as an image in output")
+	}
+	if !bytes.Contains(streams, []byte(" Do Q")) {
+		t.Errorf("Expected the PNG icon drawn in output")
+	}
+
+	// SVG icons are drawn as paths, nothing else in the month has curves
+	if got := bytes.Count(streams, []byte(" c\n")); got == 0 {
+		t.Errorf("Expected the curves of the SVG icons in output")
+	}
+}
+
+// testConfig returns a configuration that renders with the Go fonts into a
+// temporary directory, so tests don't depend on the fonts of the system
+func testConfig(t *testing.T, renderer galendar.Renderer) galendar.Config {
+	t.Helper()
+
+	fontFile := filepath.Join(t.TempDir(), "Go-Regular.ttf")
+	if err := os.WriteFile(fontFile, goregular.TTF, 0644); err != nil {
+		t.Fatalf("Failed to write font file: %v", err)
+	}
+
+	fonts := map[string]string{}
+	for _, font := range galendar.AllFonts {
+		fonts[font] = fontFile
+	}
+
+	theme, err := galendar.LoadTheme(galendar.DefaultThemeName)
+	if err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+
+	return galendar.Config{
+		Year:      2024,
+		Month:     1,
+		WeekStart: galendar.DefaultWeekStart,
+		Renderer:  renderer,
+		OutputDir: t.TempDir(),
+		Language:  galendar.Spanish,
+		Fonts:     fonts,
+		Theme:     theme,
+	}
+}
+
+func createTestPNG(t *testing.T, filename string) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for x := range 8 {
+		for y := range 4 {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create png file: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		t.Fatalf("Failed to encode png file: %v", err)
+	}
+}
+
+func TestPDFRenderer_Navigation(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "1/1"
+holiday = true
+text = "Año nuevo"
+
+[[day]]
+when = "14/2"
+text = "San Valentín"
+
+[[day]]
+when = "15/2"
+icon = "builtin:father_day"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year = 2026
+	cfg.YearIndex = true
+	cfg.Appendix = true
+	cfg.Author = "Ana"
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, 1, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderYear(cfg, cal); err != nil {
+		t.Fatalf("RenderYear failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.YearOutputFilePath())
+	if err != nil {
+		t.Fatalf("Expected output file: %v", err)
+	}
+
+	// The index, 12 months and the appendix pages of January and February,
+	// the day with an icon only isn't listed
+	if got := bytes.Count(content, []byte("/Type /Page\n")); got != 15 {
+		t.Errorf("Expected 15 pages, got %d", got)
+	}
+
+	// 12 bookmarks of months, the index, the appendix and its 2 months
+	if got := bytes.Count(content, []byte("/Parent ")) - bytes.Count(content, []byte("/Parent 1 0 R")); got != 16 {
+		t.Errorf("Expected 16 bookmarks, got %d", got)
+	}
+
+	// 12 links of the index, 2 of the days and 2 of the appendix entries
+	if got := bytes.Count(content, []byte("/Subtype /Link")); got != 16 {
+		t.Errorf("Expected 16 links, got %d", got)
+	}
+
+	for _, want := range []string{"/Lang (es)", "/PageMode /UseOutlines", "/Author ("} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("Expected %q in output", want)
+		}
+	}
+}
+
+func TestPDFRenderer_NoteLinks(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "12/3"
+text = "Reunión"
+url = "https://meet.example.com/abc"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2025, 3
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Expected output file: %v", err)
+	}
+	if !bytes.Contains(content, []byte("/URI (https://meet.example.com/abc)")) {
+		t.Errorf("Expected a link to the url of the note")
+	}
+}
+
+func TestPDFRenderer_RichTextNotes(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "1/5"
+holiday = true
+text = "**¡Feriado!** Día del {color:red}trabajador{/color}"
+`)
+	defer os.Remove(tmpFile)
+
+	render := func(withBold bool) []byte {
+		cfg := testConfig(t, galendar.PDFRenderer{})
+		cfg.Year, cfg.Month = 2025, 5
+		if withBold {
+			boldFile := filepath.Join(filepath.Dir(cfg.Fonts[galendar.FontNotes]), "Go-Bold.ttf")
+			if err := os.WriteFile(boldFile, gobold.TTF, 0644); err != nil {
+				t.Fatalf("Failed to write font file: %v", err)
+			}
+		}
+
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+		if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Expected output file: %v", err)
+		}
+		return content
+	}
+
+	// Without a bold font the bold run is drawn stroked
+	content := render(false)
+	fonts := bytes.Count(content, []byte("/FontFile2"))
+	if !bytes.Contains(pdfStreams(t, content), []byte("2 Tr")) {
+		t.Errorf("Expected the bold run to be faked")
+	}
+
+	// The red run is drawn in its own color
+	if !bytes.Contains(pdfStreams(t, content), []byte("0.827 0.184 0.184 rg")) {
+		t.Errorf("Expected the red run in the output")
+	}
+
+	// With a bold font beside the font of the notes, it's embedded and used
+	content = render(true)
+	if bytes.Contains(pdfStreams(t, content), []byte("2 Tr")) {
+		t.Errorf("Expected the bold font instead of a faked bold")
+	}
+	if got := bytes.Count(content, []byte("/FontFile2")); got != fonts+1 {
+		t.Errorf("Expected %d embedded fonts, got %d", fonts+1, got)
+	}
+}
+
+func TestPDFRenderer_QRCodes(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "12/3"
+qr = "https://meet.example.com/abc"
+`)
+	defer os.Remove(tmpFile)
+
+	render := func(footer string) []byte {
+		cfg := testConfig(t, galendar.PDFRenderer{})
+		cfg.Year, cfg.Month = 2025, 3
+		cfg.FooterQR = footer
+
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+		if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Expected output file: %v", err)
+		}
+		return pdfStreams(t, content)
+	}
+
+	// Modules are filled rectangles, the footer adds a whole QR code of them
+	withoutFooter := bytes.Count(render(""), []byte(" re f"))
+	withFooter := bytes.Count(render("https://example.com/calendario"), []byte(" re f"))
+	if withoutFooter < 50 || withFooter < 2*withoutFooter-10 {
+		t.Errorf("Expected the modules of a QR code for the day and one for the footer, got %d and %d rectangles", withoutFooter, withFooter)
+	}
+}
+
+func TestPDFRenderer_Moon(t *testing.T) {
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2025, 1
+	cfg.Moon = galendar.MoonDisplayPhases
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Expected output file: %v", err)
+	}
+	streams := pdfStreams(t, content)
+
+	// The outlines of the four principal phases, in the color of the theme
+	if count := bytes.Count(streams, []byte("0.251 G")); count != 4 {
+		t.Errorf("Expected 4 moon outlines, got %d", count)
+	}
+}
+
+func TestPDFRenderer_RejectsFontCollections(t *testing.T) {
+	cfg := testConfig(t, galendar.PDFRenderer{})
+
+	// Only the extension tells collections apart before parsing them
+	collection := filepath.Join(t.TempDir(), "Go.ttc")
+	if err := os.WriteFile(collection, goregular.TTF, 0644); err != nil {
+		t.Fatalf("Failed to write font file: %v", err)
+	}
+	cfg.Fonts[galendar.FontDays] = collection
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	err = cfg.Renderer.RenderMonth(cfg, cal)
+	if err == nil || !strings.Contains(err.Error(), "can't embed font collection") {
+		t.Errorf("Expected an error about the font collection, got %v", err)
+	}
+}
+
+func TestPDFRenderer_ReloadsIcons(t *testing.T) {
+	icon := filepath.Join(t.TempDir(), "dot.svg")
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "27/12"
+icon = "`+icon+`"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.PDFRenderer{})
+	cfg.Year, cfg.Month = 2025, 12
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	// Icons edited between renders are drawn as they are in each render
+	for _, fill := range []string{"#ff0000", "#0000ff"} {
+		svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><circle cx="5" cy="5" r="5" fill="` + fill + `"/></svg>`
+		if err := os.WriteFile(icon, []byte(svg), 0644); err != nil {
+			t.Fatalf("Failed to write icon: %v", err)
+		}
+		if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Expected output file: %v", err)
+		}
+
+		want := map[string]string{"#ff0000": "1.000 0.000 0.000 rg", "#0000ff": "0.000 0.000 1.000 rg"}[fill]
+		if !bytes.Contains(pdfStreams(t, content), []byte(want)) {
+			t.Errorf("Expected the icon filled with %s in output", fill)
+		}
+	}
+}
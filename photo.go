@@ -0,0 +1,114 @@
+package galendar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PageLayout is how the pages of a month are laid out
+type PageLayout string
+
+const (
+	// LayoutGrid is a page with the grid of the month only
+	LayoutGrid PageLayout = "grid"
+	// LayoutSpread is a two page spread: the image of the month on the first
+	// page and the grid on the second one
+	LayoutSpread PageLayout = "spread"
+	// LayoutSplit is a single page with the image of the month above the grid
+	LayoutSplit PageLayout = "split"
+)
+
+// ParsePageLayout parses a page layout, an empty string means grid
+func ParsePageLayout(s string) (PageLayout, error) {
+	switch layout := PageLayout(strings.ToLower(strings.TrimSpace(s))); layout {
+	case "":
+		return LayoutGrid, nil
+	case LayoutGrid, LayoutSpread, LayoutSplit:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("invalid layout: %q (must be grid, spread or split)", s)
+	}
+}
+
+// ImageFit is how images fill their area when their aspect ratios differ
+type ImageFit string
+
+const (
+	// ImageFitCrop scales the image to cover the whole area and crops what
+	// doesn't fit
+	ImageFitCrop ImageFit = "crop"
+	// ImageFitFit scales the image to fit whole in the area, centered
+	ImageFitFit ImageFit = "fit"
+)
+
+// ParseImageFit parses an image fit mode, an empty string means crop
+func ParseImageFit(s string) (ImageFit, error) {
+	switch fit := ImageFit(strings.ToLower(strings.TrimSpace(s))); fit {
+	case "":
+		return ImageFitCrop, nil
+	case ImageFitCrop, ImageFitFit:
+		return fit, nil
+	default:
+		return "", fmt.Errorf("invalid image fit: %q (must be crop or fit)", s)
+	}
+}
+
+// place returns the rectangle where an image of width w and height h is drawn
+// to fill the area. With ImageFitCrop the rectangle can be larger than the
+// area and the image has to be clipped to it
+func (fit ImageFit) place(w, h float64, area pageRect) pageRect {
+	if w <= 0 || h <= 0 {
+		return area
+	}
+
+	scale := min(area.w/w, area.h/h)
+	if fit == ImageFitCrop {
+		scale = max(area.w/w, area.h/h)
+	}
+	w, h = w*scale, h*scale
+
+	return pageRect{x: area.x + (area.w-w)/2, y: area.y + (area.h-h)/2, w: w, h: h}
+}
+
+// svgPreserveAspectRatio returns the SVG preserveAspectRatio that places
+// images as place does
+func (fit ImageFit) svgPreserveAspectRatio() string {
+	if fit == ImageFitCrop {
+		return "xMidYMid slice"
+	}
+	return "xMidYMid meet"
+}
+
+// photoArea returns the area of the image of a month on a page of pageWidth
+// and pageHeight: the whole content of the page with LayoutSpread, the top of
+// it with LayoutSplit. The second value is where the grid starts
+func photoArea(config Config, pageWidth, pageHeight float64) (area pageRect, gridTop float64) {
+	theme := config.Theme
+	margin := theme.Page.Margin
+	area = pageRect{x: margin, y: margin, w: pageWidth - 2*margin, h: pageHeight - 2*margin}
+
+	switch config.Layout {
+	case LayoutSplit:
+		area.h *= theme.Photo.Split
+		return area, area.y + area.h + theme.Photo.Gap
+	case LayoutSpread:
+		return area, margin
+	default:
+		return pageRect{}, margin
+	}
+}
+
+// coverLayout returns the area of the cover image and the title below it
+func coverLayout(config Config, pageWidth, pageHeight float64) (pageRect, pageText) {
+	theme := config.Theme
+	margin := theme.Page.Margin
+	area := pageRect{x: margin, y: margin, w: pageWidth - 2*margin, h: pageHeight - 2*margin - theme.Cover.Height}
+
+	title := pageText{
+		x: pageWidth / 2, y: centeredBaseline(area.y+area.h, theme.Cover.Height, theme.Cover.Size),
+		font: FontMonths, size: theme.Cover.Size, color: theme.Cover.Color,
+		text: strings.ReplaceAll(theme.Cover.Format, "{year}", fmt.Sprint(config.Year)),
+	}
+
+	return area, title
+}
@@ -0,0 +1,117 @@
+package galendar_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestPDFRenderer_PhotoLayouts(t *testing.T) {
+	imageFile := filepath.Join(t.TempDir(), "photo.png")
+	createTestPNG(t, imageFile)
+
+	for _, tc := range []struct {
+		layout galendar.PageLayout
+		pages  int
+	}{
+		{galendar.LayoutGrid, 1 + 12},
+		{galendar.LayoutSplit, 1 + 12},
+		{galendar.LayoutSpread, 1 + 24},
+	} {
+		t.Run(string(tc.layout), func(t *testing.T) {
+			cfg := testConfig(t, galendar.PDFRenderer{})
+			cfg.Year = 2025
+			cfg.Layout = tc.layout
+			cfg.Images = map[int]string{1: imageFile, 6: imageFile}
+			cfg.CoverImage = imageFile
+
+			cal, err := galendar.NewCalendar(cfg.Year, 1, cfg.WeekStart, nil)
+			if err != nil {
+				t.Fatalf("NewCalendar failed: %v", err)
+			}
+
+			if err := cfg.Renderer.RenderYear(cfg, cal); err != nil {
+				t.Fatalf("RenderYear failed: %v", err)
+			}
+
+			content, err := os.ReadFile(cfg.YearOutputFilePath())
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+
+			if pages := strings.Count(string(content), "/Type /Page\n"); pages != tc.pages {
+				t.Errorf("Expected %d pages, got %d", tc.pages, pages)
+			}
+		})
+	}
+}
+
+func TestSVGRenderer_PhotoLayouts(t *testing.T) {
+	dir := t.TempDir()
+	rasterFile := filepath.Join(dir, "photo.png")
+	createTestPNG(t, rasterFile)
+	vectorFile := filepath.Join(dir, "art.svg")
+	err := os.WriteFile(vectorFile, []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 40 10"><rect width="40" height="10" fill="#00f"/></svg>`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write image file: %v", err)
+	}
+
+	render := func(layout galendar.PageLayout, fit galendar.ImageFit, month int) string {
+		cfg := testConfig(t, galendar.SVGRenderer{})
+		cfg.Year, cfg.Month = 2025, month
+		cfg.Layout = layout
+		cfg.ImageFit = fit
+		cfg.Images = map[int]string{1: rasterFile, 2: vectorFile}
+
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+
+		if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return string(content)
+	}
+
+	svg := render(galendar.LayoutSplit, galendar.ImageFitCrop, 1)
+	if !strings.Contains(svg, `preserveAspectRatio="xMidYMid slice" xlink:href="data:image/png;base64,`) {
+		t.Errorf("Expected the cropped image of January above the grid")
+	}
+	if !strings.Contains(svg, `viewBox="0 0 1122 794"`) {
+		t.Errorf("Expected a single page")
+	}
+
+	svg = render(galendar.LayoutSpread, galendar.ImageFitFit, 2)
+	if !strings.Contains(svg, `viewBox="0 0 40 10" preserveAspectRatio="xMidYMid meet"><rect`) {
+		t.Errorf("Expected the fitted artwork of February")
+	}
+	if !strings.Contains(svg, `viewBox="0 0 1122 1588"`) || !strings.Contains(svg, `<g transform="translate(0 794)">`) {
+		t.Errorf("Expected the image and grid pages one above the other")
+	}
+
+	svg = render(galendar.LayoutSpread, galendar.ImageFitCrop, 3)
+	if strings.Contains(svg, "<image") || strings.Contains(svg, "preserveAspectRatio") {
+		t.Errorf("Expected an empty image page for a month without an image")
+	}
+}
+
+func TestParsePageLayout(t *testing.T) {
+	if layout, err := galendar.ParsePageLayout(""); err != nil || layout != galendar.LayoutGrid {
+		t.Errorf("Expected grid by default, got %q (%v)", layout, err)
+	}
+	if _, err := galendar.ParsePageLayout("poster"); err == nil {
+		t.Errorf("Expected an error for an unknown layout")
+	}
+	if _, err := galendar.ParseImageFit("stretch"); err == nil {
+		t.Errorf("Expected an error for an unknown image fit")
+	}
+}
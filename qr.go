@@ -0,0 +1,575 @@
+package galendar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QRLevel is the error correction level of QR codes, higher levels make
+// larger codes that can still be read with more of them damaged
+type QRLevel string
+
+const (
+	// QRLevelLow recovers 7% of the code
+	QRLevelLow QRLevel = "L"
+	// QRLevelMedium recovers 15% of the code
+	QRLevelMedium QRLevel = "M"
+	// QRLevelQuartile recovers 25% of the code
+	QRLevelQuartile QRLevel = "Q"
+	// QRLevelHigh recovers 30% of the code
+	QRLevelHigh QRLevel = "H"
+)
+
+const (
+	DefaultQRLevel = QRLevelMedium
+	DefaultQRSize  = 15.0
+)
+
+// ParseQRLevel parses an error correction level, an empty string means
+// medium
+func ParseQRLevel(s string) (QRLevel, error) {
+	switch level := QRLevel(strings.ToUpper(strings.TrimSpace(s))); level {
+	case "":
+		return DefaultQRLevel, nil
+	case QRLevelLow, QRLevelMedium, QRLevelQuartile, QRLevelHigh:
+		return level, nil
+	default:
+		return "", fmt.Errorf("invalid qr level: %q (must be L, M, Q or H)", s)
+	}
+}
+
+// index returns the row of level in the tables of the QR code standard
+func (level QRLevel) index() int {
+	switch level {
+	case QRLevelLow:
+		return 0
+	case QRLevelQuartile:
+		return 2
+	case QRLevelHigh:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// formatBits returns the bits of level in the format information
+func (level QRLevel) formatBits() int {
+	return [...]int{1, 0, 3, 2}[level.index()]
+}
+
+// Error correction codewords per block and number of blocks, by level and
+// version (version 0 doesn't exist)
+var (
+	qrECCPerBlock = [4][41]int{
+		{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	qrBlocks = [4][41]int{
+		{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+)
+
+// QRCode is a QR code, a square of dark and light modules
+type QRCode struct {
+	size     int
+	modules  []bool // dark modules, row by row
+	function []bool // modules of the patterns, that are not data
+}
+
+// EncodeQR encodes text in bytes mode in the smallest QR code that holds it
+// at level
+func EncodeQR(text string, level QRLevel) (*QRCode, error) {
+	data := []byte(text)
+
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+qrCountBits(v)+8*len(data) <= qrDataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("text of %d bytes is too long for a QR code at level %s", len(data), level)
+	}
+
+	// Mode, count, data, terminator and padding
+	var bits qrBits
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := qrDataCodewords(version, level) * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	qr := &QRCode{size: 4*version + 17}
+	qr.modules = make([]bool, qr.size*qr.size)
+	qr.function = make([]bool, qr.size*qr.size)
+	qr.drawFunctionPatterns(version, level)
+	qr.drawCodewords(qrAddECC(codewords, version, level))
+
+	// The mask with the lowest penalty makes the code easier to read
+	best, bestPenalty := 0, -1
+	for mask := range 8 {
+		qr.applyMask(mask)
+		qr.drawFormatBits(level, mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask) // masks undo themselves
+	}
+	qr.applyMask(best)
+	qr.drawFormatBits(level, best)
+	qr.function = nil
+
+	return qr, nil
+}
+
+// Size returns the number of modules of a side of the code, without the
+// quiet zone around it
+func (qr *QRCode) Size() int {
+	return qr.size
+}
+
+// Dark reports if the module at column x and row y is dark, modules out of
+// the code are light
+func (qr *QRCode) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= qr.size || y >= qr.size {
+		return false
+	}
+	return qr.modules[y*qr.size+x]
+}
+
+// qrQuietZone is the light margin needed around QR codes, in modules
+const qrQuietZone = 4
+
+// rects returns the dark modules of qr as rectangles of the runs of dark
+// modules of its rows, drawn in a square of size at x, y with the quiet zone
+func (qr *QRCode) rects(x, y, size float64) []pageRect {
+	module := size / float64(qr.size+2*qrQuietZone)
+	x, y = x+qrQuietZone*module, y+qrQuietZone*module
+
+	var rects []pageRect
+	for row := range qr.size {
+		for column := 0; column < qr.size; column++ {
+			if !qr.Dark(column, row) {
+				continue
+			}
+			start := column
+			for qr.Dark(column+1, row) {
+				column++
+			}
+			rects = append(rects, pageRect{
+				x: x + float64(start)*module, y: y + float64(row)*module,
+				w: float64(column-start+1) * module, h: module,
+			})
+		}
+	}
+
+	return rects
+}
+
+// Colors of the modules, QR codes need a light background to be read
+var (
+	qrDark  = Color{Valid: true}
+	qrLight = Color{R: 255, G: 255, B: 255, Valid: true}
+)
+
+// qrSize returns the size of the QR codes of config
+func (config Config) qrSize() float64 {
+	if config.QRSize > 0 {
+		return config.QRSize
+	}
+	return DefaultQRSize
+}
+
+// footerHeight returns the height taken by the footer below the grid of
+// month pages
+func (config Config) footerHeight() float64 {
+	if config.FooterQR == "" {
+		return 0
+	}
+	return config.qrSize()
+}
+
+// cellQRSize returns the size of the QR codes of days in cells of cellWidth
+// and rowHeight, they go in the bottom right corner below the day box and
+// above the day label
+func (config Config) cellQRSize(cellWidth, rowHeight, dayBoxHeight float64) float64 {
+	padding := config.Theme.Notes.Padding
+	return max(0, min(config.qrSize(), cellWidth/2, rowHeight-dayBoxHeight-padding-config.dayLabelHeight()))
+}
+
+// qrCountBits returns the length of the count of bytes in a version
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// qrRawModules returns the number of modules of a version available for
+// data and error correction, after the patterns and format information
+func qrRawModules(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		alignments := version/7 + 2
+		modules -= (25*alignments-10)*alignments - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules
+}
+
+// qrDataCodewords returns the number of codewords of data of a version at
+// level
+func qrDataCodewords(version int, level QRLevel) int {
+	i := level.index()
+	return qrRawModules(version)/8 - qrECCPerBlock[i][version]*qrBlocks[i][version]
+}
+
+// qrAddECC splits data in blocks, adds the error correction codewords to
+// each of them and interleaves them
+func qrAddECC(data []byte, version int, level QRLevel) []byte {
+	blocks := qrBlocks[level.index()][version]
+	eccLen := qrECCPerBlock[level.index()][version]
+	raw := qrRawModules(version) / 8
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw / blocks
+
+	divisor := reedSolomonDivisor(eccLen)
+	var all [][]byte
+	for i, k := 0, 0; i < blocks; i++ {
+		dataLen := shortLen - eccLen
+		if i >= shortBlocks {
+			dataLen++
+		}
+		block := append([]byte{}, data[k:k+dataLen]...)
+		k += dataLen
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < shortBlocks {
+			block = append(block, 0) // skipped when interleaving
+		}
+		all = append(all, append(block, ecc...))
+	}
+
+	var result []byte
+	for i := range all[0] {
+		for j, block := range all {
+			if i != shortLen-eccLen || j >= shortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of degree, without
+// its leading term, highest powers first
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrBits is a sequence of bits, most significant first
+type qrBits []bool
+
+func (bits *qrBits) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*bits = append(*bits, (value>>i)&1 != 0)
+	}
+}
+
+// set sets a module of a pattern
+func (qr *QRCode) set(x, y int, dark bool) {
+	qr.modules[y*qr.size+x] = dark
+	qr.function[y*qr.size+x] = true
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns and
+// reserves the modules of the format and version information
+func (qr *QRCode) drawFunctionPatterns(version int, level QRLevel) {
+	for i := range qr.size {
+		qr.set(6, i, i%2 == 0)
+		qr.set(i, 6, i%2 == 0)
+	}
+
+	for _, center := range [][2]int{{3, 3}, {qr.size - 4, 3}, {3, qr.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x >= 0 && x < qr.size && y >= 0 && y < qr.size {
+					distance := max(abs(dx), abs(dy))
+					qr.set(x, y, distance != 2 && distance != 4)
+				}
+			}
+		}
+	}
+
+	positions := qrAlignmentPositions(version)
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			// Alignment patterns don't overlap the finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					qr.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// The format is drawn for real with the mask
+	qr.drawFormatBits(level, 0)
+
+	if version >= 7 {
+		remainder := version
+		for range 12 {
+			remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+		}
+		bits := version<<12 | remainder
+		for i := range 18 {
+			dark := (bits>>i)&1 != 0
+			a, b := qr.size-11+i%3, i/3
+			qr.set(a, b, dark)
+			qr.set(b, a, dark)
+		}
+	}
+}
+
+// qrAlignmentPositions returns the centers of the alignment patterns of a
+// version, in both axes
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	count := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + count*2 + 1) / (count*2 - 2) * 2
+	}
+
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, 4*version+17-7; i > 0; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
+
+// drawFormatBits draws both copies of the format information of level and
+// mask
+func (qr *QRCode) drawFormatBits(level QRLevel, mask int) {
+	data := level.formatBits()<<3 | mask
+	remainder := data
+	for range 10 {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool {
+		return (bits>>i)&1 != 0
+	}
+
+	// Around the top left finder pattern
+	for i := range 6 {
+		qr.set(8, i, bit(i))
+	}
+	qr.set(8, 7, bit(6))
+	qr.set(8, 8, bit(7))
+	qr.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.set(14-i, 8, bit(i))
+	}
+
+	// Split between the other two finder patterns
+	for i := range 8 {
+		qr.set(qr.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.set(8, qr.size-15+i, bit(i))
+	}
+	qr.set(8, qr.size-8, true)
+}
+
+// drawCodewords draws data in zigzag, two columns at a time from the bottom
+// right corner, skipping the modules of the patterns
+func (qr *QRCode) drawCodewords(data []byte) {
+	i := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		for vertical := range qr.size {
+			for j := range 2 {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = qr.size - 1 - vertical // upward
+				}
+				if !qr.function[y*qr.size+x] && i < len(data)*8 {
+					qr.modules[y*qr.size+x] = (data[i/8]>>(7-i%8))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by mask
+func (qr *QRCode) applyMask(mask int) {
+	for y := range qr.size {
+		for x := range qr.size {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !qr.function[y*qr.size+x] {
+				qr.modules[y*qr.size+x] = !qr.modules[y*qr.size+x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to read: long runs of a color, blocks
+// of a color, patterns like the finders and imbalance of dark and light
+func (qr *QRCode) penalty() int {
+	penalty := 0
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i <= qr.size; i++ {
+			if i < qr.size && get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				penalty += 3 + run - 5
+			}
+			run = 1
+		}
+
+		finder := []bool{true, false, true, true, true, false, true}
+		for i := 0; i+len(finder) <= qr.size; i++ {
+			matches := true
+			for j, dark := range finder {
+				if get(i+j) != dark {
+					matches = false
+					break
+				}
+			}
+			if !matches {
+				continue
+			}
+			lightBefore, lightAfter := true, true
+			for j := 1; j <= 4; j++ {
+				lightBefore = lightBefore && (i-j < 0 || !get(i-j))
+				lightAfter = lightAfter && (i+6+j >= qr.size || !get(i+6+j))
+			}
+			if lightBefore || lightAfter {
+				penalty += 40
+			}
+		}
+	}
+
+	dark := 0
+	for i := range qr.size {
+		line(func(x int) bool { return qr.Dark(x, i) })
+		line(func(y int) bool { return qr.Dark(i, y) })
+		for j := range qr.size {
+			if qr.Dark(j, i) {
+				dark++
+			}
+			if i > 0 && j > 0 {
+				color := qr.Dark(j, i)
+				if color == qr.Dark(j-1, i) && color == qr.Dark(j, i-1) && color == qr.Dark(j-1, i-1) {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := qr.size * qr.size
+	penalty += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+
+	return penalty
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
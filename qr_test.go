@@ -0,0 +1,68 @@
+package galendar_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestParseQRLevel(t *testing.T) {
+	for input, expected := range map[string]galendar.QRLevel{
+		"":  galendar.QRLevelMedium,
+		"l": galendar.QRLevelLow,
+		"Q": galendar.QRLevelQuartile,
+		"H": galendar.QRLevelHigh,
+	} {
+		level, err := galendar.ParseQRLevel(input)
+		if err != nil || level != expected {
+			t.Errorf("ParseQRLevel(%q) = %q, %v; expected %q", input, level, err, expected)
+		}
+	}
+
+	if _, err := galendar.ParseQRLevel("X"); err == nil {
+		t.Errorf("Expected an error for an invalid level")
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	// The smallest versions that hold 17 bytes: 1-L holds 17, 3-H holds 24
+	for level, size := range map[galendar.QRLevel]int{
+		galendar.QRLevelLow:  21,
+		galendar.QRLevelHigh: 29,
+	} {
+		qr, err := galendar.EncodeQR("https://x.example", level)
+		if err != nil {
+			t.Fatalf("EncodeQR failed: %v", err)
+		}
+		if qr.Size() != size {
+			t.Errorf("Expected %d modules at level %s, got %d", size, level, qr.Size())
+		}
+
+		// Finder patterns: dark borders and centers, light rings
+		for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+			x, y := corner[0], corner[1]
+			if !qr.Dark(x, y) || !qr.Dark(x+6, y+6) || !qr.Dark(x+3, y+3) || qr.Dark(x+1, y+1) {
+				t.Errorf("Expected a finder pattern at %d, %d", x, y)
+			}
+		}
+
+		// Timing patterns between them, and the dark module
+		for i := 8; i < size-8; i++ {
+			if qr.Dark(i, 6) != (i%2 == 0) || qr.Dark(6, i) != (i%2 == 0) {
+				t.Errorf("Expected timing patterns at %d", i)
+			}
+		}
+		if !qr.Dark(8, size-8) {
+			t.Errorf("Expected the dark module")
+		}
+	}
+
+	// Version 40 at level H holds 1273 bytes
+	if qr, err := galendar.EncodeQR(strings.Repeat("a", 1273), galendar.QRLevelHigh); err != nil || qr.Size() != 177 {
+		t.Errorf("Expected the largest QR code for 1273 bytes, got %v", err)
+	}
+	if _, err := galendar.EncodeQR(strings.Repeat("a", 1274), galendar.QRLevelHigh); err == nil {
+		t.Errorf("Expected an error for a text too long")
+	}
+}
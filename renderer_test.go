@@ -0,0 +1,259 @@
+package galendar_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestRenderers_ReproducibleOutput(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "1/1"
+holiday = true
+text = "Año Nuevo"
+icon = "builtin:new_years_eve"
+
+[[day]]
+when = "24/12"
+text = "Nochebuena"
+icon = "builtin:christmas_eve"
+
+[[day]]
+when = "25/12"
+holiday = true
+text = "Navidad"
+icon = "builtin:christmas_tree"
+
+[[day]]
+when = "10/12"
+icon = "builtin:father_day"
+`)
+	defer os.Remove(tmpFile)
+
+	for _, renderer := range []galendar.Renderer{galendar.PDFRenderer{}, galendar.SVGRenderer{}, galendar.JSONRenderer{}} {
+		t.Run(renderer.Name(), func(t *testing.T) {
+			cfg := testConfig(t, renderer)
+			cfg.Year, cfg.Month = 2025, 12
+
+			specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+			if err != nil {
+				t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+			}
+
+			render := func() []byte {
+				cfg.OutputDir = t.TempDir()
+
+				cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+				if err != nil {
+					t.Fatalf("NewCalendar failed: %v", err)
+				}
+
+				if err := renderer.RenderMonth(cfg, cal); err != nil {
+					t.Fatalf("RenderMonth failed: %v", err)
+				}
+
+				content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+				if err != nil {
+					t.Fatalf("Failed to read output: %v", err)
+				}
+				return content
+			}
+
+			first := render()
+			second := render()
+
+			if !bytes.Equal(first, second) {
+				t.Errorf("Expected identical output in both %s renders", renderer.Name())
+			}
+
+			// The only dates in the output are the ones of OutputDate, never
+			// the current time
+			if _, ok := renderer.(galendar.PDFRenderer); ok {
+				cfg.SourceDate = time.Date(2024, time.March, 5, 6, 7, 8, 0, time.UTC)
+				content := render()
+				for _, want := range []string{"/CreationDate (D:20240305060708)", "/ModDate (D:20240305060708)"} {
+					if !bytes.Contains(content, []byte(want)) {
+						t.Errorf("Expected %q in output", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_OutputDate(t *testing.T) {
+	cfg := galendar.Config{Year: 2025}
+
+	expected := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if date := cfg.OutputDate(); !date.Equal(expected) {
+		t.Errorf("Expected output date %v, got %v", expected, date)
+	}
+
+	cfg.SourceDate = time.Unix(1700000000, 0).UTC()
+	if date := cfg.OutputDate(); !date.Equal(cfg.SourceDate) {
+		t.Errorf("Expected output date %v, got %v", cfg.SourceDate, date)
+	}
+}
+
+func TestRenderers_NoteOverflow(t *testing.T) {
+	note := strings.Repeat("A note far too long to fit in a single cell of the calendar. ", 6)
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "15/7"
+text = "`+note+`"
+
+[[day]]
+when = "17/7"
+text = "Short note"
+`)
+	defer os.Remove(tmpFile)
+
+	render := func(renderer galendar.Renderer, overflow galendar.NoteOverflow) string {
+		cfg := testConfig(t, renderer)
+		cfg.Year, cfg.Month = 2025, 7
+		cfg.NoteMinFontSize = 12
+		cfg.NoteOverflow = overflow
+
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+
+		if err := renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return string(content)
+	}
+
+	t.Run("truncate", func(t *testing.T) {
+		svg := render(galendar.SVGRenderer{}, galendar.NoteOverflowTruncate)
+		if !strings.Contains(svg, "…</tspan>") {
+			t.Errorf("Expected the long note to end with an ellipsis")
+		}
+		if strings.Contains(svg, "[1]") {
+			t.Errorf("Expected no footnotes")
+		}
+		if !strings.Contains(svg, ">Short note</text>") {
+			t.Errorf("Expected the short note untouched")
+		}
+	})
+
+	t.Run("footnote", func(t *testing.T) {
+		svg := render(galendar.SVGRenderer{}, galendar.NoteOverflowFootnote)
+		if !strings.Contains(svg, "… [1]</tspan>") {
+			t.Errorf("Expected the long note to reference its footnote")
+		}
+		if !strings.Contains(svg, ">[1] 15: A note far too long") {
+			t.Errorf("Expected the footnote with the whole note")
+		}
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		pdf := render(galendar.PDFRenderer{}, galendar.NoteOverflowFootnote)
+		if pages := strings.Count(pdf, "/Type /Page\n"); pages != 1 {
+			t.Errorf("Expected the overflowing note to stay in 1 page, got %d pages", pages)
+		}
+	})
+}
+
+func TestRenderers_TooManyFootnotes(t *testing.T) {
+	note := strings.Repeat("A note far too long to fit in a single cell of the calendar. ", 6)
+	days := `date_format = "2/1"
+`
+	for day := 1; day <= 31; day++ {
+		days += fmt.Sprintf("\n[[day]]\nwhen = \"%d/7\"\ntext = \"%s\"\n", day, note)
+	}
+	tmpFile := createTempSpecialDaysFile(t, days)
+	defer os.Remove(tmpFile)
+
+	for _, renderer := range []galendar.Renderer{galendar.SVGRenderer{}, galendar.PDFRenderer{}} {
+		cfg := testConfig(t, renderer)
+		cfg.Year, cfg.Month = 2025, 7
+		cfg.NoteMinFontSize = 12
+		cfg.NoteOverflow = galendar.NoteOverflowFootnote
+
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+
+		if err := renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+
+		// Footnotes take at most half the grid, 4 of them fit
+		switch renderer.(type) {
+		case galendar.SVGRenderer:
+			if !strings.Contains(string(content), ">[4] 4: A note") || strings.Contains(string(content), "[5] 5:") {
+				t.Errorf("Expected the first 4 footnotes only")
+			}
+			if !strings.Contains(string(content), "…y 27 más") {
+				t.Errorf("Expected the count of the footnotes left out")
+			}
+		case galendar.PDFRenderer:
+			if pages := strings.Count(string(content), "/Type /Page\n"); pages != 1 {
+				t.Errorf("Expected the footnotes to stay in 1 page, got %d pages", pages)
+			}
+		}
+	}
+}
+
+func TestRenderers_MissingIcon(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "27/12"
+icon = "missing.svg"
+`)
+	defer os.Remove(tmpFile)
+
+	for _, renderer := range []galendar.Renderer{galendar.PDFRenderer{}, galendar.SVGRenderer{}} {
+		t.Run(renderer.Name(), func(t *testing.T) {
+			cfg := testConfig(t, renderer)
+			cfg.Year, cfg.Month = 2025, 12
+
+			specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+			if err != nil {
+				t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+			}
+
+			cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+			if err != nil {
+				t.Fatalf("NewCalendar failed: %v", err)
+			}
+
+			err = renderer.RenderMonth(cfg, cal)
+			if err == nil || !strings.Contains(err.Error(), "missing.svg") {
+				t.Errorf("Expected an error about the missing icon, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package galendar
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// textStyle is the style of a run of rich text, the zero value is the plain
+// text of the note
+type textStyle struct {
+	bold, italic bool
+	color        Color // not valid for the color of the note
+}
+
+// textRun is a piece of text with a single style
+type textRun struct {
+	text  string
+	style textStyle
+}
+
+// richLine is a line of rich text, as runs of text
+type richLine []textRun
+
+// String returns the text of line without styles
+func (line richLine) String() string {
+	var sb strings.Builder
+	for _, run := range line {
+		sb.WriteString(run.text)
+	}
+	return sb.String()
+}
+
+// plain reports if all of line is in the style of the note
+func (line richLine) plain() bool {
+	for _, run := range line {
+		if run.style != (textStyle{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// textColors are the color names accepted by the {color:name} markup, besides
+// the colors of themes
+var textColors = map[string]string{
+	"black":  "#000000",
+	"white":  "#ffffff",
+	"gray":   "#808080",
+	"red":    "#d32f2f",
+	"orange": "#ef6c00",
+	"yellow": "#f9a825",
+	"green":  "#2e7d32",
+	"blue":   "#1565c0",
+	"purple": "#6a1b9a",
+	"pink":   "#c2185b",
+	"brown":  "#6d4c41",
+}
+
+// parseRichText parses the markup of note texts into runs: **bold**,
+// *italic*, {color:red}...{/color} and line breaks, that can be written as \n.
+// Markers that are never closed and markers escaped with a backslash are
+// text. Runs with an invalid color keep the color around them
+func parseRichText(text string) ([]textRun, error) {
+	var runs []textRun
+	var style textStyle
+	var colors []Color
+	var current strings.Builder
+	var errs []string
+
+	flush := func() {
+		if current.Len() > 0 {
+			runs = append(runs, textRun{text: current.String(), style: style})
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, `\n`):
+			current.WriteByte('\n')
+			i += 2
+		case len(rest) > 1 && rest[0] == '\\' && strings.IndexByte(`\*{`, rest[1]) >= 0:
+			current.WriteByte(rest[1])
+			i += 2
+		case strings.HasPrefix(rest, "**") && (style.bold || hasMarker(rest[2:], "**")):
+			flush()
+			style.bold = !style.bold
+			i += 2
+		case strings.HasPrefix(rest, "**"):
+			// Never closed, its stars are not italic markers either
+			current.WriteString("**")
+			i += 2
+		case rest[0] == '*' && (style.italic || hasMarker(rest[1:], "*")):
+			flush()
+			style.italic = !style.italic
+			i++
+		case strings.HasPrefix(rest, "{color:") && strings.IndexByte(rest, '}') > 0:
+			end := strings.IndexByte(rest, '}')
+			name := rest[len("{color:"):end]
+			color, err := parseTextColor(name)
+			if err != nil {
+				errs = append(errs, err.Error())
+				color = style.color
+			}
+			flush()
+			colors = append(colors, color)
+			style.color = color
+			i += end + 1
+		case strings.HasPrefix(rest, "{/color}") && len(colors) > 0:
+			flush()
+			colors = colors[:len(colors)-1]
+			style.color = Color{}
+			if len(colors) > 0 {
+				style.color = colors[len(colors)-1]
+			}
+			i += len("{/color}")
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			current.WriteString(rest[:size])
+			i += size
+		}
+	}
+	flush()
+
+	if len(errs) > 0 {
+		return runs, fmt.Errorf("invalid markup: %s", strings.Join(errs, ", "))
+	}
+	return runs, nil
+}
+
+// hasMarker reports if s has marker, ** or *, out of escapes. The stars of **
+// are never a * marker
+func hasMarker(s, marker string) bool {
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\':
+			i += 2
+		case strings.HasPrefix(s[i:], "**"):
+			if marker == "**" {
+				return true
+			}
+			i += 2
+		case s[i] == '*':
+			if marker == "*" {
+				return true
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return false
+}
+
+// parseTextColor parses the color of a {color:name} markup, by name or as
+// the colors of themes
+func parseTextColor(name string) (Color, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if hex, ok := textColors[name]; ok {
+		name = hex
+	}
+
+	var color Color
+	if err := color.UnmarshalText([]byte(name)); err != nil {
+		return Color{}, err
+	}
+	return color, nil
+}
+
+// runs returns the text of note parsed as rich text
+func (note SpecialDayNote) runs() []textRun {
+	runs, _ := parseRichText(note.Text)
+	return runs
+}
+
+// plainText returns the text of note without markup
+func (note SpecialDayNote) plainText() string {
+	return richLine(note.runs()).String()
+}
+
+// wrapRuns breaks runs into lines that fit within maxWidth as measured by
+// measure, the same way wrapText does with plain text: explicit line breaks
+// are kept, words are separated by a single space and words longer than a
+// line are broken at the widest prefix that fits
+func wrapRuns(runs []textRun, maxWidth float64, measure func(string, textStyle) float64) []richLine {
+	if len(runs) == 0 {
+		return []richLine{nil}
+	}
+
+	width := func(line richLine) float64 {
+		total := 0.0
+		for _, run := range line {
+			total += measure(run.text, run.style)
+		}
+		return total
+	}
+
+	var lines []richLine
+	for _, paragraph := range richParagraphs(runs) {
+		words := richWords(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, nil)
+			continue
+		}
+
+		var currentLine richLine
+		for _, word := range words {
+			testLine := word
+			if currentLine != nil {
+				testLine = appendRuns(appendRuns(nil, currentLine...), textRun{text: " ", style: currentLine[len(currentLine)-1].style})
+				testLine = appendRuns(testLine, word...)
+			}
+
+			if width(testLine) <= maxWidth {
+				currentLine = testLine
+				continue
+			}
+
+			if currentLine != nil {
+				lines = append(lines, currentLine)
+			}
+
+			// Break words that don't fit in a line by themselves
+			for width(word) > maxWidth {
+				prefix, rest := splitRichWord(word, maxWidth, width)
+				lines = append(lines, prefix)
+				word = rest
+			}
+			currentLine = word
+		}
+
+		if currentLine != nil {
+			lines = append(lines, currentLine)
+		}
+	}
+
+	return lines
+}
+
+// appendRuns appends runs to line merging the runs of the same style
+func appendRuns(line richLine, runs ...textRun) richLine {
+	for _, run := range runs {
+		if run.text == "" {
+			continue
+		}
+		if last := len(line) - 1; last >= 0 && line[last].style == run.style {
+			line[last].text += run.text
+			continue
+		}
+		line = append(line, run)
+	}
+	return line
+}
+
+// richParagraphs splits runs at their line breaks
+func richParagraphs(runs []textRun) [][]textRun {
+	paragraphs := [][]textRun{nil}
+	for _, run := range runs {
+		for i, part := range strings.Split(run.text, "\n") {
+			if i > 0 {
+				paragraphs = append(paragraphs, nil)
+			}
+			last := len(paragraphs) - 1
+			paragraphs[last] = append(paragraphs[last], textRun{text: part, style: run.style})
+		}
+	}
+	return paragraphs
+}
+
+// richWords splits a paragraph into its words, words can have runs of many
+// styles
+func richWords(paragraph []textRun) []richLine {
+	var words []richLine
+	var word richLine
+	for _, run := range paragraph {
+		start := -1
+		for i, r := range run.text {
+			if unicode.IsSpace(r) {
+				if start >= 0 {
+					word = appendRuns(word, textRun{text: run.text[start:i], style: run.style})
+					start = -1
+				}
+				if word != nil {
+					words = append(words, word)
+					word = nil
+				}
+				continue
+			}
+			if start < 0 {
+				start = i
+			}
+		}
+		if start >= 0 {
+			word = appendRuns(word, textRun{text: run.text[start:], style: run.style})
+		}
+	}
+	if word != nil {
+		words = append(words, word)
+	}
+	return words
+}
+
+// splitRichWord returns the longest prefix of word (at least one rune) that
+// fits in maxWidth and the rest of it
+func splitRichWord(word richLine, maxWidth float64, width func(richLine) float64) (richLine, richLine) {
+	var prefix richLine
+	for i, run := range word {
+		for j, r := range run.text {
+			size := utf8.RuneLen(r)
+			next := appendRuns(appendRuns(nil, prefix...), textRun{text: run.text[j : j+size], style: run.style})
+			if prefix != nil && width(next) > maxWidth {
+				rest := appendRuns(nil, textRun{text: run.text[j:], style: run.style})
+				return prefix, appendRuns(rest, word[i+1:]...)
+			}
+			prefix = next
+		}
+	}
+	return prefix, nil
+}
+
+// truncateRichLine removes runes from the end of line until it fits in
+// maxWidth followed by suffix, in the style of the end of the line
+func truncateRichLine(line richLine, suffix string, maxWidth float64, measure func(string, textStyle) float64) richLine {
+	line = appendRuns(nil, line...)
+	style := textStyle{}
+	if len(line) > 0 {
+		style = line[len(line)-1].style
+	}
+
+	width := func() float64 {
+		total := 0.0
+		for _, run := range appendRuns(appendRuns(nil, line...), textRun{text: suffix, style: style}) {
+			total += measure(run.text, run.style)
+		}
+		return total
+	}
+
+	for len(line) > 0 && width() > maxWidth {
+		last := len(line) - 1
+		_, size := utf8.DecodeLastRuneInString(line[last].text)
+		line[last].text = line[last].text[:len(line[last].text)-size]
+		if line[last].text == "" {
+			line = line[:last]
+		}
+	}
+
+	if last := len(line) - 1; last >= 0 {
+		line[last].text = strings.TrimRight(line[last].text, " ")
+	}
+	return appendRuns(line, textRun{text: suffix, style: style})
+}
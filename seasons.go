@@ -0,0 +1,124 @@
+package galendar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Hemisphere tells which seasons start at the equinoxes and solstices
+type Hemisphere string
+
+const (
+	HemisphereNorth Hemisphere = "north"
+	HemisphereSouth Hemisphere = "south"
+)
+
+// ParseHemisphere parses a hemisphere, an empty string means north
+func ParseHemisphere(s string) (Hemisphere, error) {
+	switch hemisphere := Hemisphere(strings.ToLower(strings.TrimSpace(s))); hemisphere {
+	case "":
+		return HemisphereNorth, nil
+	case HemisphereNorth, HemisphereSouth:
+		return hemisphere, nil
+	default:
+		return "", fmt.Errorf("invalid hemisphere: %q (must be north or south)", s)
+	}
+}
+
+// Season is an equinox or solstice, the start of a season
+type Season struct {
+	Time  time.Time // in UTC
+	Name  string    // of the season it starts in the hemisphere, to be translated: "Spring", "Summer", "Autumn" or "Winter"
+	Label string    // of the equinox or solstice, to be translated, as "Spring equinox"
+	Icon  string    // builtin icon of the season
+}
+
+// seasonNames are the seasons that start at the March equinox, the June
+// solstice, the September equinox and the December solstice in the northern
+// hemisphere, in the southern one they are shifted by two
+var seasonNames = []string{"Spring", "Summer", "Autumn", "Winter"}
+
+// seasonTerms are the mean instants of the equinoxes and solstices in julian
+// ephemeris days for the years from 1000 to 3000, as polynomials of the
+// millennia since 2000 (Meeus, chapter 27)
+var seasonTerms = [4][5]float64{
+	{2451623.80984, 365242.37404, 0.05169, -0.00411, -0.00057},
+	{2451716.56767, 365241.62603, 0.00325, 0.00888, -0.00030},
+	{2451810.21715, 365242.01767, -0.11575, 0.00337, 0.00078},
+	{2451900.05952, 365242.74049, -0.06223, -0.00823, 0.00032},
+}
+
+// seasonPeriodicTerms correct the mean instants for the perturbations of the
+// orbit of the Earth, as amplitude, phase and rate
+var seasonPeriodicTerms = [][3]float64{
+	{485, 324.96, 1934.136}, {203, 337.23, 32964.467}, {199, 342.08, 20.186},
+	{182, 27.85, 445267.112}, {156, 73.14, 45036.886}, {136, 171.52, 22518.443},
+	{77, 222.54, 65928.934}, {74, 296.72, 3034.906}, {70, 243.58, 9037.513},
+	{58, 119.81, 33718.147}, {52, 297.17, 150.678}, {50, 21.02, 2281.226},
+	{45, 247.54, 29929.562}, {44, 325.15, 31555.956}, {29, 60.93, 4443.417},
+	{18, 155.12, 67555.328}, {17, 288.79, 4562.452}, {16, 198.04, 62894.029},
+	{14, 199.76, 31436.921}, {12, 95.39, 14577.848}, {12, 287.11, 31931.756},
+	{12, 320.81, 34777.259}, {9, 227.73, 1222.114}, {8, 15.45, 16859.074},
+}
+
+// Seasons returns the equinoxes and solstices of year in order, named after
+// the seasons they start in hemisphere
+func Seasons(year int, hemisphere Hemisphere) []Season {
+	y := float64(year-2000) / 1000
+
+	var seasons []Season
+	for i, terms := range seasonTerms {
+		jde0 := terms[0] + terms[1]*y + terms[2]*y*y + terms[3]*y*y*y + terms[4]*y*y*y*y
+		t := (jde0 - jdJ2000) / 36525
+		w := radians(35999.373*t - 2.47)
+		dl := 1 + 0.0334*math.Cos(w) + 0.0007*math.Cos(2*w)
+
+		s := 0.0
+		for _, term := range seasonPeriodicTerms {
+			s += term[0] * math.Cos(radians(term[1]+term[2]*t))
+		}
+
+		name := seasonNames[i]
+		if hemisphere == HemisphereSouth {
+			name = seasonNames[(i+2)%4]
+		}
+		kind := "equinox"
+		if i%2 == 1 {
+			kind = "solstice"
+		}
+
+		seasons = append(seasons, Season{
+			Time:  timeOfJulianEphemerisDay(jde0 + 0.00001*s/dl),
+			Name:  name,
+			Label: name + " " + kind,
+			Icon:  BuiltinIconPrefix + strings.ToLower(name),
+		})
+	}
+
+	return seasons
+}
+
+// SeasonSpecialDays returns the equinoxes and solstices of cfg.Year as
+// special days, on their dates in cfg.TimeZone with the icon of the season
+// and its label in cfg.Language
+func SeasonSpecialDays(cfg Config) SpecialDays {
+	loc := cfg.TimeZone
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	days := SpecialDays{}
+	for _, season := range Seasons(cfg.Year, cfg.Hemisphere) {
+		local := season.Time.In(loc)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		days[specialDaysKeyFromTime(date)] = SpecialDay{
+			Date: date,
+			Icon: season.Icon,
+			Note: SpecialDayNote{Text: cfg.Language.Read(season.Label)},
+		}
+	}
+
+	return days
+}
@@ -0,0 +1,133 @@
+package galendar_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestParseHemisphere(t *testing.T) {
+	for input, expected := range map[string]galendar.Hemisphere{
+		"":      galendar.HemisphereNorth,
+		"North": galendar.HemisphereNorth,
+		"south": galendar.HemisphereSouth,
+	} {
+		hemisphere, err := galendar.ParseHemisphere(input)
+		if err != nil {
+			t.Errorf("ParseHemisphere(%q) failed: %v", input, err)
+		} else if hemisphere != expected {
+			t.Errorf("ParseHemisphere(%q) = %q, expected %q", input, hemisphere, expected)
+		}
+	}
+
+	if _, err := galendar.ParseHemisphere("east"); err == nil {
+		t.Errorf("Expected an error for an invalid hemisphere")
+	}
+}
+
+func TestSeasons(t *testing.T) {
+	// Equinoxes and solstices of 2025 published by the US Naval Observatory
+	expected := []struct {
+		time  time.Time
+		north string
+		south string
+	}{
+		{time.Date(2025, 3, 20, 9, 1, 0, 0, time.UTC), "Spring equinox", "Autumn equinox"},
+		{time.Date(2025, 6, 21, 2, 42, 0, 0, time.UTC), "Summer solstice", "Winter solstice"},
+		{time.Date(2025, 9, 22, 18, 19, 0, 0, time.UTC), "Autumn equinox", "Spring equinox"},
+		{time.Date(2025, 12, 21, 15, 3, 0, 0, time.UTC), "Winter solstice", "Summer solstice"},
+	}
+
+	north := galendar.Seasons(2025, galendar.HemisphereNorth)
+	south := galendar.Seasons(2025, galendar.HemisphereSouth)
+	if len(north) != len(expected) || len(south) != len(expected) {
+		t.Fatalf("Expected %d seasons, got %d and %d", len(expected), len(north), len(south))
+	}
+
+	for i, want := range expected {
+		if diff := north[i].Time.Sub(want.time).Abs(); diff > 2*time.Minute {
+			t.Errorf("Expected the %s at %v, got %v", want.north, want.time, north[i].Time)
+		}
+		if north[i].Label != want.north || south[i].Label != want.south {
+			t.Errorf("Expected %q and %q, got %q and %q", want.north, want.south, north[i].Label, south[i].Label)
+		}
+		if !south[i].Time.Equal(north[i].Time) {
+			t.Errorf("Expected the same instants in both hemispheres, got %v and %v", north[i].Time, south[i].Time)
+		}
+	}
+}
+
+func TestSeasonSpecialDays(t *testing.T) {
+	cfg := galendar.Config{Year: 2025, Language: galendar.Spanish, Hemisphere: galendar.HemisphereSouth}
+
+	cal, err := galendar.NewCalendar(2025, 12, time.Sunday, galendar.SeasonSpecialDays(cfg))
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	days := moonDays(cal)
+	if note := days[21].Note(); note == nil || note.Text != "Solsticio de verano" || days[21].Icon() != "builtin:summer" {
+		t.Errorf("Expected the summer solstice on December 21st, got %v with icon %q", note, days[21].Icon())
+	}
+
+	// The solstice at 15:03 UTC is on the 22nd in Tokyo, with the day of a file
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "22/12"
+text = "Cumpleaños"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg.TimeZone = time.FixedZone("JST", 9*60*60)
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+	cal, err = galendar.NewCalendar(2025, 12, time.Sunday, specialDays.Combine(galendar.SeasonSpecialDays(cfg)))
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	days = moonDays(cal)
+	if days[21].Note() != nil {
+		t.Errorf("Expected no solstice on December 21st in Tokyo, got %q", days[21].Note().Text)
+	}
+	if note := days[22].Note(); note == nil || note.Text != `Cumpleaños\nSolsticio de verano` || days[22].Icon() != "builtin:summer" {
+		t.Errorf("Expected the day of the file and the solstice on December 22nd, got %v with icon %q", note, days[22].Icon())
+	}
+}
+
+func TestSeasonSpecialDays_CombinedWithFileDays(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "20/3"
+text = "Feriado"
+icon = "builtin:anniversary"
+holiday = true
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := galendar.Config{Year: 2025, Language: galendar.Spanish, Seasons: true}
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+	cal, err := galendar.NewCalendar(2025, 3, time.Sunday, specialDays.Combine(galendar.SeasonSpecialDays(cfg)))
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	// The equinox of March 20th keeps its icon and label next to the day of the file
+	day := moonDays(cal)[20]
+	if note := day.Note(); note == nil || note.Text != `Feriado\nEquinoccio de primavera` {
+		t.Errorf("Expected the note of the file and the equinox on March 20th, got %v", note)
+	}
+	if icons := day.Icons(); len(icons) != 2 || icons[0] != "builtin:anniversary" || icons[1] != "builtin:spring" {
+		t.Errorf("Expected the icons of the file and the equinox on March 20th, got %v", icons)
+	}
+	if !day.IsHoliday() {
+		t.Error("Expected March 20th to stay a holiday")
+	}
+}
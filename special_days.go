@@ -8,6 +8,8 @@ type SpecialDay struct {
 	Date    time.Time
 	Holiday bool
 	Icon    string
+	QR      string   // text of the QR code of the day, such as the url of a meeting
+	Icons   []string // more icons, drawn to the left of Icon
 	Note    SpecialDayNote
 }
 
@@ -15,6 +17,7 @@ type SpecialDayNote struct {
 	Text string
 	Font string
 	Size float64
+	URL  string // opened by clicking the note, from the url of the day or the [text](url) link of the text
 }
 
 type SpecialDays map[specialDaysKey]SpecialDay
@@ -29,3 +32,43 @@ func (days SpecialDays) At(date time.Time) *SpecialDay {
 	}
 	return nil
 }
+
+// Merge returns the special days of days and other, days win on the dates
+// both have
+func (days SpecialDays) Merge(other SpecialDays) SpecialDays {
+	merged := SpecialDays{}
+	for key, day := range other {
+		merged[key] = day
+	}
+	for key, day := range days {
+		merged[key] = day
+	}
+	return merged
+}
+
+// Combine returns the special days of days and other, on the dates both have
+// the day of days gets the icon of other after its own and the note text of
+// other in a new line
+func (days SpecialDays) Combine(other SpecialDays) SpecialDays {
+	combined := days.Merge(other)
+	for key, day := range days {
+		extra, ok := other[key]
+		if !ok {
+			continue
+		}
+		icons := append([]string{}, day.Icons...)
+		if day.Icon == "" {
+			day.Icon = extra.Icon
+		} else if extra.Icon != "" {
+			icons = append(icons, extra.Icon)
+		}
+		day.Icons = append(icons, extra.Icons...)
+		if day.Note.Text == "" {
+			day.Note.Text = extra.Note.Text
+		} else if extra.Note.Text != "" {
+			day.Note.Text += `\n` + extra.Note.Text
+		}
+		combined[key] = day
+	}
+	return combined
+}
@@ -2,6 +2,7 @@ package galendar_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -315,12 +316,64 @@ icon = "assets/icon-((year)).svg"
 	if day == nil {
 		t.Fatalf("Expected to find special day for March 18, 2024")
 	}
-	expectedIcon := "assets/icon-2024.svg"
+	// icons are relative to the special days file
+	expectedIcon := filepath.Join(filepath.Dir(tmpFile), "assets/icon-2024.svg")
 	if day.Icon != expectedIcon {
 		t.Errorf("Expected icon %q, got %q", expectedIcon, day.Icon)
 	}
 }
 
+func TestLoadSpecialDaysFromFile_BuiltinIcon(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "25/12"
+text = "Navidad"
+icon = "builtin:christmas_tree"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := galendar.Config{
+		Year:  2024,
+		Month: 12,
+	}
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	date := time.Date(2024, time.December, 25, 0, 0, 0, 0, time.UTC)
+	day := specialDays.At(date)
+	if day == nil {
+		t.Fatalf("Expected to find special day for December 25, 2024")
+	}
+	expectedIcon := "builtin:christmas_tree"
+	if day.Icon != expectedIcon {
+		t.Errorf("Expected icon %q, got %q", expectedIcon, day.Icon)
+	}
+}
+
+func TestLoadSpecialDaysFromFile_UnknownBuiltinIcon(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "25/12"
+icon = "builtin:does_not_exist"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := galendar.Config{
+		Year:  2024,
+		Month: 12,
+	}
+
+	_, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown builtin icon")
+	}
+}
+
 func TestLoadSpecialDaysFromFile_ExpressionEvaluation_FontProperty(t *testing.T) {
 	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
 
@@ -505,3 +558,83 @@ func createTempSpecialDaysFile(t *testing.T, content string) string {
 
 	return tmpFile.Name()
 }
+
+func TestLoadSpecialDaysFromFile_NoteLinks(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "3/3"
+text = "Ver [la minuta](https://wiki.example.com/minuta) antes"
+
+[[day]]
+when = "4/3"
+text = "Día de [Ada](https://es.wikipedia.org/wiki/Ada_(lenguaje)) (1980)"
+
+[[day]]
+when = "5/3"
+text = "Reunión"
+url = "https://meet.example.com/b"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := galendar.Config{Year: 2025}
+	days, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		day       int
+		text, url string
+	}{
+		{3, "Ver la minuta antes", "https://wiki.example.com/minuta"},
+		{4, "Día de Ada (1980)", "https://es.wikipedia.org/wiki/Ada_(lenguaje)"},
+		{5, "Reunión", "https://meet.example.com/b"},
+	} {
+		day := days.At(time.Date(2025, time.March, tt.day, 0, 0, 0, 0, time.UTC))
+		if day == nil {
+			t.Fatalf("Expected a special day on March %d", tt.day)
+		}
+		if day.Note.Text != tt.text || day.Note.URL != tt.url {
+			t.Errorf("Expected note %q linking to %q, got %q linking to %q", tt.text, tt.url, day.Note.Text, day.Note.URL)
+		}
+	}
+}
+
+func TestLoadSpecialDaysFromFile_NoteLinksRejectsMoreLinks(t *testing.T) {
+	for name, day := range map[string]string{
+		"two links": `text = "[Sala](https://meet.example.com/a) o [wiki](https://wiki.example.com)"`,
+		"link and url": `text = "Reunión [sala](https://meet.example.com/a)"
+url = "https://meet.example.com/b"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "3/3"
+`+day+`
+`)
+			defer os.Remove(tmpFile)
+
+			_, err := galendar.LoadSpecialDaysFromFile(tmpFile, galendar.Config{Year: 2025})
+			if err == nil {
+				t.Fatalf("Expected an error for a note with more than one url")
+			}
+		})
+	}
+}
+
+func TestLoadSpecialDaysFromFile_InvalidMarkup(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "3/3"
+text = "{color:rojizo}Reunión{/color}"
+`)
+	defer os.Remove(tmpFile)
+
+	_, err := galendar.LoadSpecialDaysFromFile(tmpFile, galendar.Config{Year: 2025})
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid color")
+	}
+}
@@ -2,6 +2,7 @@ package galendar
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -10,6 +11,9 @@ import (
 	"github.com/BurntSushi/toml"
 )
 
+// LoadSpecialDaysFromFile loads the special days of filename in cfg.Year.
+// Calendars that show days of other years, as their mini months, need the
+// special days of those years too, loaded with their year in cfg
 func LoadSpecialDaysFromFile(filename string, cfg Config) (SpecialDays, error) {
 	if filename == "" {
 		return nil, nil
@@ -24,52 +28,99 @@ func LoadSpecialDaysFromFile(filename string, cfg Config) (SpecialDays, error) {
 
 	days := SpecialDays{}
 	for _, day := range file.Day {
-		key, err := specialDaysKeyFromString(file.DateFormat, day.When, cfg)
+		keys, err := specialDaysKeysFromString(file.DateFormat, day.When, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("invalid 'when' value %q: %w", day.When, err)
 		}
 
-		// Create the date for this special day (using calendar year)
-		date := time.Date(cfg.Year, time.Month(key.month), key.day, 0, 0, 0, 0, time.UTC)
+		// Dates of other calendars can happen twice in a year
+		for _, key := range keys {
+			// Create the date for this special day (using calendar year)
+			date := time.Date(key.year, time.Month(key.month), key.day, 0, 0, 0, 0, time.UTC)
 
-		// Evaluate expressions in string properties
-		// We need to check if any expression evaluates to ≤ 0 to skip the day
-		evaluatedText, shouldSkip, err := evaluateExpressionsWithSkip(day.Text, cfg, date)
-		if err != nil {
-			return nil, fmt.Errorf("error evaluating text for day %q: %w", day.When, err)
-		}
-		if shouldSkip {
-			continue
-		}
+			// Evaluate expressions in string properties
+			// We need to check if any expression evaluates to ≤ 0 to skip the day
+			evaluatedText, shouldSkip, err := evaluateExpressionsWithSkip(day.Text, cfg, date)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating text for day %q: %w", day.When, err)
+			}
+			if shouldSkip {
+				continue
+			}
 
-		evaluatedIcon, shouldSkip, err := evaluateExpressionsWithSkip(day.Icon, cfg, date)
-		if err != nil {
-			return nil, fmt.Errorf("error evaluating icon for day %q: %w", day.When, err)
-		}
-		if shouldSkip {
-			continue
-		}
+			evaluatedIcon, shouldSkip, err := evaluateExpressionsWithSkip(day.Icon, cfg, date)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating icon for day %q: %w", day.When, err)
+			}
+			if shouldSkip {
+				continue
+			}
 
-		evaluatedFont, shouldSkip, err := evaluateExpressionsWithSkip(day.Font, cfg, date)
-		if err != nil {
-			return nil, fmt.Errorf("error evaluating font for day %q: %w", day.When, err)
-		}
-		if shouldSkip {
-			continue
-		}
+			evaluatedIcon, err = resolveIconPath(evaluatedIcon, filepath.Dir(filename))
+			if err != nil {
+				return nil, fmt.Errorf("invalid icon for day %q: %w", day.When, err)
+			}
 
-		specialDay := SpecialDay{
-			Date:    date,
-			Holiday: day.Holiday,
-			Icon:    evaluatedIcon,
-			Note: SpecialDayNote{
-				Text: evaluatedText,
-				Font: evaluatedFont,
-				Size: day.Size,
-			},
-		}
+			evaluatedFont, shouldSkip, err := evaluateExpressionsWithSkip(day.Font, cfg, date)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating font for day %q: %w", day.When, err)
+			}
+			if shouldSkip {
+				continue
+			}
 
-		days[key] = specialDay
+			evaluatedURL, shouldSkip, err := evaluateExpressionsWithSkip(day.URL, cfg, date)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating url for day %q: %w", day.When, err)
+			}
+			if shouldSkip {
+				continue
+			}
+
+			// A link in the text is shown as its text, the note opens its url
+			evaluatedText, textURL, err := parseNoteLinks(evaluatedText)
+			if err != nil {
+				return nil, fmt.Errorf("invalid text for day %q: %w", day.When, err)
+			}
+			if textURL != "" && evaluatedURL != "" {
+				return nil, fmt.Errorf("invalid text for day %q: notes can't have a link and an url", day.When)
+			}
+			if evaluatedURL == "" {
+				evaluatedURL = textURL
+			}
+
+			if _, err := parseRichText(evaluatedText); err != nil {
+				return nil, fmt.Errorf("invalid text for day %q: %w", day.When, err)
+			}
+
+			evaluatedQR, shouldSkip, err := evaluateExpressionsWithSkip(day.QR, cfg, date)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating qr for day %q: %w", day.When, err)
+			}
+			if shouldSkip {
+				continue
+			}
+			if evaluatedQR != "" {
+				if _, err := EncodeQR(evaluatedQR, cfg.QRLevel); err != nil {
+					return nil, fmt.Errorf("invalid qr for day %q: %w", day.When, err)
+				}
+			}
+
+			specialDay := SpecialDay{
+				Date:    date,
+				Holiday: day.Holiday,
+				Icon:    evaluatedIcon,
+				QR:      evaluatedQR,
+				Note: SpecialDayNote{
+					Text: evaluatedText,
+					Font: evaluatedFont,
+					Size: day.Size,
+					URL:  evaluatedURL,
+				},
+			}
+
+			days[key] = specialDay
+		}
 	}
 
 	return days, nil
@@ -84,16 +135,89 @@ type specialDaysTomlFile struct {
 		Text    string
 		Font    string
 		Size    float64
+		URL     string
+		QR      string
 	}
 }
 
+// specialDaysKey is a date of special days, with its year so the days of
+// other years shown in a calendar, as in its mini months, are the ones of
+// their year
 type specialDaysKey struct {
+	year  int
 	month int
 	day   int
 }
 
 func (key specialDaysKey) String() string {
-	return fmt.Sprintf("%d/%d", key.month, key.day)
+	return fmt.Sprintf("%d/%d/%d", key.month, key.day, key.year)
+}
+
+// specialDaysKeysFromString returns the days of cfg.Year of a 'when' value:
+// a date in layout, a relative date or a date of another calendar such as
+// "hijri:1/10", the day and month of the islamic calendar, or
+// "hebrew:15 nisan", the day and month name of the hebrew calendar, or
+// "chinese:1/1", the day and month of the chinese calendar, or a solar term
+// as "chinese:qingming"
+func specialDaysKeysFromString(layout, s string, cfg Config) ([]specialDaysKey, error) {
+	if date, ok := strings.CutPrefix(s, "hijri:"); ok {
+		day, month, err := parseDayMonth(date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hijri date: %w", err)
+		}
+
+		dates, err := hijriDaysOfYear(cfg.Year, month, day, cfg.HijriAdjustment)
+		if err != nil {
+			return nil, err
+		}
+		return specialDaysKeysFromTimes(dates), nil
+	}
+
+	if date, ok := strings.CutPrefix(s, "hebrew:"); ok {
+		day, month, err := parseHebrewDayMonth(date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hebrew date: %w", err)
+		}
+		return specialDaysKeysFromTimes(hebrewDaysOfYear(cfg.Year, month, day)), nil
+	}
+
+	if date, ok := strings.CutPrefix(s, "chinese:"); ok {
+		if !strings.Contains(date, "/") {
+			dates, err := solarTermDaysOfYear(cfg.Year, date)
+			if err != nil {
+				return nil, err
+			}
+			return specialDaysKeysFromTimes(dates), nil
+		}
+
+		day, month, err := parseDayMonth(date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chinese date: %w", err)
+		}
+
+		dates, err := chineseDaysOfYear(cfg.Year, month, day)
+		if err != nil {
+			return nil, err
+		}
+		return specialDaysKeysFromTimes(dates), nil
+	}
+
+	key, err := specialDaysKeyFromString(layout, s, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []specialDaysKey{key}, nil
+}
+
+// parseDayMonth parses "day/month" as numbers
+func parseDayMonth(s string) (day, month int, err error) {
+	d, m, ok := strings.Cut(strings.TrimSpace(s), "/")
+	day, errDay := strconv.Atoi(d)
+	month, errMonth := strconv.Atoi(m)
+	if !ok || errDay != nil || errMonth != nil {
+		return 0, 0, fmt.Errorf("can't parse %q as day/month", s)
+	}
+	return day, month, nil
 }
 
 func specialDaysKeyFromString(layout, s string, cfg Config) (specialDaysKey, error) {
@@ -108,11 +232,21 @@ func specialDaysKeyFromString(layout, s string, cfg Config) (specialDaysKey, err
 		return specialDaysKey{}, fmt.Errorf("can't parse %q as %q or relative date: %w", s, layout, err)
 	}
 
-	return specialDaysKeyFromTime(t), nil
+	// Dates are of cfg.Year even with a year in layout
+	return specialDaysKey{year: cfg.Year, month: int(t.Month()), day: t.Day()}, nil
+}
+
+func specialDaysKeysFromTimes(times []time.Time) []specialDaysKey {
+	keys := make([]specialDaysKey, len(times))
+	for i, t := range times {
+		keys[i] = specialDaysKeyFromTime(t)
+	}
+	return keys
 }
 
 func specialDaysKeyFromTime(t time.Time) specialDaysKey {
 	return specialDaysKey{
+		year:  t.Year(),
 		month: int(t.Month()),
 		day:   t.Day(),
 	}
@@ -337,6 +471,7 @@ func parseRelativeDate(s string, cfg Config) (specialDaysKey, error) {
 	}
 
 	return specialDaysKey{
+		year:  cfg.Year,
 		month: month,
 		day:   day,
 	}, nil
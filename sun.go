@@ -0,0 +1,207 @@
+package galendar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coordinates are a place on Earth, in degrees: latitude north and longitude
+// east are positive
+type Coordinates struct {
+	Latitude, Longitude float64
+}
+
+// ParseCoordinates parses "latitude,longitude" in degrees, such as
+// "-34.6,-58.4", an empty string means no coordinates
+func ParseCoordinates(s string) (*Coordinates, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	lat, lon, ok := strings.Cut(s, ",")
+	latitude, errLat := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	longitude, errLon := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if !ok || errLat != nil || errLon != nil || math.Abs(latitude) > 90 || math.Abs(longitude) > 180 {
+		return nil, fmt.Errorf("invalid location: %q (must be latitude,longitude in degrees)", s)
+	}
+
+	return &Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// Sun is the sunrise and sunset of a day at some coordinates
+type Sun struct {
+	Sunrise, Sunset      time.Time     // zero if the sun doesn't rise or set that day, see DayLength
+	CivilDawn, CivilDusk time.Time     // the sun 6 degrees below the horizon, zero if it doesn't get there that day
+	DayLength            time.Duration // from sunrise to sunset, 24 hours on polar days and 0 on polar nights
+	DayLengthDelta       time.Duration // change of DayLength since the day before
+}
+
+// Altitudes of the center of the sun at its events, in degrees: sunrise and
+// sunset take the refraction and the radius of the sun into account
+const (
+	sunriseAltitude       = -0.833
+	civilTwilightAltitude = -6.0
+)
+
+// sunOfDays returns the sun of the days from start to end, both included, at
+// coordinates with times in loc
+func sunOfDays(start, end time.Time, loc *time.Location, coordinates Coordinates) map[time.Time]*Sun {
+	suns := map[time.Time]*Sun{}
+	previous := sunOfDay(start.AddDate(0, 0, -1), loc, coordinates)
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		sun := sunOfDay(date, loc, coordinates)
+		sun.DayLengthDelta = sun.DayLength - previous.DayLength
+		suns[date] = sun
+		previous = sun
+	}
+	return suns
+}
+
+// sunOfDay returns the sun of date, a date at midnight UTC, at coordinates
+func sunOfDay(date time.Time, loc *time.Location, coordinates Coordinates) *Sun {
+	sun := &Sun{}
+
+	sunrise, rises, polarDay := sunEvent(date, coordinates, sunriseAltitude, true)
+	sunset, _, _ := sunEvent(date, coordinates, sunriseAltitude, false)
+	switch {
+	case rises:
+		sun.Sunrise, sun.Sunset = sunrise.In(loc), sunset.In(loc)
+		sun.DayLength = sunset.Sub(sunrise)
+	case polarDay:
+		sun.DayLength = 24 * time.Hour
+	}
+
+	if dawn, ok, _ := sunEvent(date, coordinates, civilTwilightAltitude, true); ok {
+		dusk, _, _ := sunEvent(date, coordinates, civilTwilightAltitude, false)
+		sun.CivilDawn, sun.CivilDusk = dawn.In(loc), dusk.In(loc)
+	}
+
+	return sun
+}
+
+// sunEvent returns the time the center of the sun crosses altitude on the
+// solar day of date at coordinates, rising or setting, with the algorithm of
+// the NOAA solar calculator. ok is false if the sun stays above (always is
+// true) or below the altitude the whole day
+func sunEvent(date time.Time, coordinates Coordinates, altitude float64, rising bool) (event time.Time, ok, always bool) {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	latitude := radians(coordinates.Latitude)
+
+	// Minutes since midnight UTC, starting at the mean solar noon and refined
+	// with the position of the sun at the previous estimate
+	minutes := 720 - 4*coordinates.Longitude
+	for range 3 {
+		equationOfTime, declination := solarPosition(midnight.Add(time.Duration(minutes * float64(time.Minute))))
+		noon := 720 - 4*coordinates.Longitude - equationOfTime
+
+		cosHourAngle := (math.Sin(radians(altitude)) - math.Sin(latitude)*math.Sin(declination)) /
+			(math.Cos(latitude) * math.Cos(declination))
+		if cosHourAngle > 1 || cosHourAngle < -1 {
+			return time.Time{}, false, cosHourAngle < -1
+		}
+
+		hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+		if rising {
+			minutes = noon - 4*hourAngle
+		} else {
+			minutes = noon + 4*hourAngle
+		}
+	}
+
+	return midnight.Add(time.Duration(math.Round(minutes*60)) * time.Second), true, false
+}
+
+// solarPosition returns the equation of time in minutes and the declination
+// of the sun in radians at t
+func solarPosition(t time.Time) (equationOfTime, declination float64) {
+	c := (julianDay(t) - jdJ2000) / 36525
+
+	meanLongitude := radians(math.Mod(280.46646+c*(36000.76983+c*0.0003032), 360))
+	meanAnomaly := radians(357.52911 + c*(35999.05029-0.0001537*c))
+	eccentricity := 0.016708634 - c*(0.000042037+0.0000001267*c)
+	center := radians(math.Sin(meanAnomaly)*(1.914602-c*(0.004817+0.000014*c)) +
+		math.Sin(2*meanAnomaly)*(0.019993-0.000101*c) + math.Sin(3*meanAnomaly)*0.000289)
+
+	omega := radians(125.04 - 1934.136*c)
+	apparentLongitude := meanLongitude + center - radians(0.00569+0.00478*math.Sin(omega))
+	meanObliquity := 23 + (26+(21.448-c*(46.815+c*(0.00059-c*0.001813)))/60)/60
+	obliquity := radians(meanObliquity + 0.00256*math.Cos(omega))
+
+	declination = math.Asin(math.Sin(obliquity) * math.Sin(apparentLongitude))
+
+	y := math.Pow(math.Tan(obliquity/2), 2)
+	equationOfTime = 4 * 180 / math.Pi * (y*math.Sin(2*meanLongitude) -
+		2*eccentricity*math.Sin(meanAnomaly) +
+		4*eccentricity*y*math.Sin(meanAnomaly)*math.Cos(2*meanLongitude) -
+		0.5*y*y*math.Sin(4*meanLongitude) -
+		1.25*eccentricity*eccentricity*math.Sin(2*meanAnomaly))
+
+	return equationOfTime, declination
+}
+
+// sunText is the sun of a day laid out on a page, in millimeters: lines of
+// text at the bottom left corner of the cell
+type sunText struct {
+	x, top     float64
+	size       float64
+	lineHeight float64
+	color      Color
+	lines      []string
+}
+
+// sunLines returns the lines of text of sun: sunrise and sunset, and the
+// civil twilight and the length of the day if config shows them
+func (config Config) sunLines(sun *Sun) []string {
+	clock := func(t time.Time) string {
+		if t.IsZero() {
+			return "--:--"
+		}
+		return t.Format("15:04")
+	}
+
+	lines := []string{clock(sun.Sunrise) + " - " + clock(sun.Sunset)}
+	if config.Twilight {
+		lines = append(lines, "("+clock(sun.CivilDawn)+" - "+clock(sun.CivilDusk)+")")
+	}
+	if config.DayLength {
+		length := sun.DayLength.Round(time.Minute)
+		delta := sun.DayLengthDelta.Round(time.Second)
+		sign := "+"
+		if delta < 0 {
+			sign, delta = "-", -delta
+		}
+		lines = append(lines, fmt.Sprintf("%dh%02dm %s%dm%02ds",
+			int(length.Hours()), int(length.Minutes())%60, sign, int(delta.Minutes()), int(delta.Seconds())%60))
+	}
+	return lines
+}
+
+// sunHeight returns the height taken by the sun at the bottom of the cells,
+// 0 if the cells don't show it
+func (config Config) sunHeight() float64 {
+	if config.Coordinates == nil {
+		return 0
+	}
+	return float64(len(config.sunLines(&Sun{}))) * config.Theme.Sun.TextSize * mmPerPoint * noteLineSpacing
+}
+
+// layoutSun lays out the sun of day for a cell with its top left corner at
+// x, y and height rowHeight, if the day has one
+func layoutSun(config Config, day Day, x, y, rowHeight float64) (sunText, bool) {
+	if day.Sun == nil || config.Coordinates == nil || !day.IsCurrentMonth {
+		return sunText{}, false
+	}
+
+	theme := config.Theme
+	return sunText{
+		x:          x + theme.Notes.Padding,
+		top:        y + rowHeight - theme.Notes.Padding - config.sunHeight(),
+		size:       theme.Sun.TextSize,
+		lineHeight: theme.Sun.TextSize * mmPerPoint * noteLineSpacing,
+		color:      theme.Sun.Color,
+		lines:      config.sunLines(day.Sun),
+	}, true
+}
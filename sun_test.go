@@ -0,0 +1,98 @@
+package galendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	coordinates, err := galendar.ParseCoordinates(" -34.6, -58.4 ")
+	if err != nil {
+		t.Fatalf("ParseCoordinates failed: %v", err)
+	}
+	if coordinates == nil || coordinates.Latitude != -34.6 || coordinates.Longitude != -58.4 {
+		t.Errorf("Expected -34.6,-58.4, got %v", coordinates)
+	}
+
+	if coordinates, err := galendar.ParseCoordinates(""); err != nil || coordinates != nil {
+		t.Errorf("Expected no coordinates for an empty string, got %v (%v)", coordinates, err)
+	}
+
+	for _, input := range []string{"-34.6", "north,west", "91,0", "0,181"} {
+		if _, err := galendar.ParseCoordinates(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestCalendar_AtSunTimes(t *testing.T) {
+	for _, tc := range []struct {
+		name                  string
+		timeZone              string
+		coordinates           galendar.Coordinates
+		sunrise, sunset       string
+		dawn, dusk            string
+		dayLength             time.Duration
+		dayLengthDeltaOnFirst time.Duration // sign of the change on the 1st
+	}{
+		// June solstice of 2025, as published by timeanddate.com
+		{"Buenos Aires", "America/Argentina/Buenos_Aires", galendar.Coordinates{Latitude: -34.6037, Longitude: -58.3816}, "08:00", "17:50", "07:32", "18:18", 9*time.Hour + 50*time.Minute, -time.Second},
+		{"London", "Europe/London", galendar.Coordinates{Latitude: 51.5074, Longitude: -0.1278}, "04:43", "21:21", "03:55", "22:09", 16*time.Hour + 38*time.Minute, time.Second},
+		{"Tromsø", "Europe/Oslo", galendar.Coordinates{Latitude: 69.6492, Longitude: 18.9553}, "", "", "", "", 24 * time.Hour, 0},
+	} {
+		loc, err := time.LoadLocation(tc.timeZone)
+		if err != nil {
+			t.Fatalf("LoadLocation(%q) failed: %v", tc.timeZone, err)
+		}
+
+		cal, err := galendar.NewCalendar(2025, 6, time.Sunday, nil)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+		days := moonDays(cal.In(loc).At(tc.coordinates))
+		if days[21].Sun == nil {
+			t.Fatalf("Expected the sun of June 21st in %s", tc.name)
+		}
+
+		sun := days[21].Sun
+		clock := func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format("15:04")
+		}
+		near := func(got, expected string) bool {
+			if got == "" || expected == "" {
+				return got == expected
+			}
+			a, _ := time.Parse("15:04", got)
+			b, _ := time.Parse("15:04", expected)
+			return a.Sub(b).Abs() <= 2*time.Minute
+		}
+		if !near(clock(sun.Sunrise), tc.sunrise) || !near(clock(sun.Sunset), tc.sunset) {
+			t.Errorf("Expected sunrise %q and sunset %q in %s, got %q and %q", tc.sunrise, tc.sunset, tc.name, clock(sun.Sunrise), clock(sun.Sunset))
+		}
+		if !near(clock(sun.CivilDawn), tc.dawn) || !near(clock(sun.CivilDusk), tc.dusk) {
+			t.Errorf("Expected civil twilight %q to %q in %s, got %q to %q", tc.dawn, tc.dusk, tc.name, clock(sun.CivilDawn), clock(sun.CivilDusk))
+		}
+		if diff := (sun.DayLength - tc.dayLength).Abs(); diff > 2*time.Minute {
+			t.Errorf("Expected a day of %v in %s, got %v", tc.dayLength, tc.name, sun.DayLength)
+		}
+
+		// Days get shorter in the south and longer in the north until the solstice
+		delta := days[1].Sun.DayLengthDelta
+		if (delta > 0) != (tc.dayLengthDeltaOnFirst > 0) || (delta < 0) != (tc.dayLengthDeltaOnFirst < 0) {
+			t.Errorf("Expected a change of the length of the day like %v in %s, got %v", tc.dayLengthDeltaOnFirst, tc.name, delta)
+		}
+	}
+
+	cal, err := galendar.NewCalendar(2025, 6, time.Sunday, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if days := moonDays(cal); days[21].Sun != nil {
+		t.Errorf("Expected no sun without coordinates, got %+v", days[21].Sun)
+	}
+}
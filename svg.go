@@ -1,11 +1,15 @@
 package galendar
 
 import (
-	"encoding/xml"
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -22,443 +26,640 @@ func (r SVGRenderer) Name() string {
 
 // RenderMonth renders a single month calendar to SVG
 func (r SVGRenderer) RenderMonth(config Config, cal Calendar) error {
-	svg := r.generateSVG(config, cal)
+	config = config.Print.withSafeZone(config)
+	config = config.withFontSizes()
+	svg, err := r.generateSVG(config, cal)
+	if err != nil {
+		return err
+	}
 	return os.WriteFile(config.MonthOutputFilePath(cal), []byte(svg), 0644)
 }
 
-// RenderYear renders a full year calendar, creating 12 separate SVG files
+// RenderYear renders a full year calendar, creating 12 separate SVG files (or
+// one for each month of a calendar from a model) and one more for the cover if
+// it has an image
 func (r SVGRenderer) RenderYear(config Config, cal Calendar) error {
-	for month := 1; month <= 12; month++ {
-		cal, err := cal.CloneAt(month)
+	config = config.Print.withSafeZone(config)
+	config = config.withFontSizes()
+	if config.CoverImage != "" {
+		svg, err := r.generateCoverSVG(config)
 		if err != nil {
-			return fmt.Errorf("can't clone calendar at month %d: %w", month, err)
+			return fmt.Errorf("failed to render cover: %w", err)
 		}
+		if err := os.WriteFile(config.CoverOutputFilePath(), []byte(svg), 0644); err != nil {
+			return err
+		}
+	}
+
+	months, err := cal.months()
+	if err != nil {
+		return err
+	}
 
+	for _, cal := range months {
 		if err := r.RenderMonth(config, cal); err != nil {
-			return fmt.Errorf("failed to render month %d: %w", month, err)
+			return fmt.Errorf("failed to render month %d: %w", cal.Month, err)
 		}
 	}
 
 	return nil
 }
 
-// generateSVG generates the SVG content for a calendar
-func (r SVGRenderer) generateSVG(config Config, cal Calendar) string {
-	width := 1122
-	height := 794
-	margin := 60
+// The SVG page is A4 landscape, in units of 1/96 inch
+const (
+	svgPageWidth  = 1122
+	svgPageHeight = 794
+	svgUnitsPerMM = svgPageWidth / 297.0
+)
+
+// generateSVG generates the SVG content for a calendar. The layout is done in
+// millimeters, as in the PDF renderer, and scaled to SVG units on output. With
+// LayoutSpread both pages are in the same file, one above the other
+func (r SVGRenderer) generateSVG(config Config, cal Calendar) (string, error) {
+	theme := config.Theme
+	u := func(mm float64) float64 { return mm * svgUnitsPerMM }
+	pt := func(size float64) float64 { return u(size * mmPerPoint) }
+
+	pageWidth, pageHeight := svgPageWidth/svgUnitsPerMM, svgPageHeight/svgUnitsPerMM
+	margin := theme.Page.Margin
+	contentWidth := pageWidth - 2*margin
+
+	pages := 1
+	if config.Layout == LayoutSpread {
+		pages = 2
+	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(`<svg width="297mm" height="210mm" xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">`, width, height))
-	sb.WriteString("\n")
+	writeSVGHeader(&sb, pages)
 
 	// Collect unique SVG icons from special days
 	iconMap := r.collectSVGIcons(cal)
 
 	// Write defs section with all icons
 	if len(iconMap) > 0 {
-		r.writeDefsSection(&sb, iconMap)
+		if err := r.writeDefsSection(&sb, iconMap); err != nil {
+			return "", fmt.Errorf("can't embed icons: %w", err)
+		}
+	}
+
+	// The body is written apart so the fonts it uses can be embedded before it
+	var body strings.Builder
+	texts := newSVGTextWriter(config.SVGFonts)
+
+	// The image of the month goes in its own page before the grid or above it
+	photo, top := photoArea(config, pageWidth, pageHeight)
+	if config.Layout == LayoutSpread {
+		writeSVGRect(&body, 0, 0, svgPageWidth, svgPageHeight, theme.Page.Background, Line{})
+		if err := r.writePhoto(&body, config, config.Images[cal.Month], photo); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "  <g transform=\"translate(0 %d)\">\n", svgPageHeight)
+	}
+
+	writeSVGRect(&body, 0, 0, svgPageWidth, svgPageHeight, theme.Page.Background, Line{})
+	if config.Layout == LayoutSplit {
+		if err := r.writePhoto(&body, config, config.Images[cal.Month], photo); err != nil {
+			return "", err
+		}
 	}
 
 	// Title (Month Year)
-	monthFont := config.Fonts[FontMonths]
-	titleY := margin + 10
-	sb.WriteString(fmt.Sprintf(`  <text x="%s" y="%d" text-anchor="middle" font-family="%s" font-size="36" font-weight="" fill="black">%s %d</text>`,
-		"50%", titleY, monthFont, config.Language.MonthName(cal.Month), cal.Year))
-	sb.WriteString("\n")
+	texts.write(&body, svgText{
+		x: u(pageWidth / 2), y: u(centeredBaseline(top, theme.Title.Height, theme.Title.Size)), anchor: "middle",
+		font: config.Fonts[FontMonths], size: pt(theme.Title.Size), fill: theme.Title.Color.String(),
+		lines: []string{theme.title(config, cal)},
+	})
+
+	if config.MiniMonths {
+		miniTexts, miniRects, err := layoutMiniMonths(config, cal, pageWidth, top)
+		if err != nil {
+			return "", fmt.Errorf("can't draw mini months: %w", err)
+		}
+		for _, rect := range miniRects {
+			writeSVGRect(&body, u(rect.x), u(rect.y), u(rect.w), u(rect.h), rect.fill, Line{})
+		}
+		for _, text := range miniTexts {
+			texts.write(&body, svgText{
+				x: u(text.x), y: u(text.y), anchor: "middle",
+				font: config.Fonts[text.font], size: pt(text.size), fill: text.color.String(),
+				lines: []string{text.text},
+			})
+		}
+	}
 
 	// Weekday headers
-	daysFont := config.Fonts[FontDays]
-	cellWidth := (width - 2*margin) / 7
-	headerY := titleY + 40
+	cellWidth := contentWidth / 7
+	headerY := top + theme.Title.Height
 
 	weekdayNames := config.Language.WeekdayAbbreviations(cal.WeekStart)
 	for i, dayName := range weekdayNames {
-		x := margin + i*cellWidth + cellWidth/2
-		sb.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-family="%s" font-size="24" font-weight="" text-anchor="middle" fill="black">%s</text>`,
-			x, headerY, daysFont, dayName))
-		sb.WriteString("\n")
+		x := margin + float64(i)*cellWidth + cellWidth/2
+		texts.write(&body, svgText{
+			x: u(x), y: u(centeredBaseline(headerY, theme.Weekdays.Height, theme.Weekdays.Size)), anchor: "middle",
+			font: config.Fonts[FontWeekdays], size: pt(theme.Weekdays.Size), fill: theme.Weekdays.Color.String(),
+			lines: []string{dayName},
+		})
 	}
 
 	// Calendar grid
-	gridStartY := headerY + 20
-	rows := len(cal.Weeks)
-	rowHeight := float64(height-gridStartY-margin) / float64(rows)
-
-	// Calculate note font size based on number of rows (matching PDF logic)
-	noteFontSize := float64(config.FontSizes[FontNotes])
-	switch rows {
-	case 5:
-		noteFontSize = noteFontSize - 1
-	case 6:
-		noteFontSize = noteFontSize - 2
-	}
+	gridStartY := headerY + theme.Weekdays.Height
+	dayBoxWidth := cellWidth * theme.DayBox.Width
+	gridHeight := pageHeight - margin - gridStartY - config.footerHeight()
+	// Short grids, as the one below an image, have rows lower than the day box
+	dayBoxHeight := min(theme.DayBox.Height, gridHeight/float64(len(cal.Weeks)))
+	qrSize := config.cellQRSize(cellWidth, gridHeight/float64(len(cal.Weeks)), dayBoxHeight)
+
+	// Fit the notes in their cells, with the same logic as the PDF renderer
+	notes := noteLayout{
+		gridHeight:    gridHeight,
+		width:         cellWidth - 2*theme.Notes.Padding,
+		rowPadding:    dayBoxHeight + theme.Notes.Gap + theme.Notes.Padding + config.cellFooterHeight(),
+		foldedWidth:   cellWidth/2 - theme.Notes.Padding,
+		foldedPadding: theme.Folded.Header + theme.Notes.Gap,
+		footnoteWidth: contentWidth,
+		qrWidth:       qrSize,
+		toUnits:       func(size float64) float64 { return size * mmPerPoint },
+		measure: func(day Day, size float64) func(string, textStyle) float64 {
+			return func(text string, style textStyle) float64 {
+				font := noteFontName(config, day)
+				if variant := texts.cache.fontVariant(font, textStyle{bold: style.bold, italic: style.italic}); variant != "" {
+					font = variant
+				}
+				return texts.cache.metricsForFont(font).textWidth(text, size*mmPerPoint)
+			}
+		},
+		measureFootnote: func(size float64) func(string) float64 {
+			return texts.cache.metricsForFont(config.Fonts[FontNotes]).measure(size * mmPerPoint)
+		},
+	}.fit(config, cal)
+	notes.warnOverflowed(config, cal)
+	rowHeight := notes.rowHeight
 
 	for weekIdx, week := range cal.Weeks {
 		for dayIdx, day := range week {
-			x := margin + dayIdx*cellWidth
-			y := gridStartY + weekIdx*int(rowHeight)
+			x := margin + float64(dayIdx)*cellWidth
+			y := gridStartY + float64(weekIdx)*rowHeight
 
-			// Draw cell border
-			sb.WriteString(fmt.Sprintf(`  <rect x="%d" y="%d" width="%d" height="%.0f" fill="white" stroke="#000000" stroke-width="1"/>`,
-				x, y, cellWidth, rowHeight))
-			sb.WriteString("\n")
+			// Draw cell background and border
+			writeSVGRect(&body, u(x), u(y), u(cellWidth), u(rowHeight), theme.cellBackground(day), scaleLine(theme.Cell.Border, u))
 
-			// Get text and fill colors
-			tr, tg, tb, ta := day.TextColor()
-			if ta == 0 && !config.ShowExtraDays {
+			if !day.IsCurrentMonth && !config.ShowExtraDays {
+				continue
+			}
+
+			if day.Folded != nil {
+				r.writeFoldedCell(&body, texts, config, notes, iconMap, day, x, y, cellWidth, rowHeight)
 				continue
 			}
 
 			// Draw day box rectangle for current month days (matching PDF)
-			dayBoxHeight := 50.0
-			dayBoxBottom := float64(y) + dayBoxHeight
 			if day.IsCurrentMonth {
-				fr, fg, fb, fa := day.FillColor()
-				fill := "white"
-				// Draw filled rectangle for holidays (FD mode in PDF)
-				if fa != 0 {
-					fill = fmt.Sprintf("rgb(%d,%d,%d)", fr, fg, fb)
+				writeSVGRect(&body, u(x), u(y), u(dayBoxWidth), u(dayBoxHeight), theme.dayBoxFill(day), scaleLine(theme.DayBox.Border, u))
+			}
+
+			// Draw day number centered in the day box (matching PDF)
+			texts.write(&body, svgText{
+				x: u(x + dayBoxWidth/2), y: u(centeredBaseline(y, dayBoxHeight, theme.DayNumber.Size)), anchor: "middle",
+				font: config.Fonts[FontDays], size: pt(theme.DayNumber.Size), fill: theme.dayNumberColor(day).String(),
+				lines: []string{fmt.Sprintf("%d", day.DayNumber)},
+			})
+
+			// Render special day icons if present
+			if day.IsCurrentMonth {
+				for _, icon := range layoutCellIcons(theme, day, x, y, cellWidth) {
+					if iconID, ok := iconMap[icon.icon]; ok {
+						writeSVGIcon(&body, iconID, u(icon.x), u(icon.y), u(icon.size))
+					}
 				}
+			}
 
-				sb.WriteString(fmt.Sprintf(`  <rect x="%d" y="%d" width="%.0f" height="%.0f" fill="%s" stroke="#000000"/>`,
-					x, y, float64(cellWidth)/3, dayBoxHeight, fill))
-				sb.WriteString("\n")
+			if glyph, ok := layoutMoon(config, day, x, y, cellWidth, dayBoxWidth); ok {
+				writeSVGMoon(&body, texts, config, glyph)
 			}
 
-			// Draw day number (matching PDF positioning)
-			dayText := fmt.Sprintf("%d", day.DayNumber)
-			textColor := fmt.Sprintf("rgb(%d,%d,%d)", tr, tg, tb)
-			// Position similar to PDF: x+1+(numberWidth/2), y-(rowHeight/2)+8
-			// For SVG, we approximate this positioning
-			numberWidth := 14
-			if len(dayText) >= 2 {
-				numberWidth = 0
+			// Render special day note/text if present (matching PDF logic)
+			if note, ok := notes.notes[day.Name()]; ok {
+				writeSVGNote(&body, texts, config, day, note, x+theme.Notes.Padding, y+dayBoxHeight+theme.Notes.Gap)
 			}
-			textX := x + 8 + (numberWidth / 2)
-			textY := y + 10 + (int(dayBoxHeight) / 2)
-			sb.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-family="%s" font-size="30" fill="%s">%s</text>`,
-				textX, textY, daysFont, textColor, dayText))
-			sb.WriteString("\n")
-
-			// Render special day icon if present
-			if day.special != nil && day.special.Icon != "" && day.IsCurrentMonth {
-				if iconID, ok := iconMap[day.special.Icon]; ok {
-					iconSize := cellWidth / 3
-					iconX := x + cellWidth - iconSize - 5
-					iconY := y + 5
-					// Use <use> with symbol - width and height will scale the symbol
-					// Use xlink:href for better compatibility with older SVG viewers
-					sb.WriteString(fmt.Sprintf(`  <use xlink:href="#%s" x="%d" y="%d" width="%d" height="%d"/>`,
-						iconID, iconX, iconY, iconSize, iconSize))
-					sb.WriteString("\n")
-				}
+
+			if sun, ok := layoutSun(config, day, x, y, rowHeight); ok {
+				texts.write(&body, svgText{
+					x: u(sun.x), y: u(lineBaseline(sun.top, sun.lineHeight, 0)),
+					font: config.Fonts[FontNotes], size: pt(sun.size), fill: sun.color.String(),
+					lines: sun.lines, lineHeight: u(sun.lineHeight),
+				})
 			}
 
-			// Render special day note/text if present (matching PDF logic)
-			if note := day.Note(); note != nil {
-				noteSize := noteFontSize
-				noteLineHeight := noteSize
-				if note.Size != 0 {
-					noteSize = note.Size
-					noteLineHeight = noteSize
-				}
-				noteFont := config.Fonts[FontNotes]
-				if note.Font != "" {
-					noteFont = note.Font
-				}
-				noteX := x + 5
-				noteY := int(dayBoxBottom) + int(noteLineHeight) + 5
-				availableWidth := float64(cellWidth - 4) // Leave padding on both sides
-
-				// Break text into lines that fit within the cell width
-				lines := r.wrapText(note.Text, noteSize, availableWidth)
-
-				// Render wrapped text using tspan elements
-				textColor := fmt.Sprintf("rgb(%d,%d,%d)", tr, tg, tb)
-				sb.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-family="%s" font-size="%.1f" fill="%s">`,
-					noteX, noteY, noteFont, noteSize, textColor))
-				for i, line := range lines {
-					if i == 0 {
-						// First line uses the base text element
-						sb.WriteString(escapeXML(line))
-					} else {
-						// Subsequent lines use tspan with dy for line spacing
-						sb.WriteString(fmt.Sprintf(`<tspan x="%d" dy="%.1f">%s</tspan>`,
-							noteX, noteLineHeight, escapeXML(line)))
-					}
+			if label, ok := layoutDayLabel(config, day, x, y, cellWidth, rowHeight); ok {
+				texts.write(&body, svgText{
+					x: u(label.right), y: u(label.y), anchor: "end",
+					font: config.Fonts[FontNotes], size: pt(label.size), fill: label.color.String(),
+					lines: []string{label.text},
+				})
+			}
+
+			if qr := day.QR(); qr != "" && day.IsCurrentMonth {
+				size := min(qrSize, config.cellQRSize(cellWidth, rowHeight, dayBoxHeight))
+				padding := theme.Notes.Padding
+				if err := writeSVGQRCode(&body, config, qr, x+cellWidth-padding-size, y+rowHeight-padding-size-config.dayLabelHeight(), size); err != nil {
+					return "", fmt.Errorf("can't write qr code of %s: %w", day.Name(), err)
 				}
-				sb.WriteString("</text>\n")
 			}
 		}
 	}
 
+	if config.FooterQR != "" {
+		size := config.qrSize()
+		if err := writeSVGQRCode(&body, config, config.FooterQR, pageWidth-margin-size, pageHeight-margin-size, size); err != nil {
+			return "", fmt.Errorf("can't write footer qr code: %w", err)
+		}
+	}
+
+	// Footnotes with the notes that didn't fit, below the grid
+	if len(notes.footnotes) > 0 {
+		footnotesTop := gridStartY + float64(len(cal.Weeks))*rowHeight + notes.footnoteLineHeight
+		texts.write(&body, svgText{
+			x: u(margin), y: u(lineBaseline(footnotesTop, notes.footnoteLineHeight, 0)),
+			font: config.Fonts[FontNotes], size: pt(notes.footnoteSize), fill: theme.Footnotes.Color.String(),
+			lines: notes.footnotes, lineHeight: u(notes.footnoteLineHeight),
+		})
+	}
+
+	if config.Layout == LayoutSpread {
+		body.WriteString("  </g>\n")
+	}
+
+	texts.writeStyle(&sb)
+	sb.WriteString(body.String())
 	sb.WriteString("</svg>")
-	return sb.String()
+	return sb.String(), nil
 }
 
-// collectSVGIcons collects all unique SVG icon files from the calendar's special days
-func (r SVGRenderer) collectSVGIcons(cal Calendar) map[string]string {
-	iconMap := make(map[string]string)
-	iconCounter := 0
+// generateCoverSVG generates the SVG content of the cover page
+func (r SVGRenderer) generateCoverSVG(config Config) (string, error) {
+	theme := config.Theme
+	u := func(mm float64) float64 { return mm * svgUnitsPerMM }
 
-	for _, week := range cal.Weeks {
-		for _, day := range week {
-			if day.special != nil && day.special.Icon != "" {
-				iconPath := day.special.Icon
-				// Only add if not already in map
-				if _, exists := iconMap[iconPath]; !exists {
-					iconID := fmt.Sprintf("icon-%d", iconCounter)
-					iconMap[iconPath] = iconID
-					iconCounter++
-				}
-			}
-		}
-	}
+	var sb strings.Builder
+	writeSVGHeader(&sb, 1)
 
-	log.Println("icons found:")
-	for k, v := range iconMap {
-		log.Printf("  %s : %s", k, v)
+	var body strings.Builder
+	texts := newSVGTextWriter(config.SVGFonts)
+
+	writeSVGRect(&body, 0, 0, svgPageWidth, svgPageHeight, theme.Page.Background, Line{})
+	area, title := coverLayout(config, svgPageWidth/svgUnitsPerMM, svgPageHeight/svgUnitsPerMM)
+	if err := r.writePhoto(&body, config, config.CoverImage, area); err != nil {
+		return "", err
 	}
+	texts.write(&body, svgText{
+		x: u(title.x), y: u(title.y), anchor: "middle",
+		font: config.Fonts[title.font], size: u(title.size * mmPerPoint), fill: title.color.String(),
+		lines: []string{title.text},
+	})
+
+	texts.writeStyle(&sb)
+	sb.WriteString(body.String())
+	sb.WriteString("</svg>")
+	return sb.String(), nil
+}
 
-	return iconMap
+// writeSVGHeader writes the opening <svg> of a file with pages one above the
+// other
+func writeSVGHeader(sb *strings.Builder, pages int) {
+	fmt.Fprintf(sb, `<svg width="297mm" height="%dmm" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">`,
+		210*pages, svgPageWidth, svgPageHeight*pages)
+	sb.WriteString("\n")
 }
 
-// writeDefsSection writes the <defs> section with all SVG icons
-func (r SVGRenderer) writeDefsSection(sb *strings.Builder, iconMap map[string]string) {
-	sb.WriteString("  <defs>\n")
+// writePhoto writes the image at imagePath filling area (in millimeters)
+// as config.ImageFit says, months without an image leave the area empty
+func (r SVGRenderer) writePhoto(sb *strings.Builder, config Config, imagePath string, area pageRect) error {
+	if imagePath == "" {
+		return nil
+	}
 
-	for iconPath, iconID := range iconMap {
-		innerContent, viewBox, err := r.extractSVGInnerContent(iconPath)
+	x, y := svgNumber(area.x*svgUnitsPerMM), svgNumber(area.y*svgUnitsPerMM)
+	w, h := svgNumber(area.w*svgUnitsPerMM), svgNumber(area.h*svgUnitsPerMM)
+	preserveAspectRatio := config.ImageFit.svgPreserveAspectRatio()
+
+	if isRasterIcon(imagePath) {
+		content, err := readIcon(imagePath)
 		if err != nil {
-			// Skip icons that can't be read, but continue with others
-			continue
+			return fmt.Errorf("can't read image %q: %w", imagePath, err)
+		}
+		_, format, err := image.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("can't decode image %q: %w", imagePath, err)
 		}
 
-		// Use <symbol> instead of <g> for better viewBox handling
-		// <symbol> is designed for reusable SVG content
-		if viewBox != "" {
-			fmt.Fprintf(sb, `    <symbol id="%s" viewBox="%s">%s</symbol>`, iconID, viewBox, innerContent)
-		} else {
-			fmt.Fprintf(sb, `    <symbol id="%s">%s</symbol>`, iconID, innerContent)
+		fmt.Fprintf(sb, `  <image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="%s" xlink:href="data:image/%s;base64,%s"/>`,
+			x, y, w, h, preserveAspectRatio, format, base64.StdEncoding.EncodeToString(content))
+	} else {
+		// A nested <svg> clips its content as the PDF renderer does
+		content, viewBox, err := r.importSVGContent(imagePath, "photo")
+		if err != nil {
+			return fmt.Errorf("can't import image %q: %w", imagePath, err)
 		}
-		sb.WriteString("\n")
+		if viewBox == "" {
+			icon, err := loadVectorIcon(imagePath)
+			if err != nil {
+				return fmt.Errorf("can't size image %q: %w", imagePath, err)
+			}
+			viewBox = fmt.Sprintf("%s %s %s %s", svgNumber(icon.viewBox[0]), svgNumber(icon.viewBox[1]), svgNumber(icon.viewBox[2]), svgNumber(icon.viewBox[3]))
+		}
+
+		fmt.Fprintf(sb, `  <svg x="%s" y="%s" width="%s" height="%s" viewBox="%s" preserveAspectRatio="%s">%s</svg>`,
+			x, y, w, h, escapeXMLAttr(viewBox), preserveAspectRatio, content)
 	}
+	sb.WriteString("\n")
 
-	sb.WriteString("  </defs>\n")
+	border := scaleLine(config.Theme.Photo.Border, func(mm float64) float64 { return mm * svgUnitsPerMM })
+	writeSVGRect(sb, area.x*svgUnitsPerMM, area.y*svgUnitsPerMM, area.w*svgUnitsPerMM, area.h*svgUnitsPerMM, Color{}, border)
+
+	return nil
 }
 
-// extractSVGInnerContent reads an SVG file and extracts its inner content
-// (everything between the outer <svg> tags, excluding the <svg> tags themselves)
-// Returns: innerContent, viewBox, error
-func (r SVGRenderer) extractSVGInnerContent(svgPath string) (string, string, error) {
-	content, err := os.ReadFile(svgPath)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to read SVG file %s: %w", svgPath, err)
+// writeFoldedCell writes the two days of a folded cell, see layoutFoldedCell.
+// Lengths are in millimeters
+func (r SVGRenderer) writeFoldedCell(sb *strings.Builder, texts *svgTextWriter, config Config, notes monthNotes, iconMap map[string]string, cell Day, x, y, w, h float64) {
+	theme := config.Theme
+	u := func(mm float64) float64 { return mm * svgUnitsPerMM }
+
+	if diagonal := scaleLine(theme.Folded.Diagonal, u); diagonal.Visible() {
+		fmt.Fprintf(sb, `  <line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"/>`,
+			svgNumber(u(x+w)), svgNumber(u(y)), svgNumber(u(x)), svgNumber(u(y+h)), diagonal.Color, svgNumber(diagonal.Width))
+		sb.WriteString("\n")
 	}
 
-	decoder := xml.NewDecoder(strings.NewReader(string(content)))
-	var viewBox string
-	var innerContent strings.Builder
-	depth := 0
-	inSVG := false
+	for _, half := range layoutFoldedCell(config, cell.CellDays(), x, y, w, h) {
+		box := half.box
+		writeSVGRect(sb, u(box.x), u(box.y), u(box.w), u(box.h), box.fill, scaleLine(theme.DayBox.Border, u))
 
-	for {
-		token, err := decoder.Token()
-		if err != nil {
-			if err == io.EOF {
-				break
+		number := half.number
+		texts.write(sb, svgText{
+			x: u(number.x), y: u(number.y), anchor: "middle",
+			font: config.Fonts[number.font], size: u(number.size * mmPerPoint), fill: number.color.String(),
+			lines: []string{number.text},
+		})
+
+		for _, icon := range half.icons {
+			if iconID, ok := iconMap[icon.icon]; ok {
+				writeSVGIcon(sb, iconID, u(icon.x), u(icon.y), u(icon.size))
 			}
-			return "", "", fmt.Errorf("failed to parse SVG file %s: %w", svgPath, err)
 		}
 
-		switch t := token.(type) {
-		case xml.StartElement:
-			// Check if this is the root <svg> element
-			if t.Name.Local == "svg" && depth == 0 {
-				inSVG = true
-				// Extract viewBox attribute
-				for _, attr := range t.Attr {
-					if attr.Name.Local == "viewBox" {
-						viewBox = attr.Value
-						break
-					}
-				}
-				depth++
-				continue
-			}
+		if half.moon != nil {
+			writeSVGMoon(sb, texts, config, *half.moon)
+		}
 
-			if inSVG {
-				// Skip Inkscape and Sodipodi namespace elements
-				if t.Name.Space == "http://www.inkscape.org/namespaces/inkscape" ||
-					t.Name.Space == "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd" {
-					depth++
-					continue
-				}
+		if note, ok := notes.notes[half.day.Name()]; ok {
+			writeSVGNote(sb, texts, config, half.day, note, half.notesX, half.notesTop)
+		}
+	}
+}
 
-				// Skip metadata elements
-				if t.Name.Local == "metadata" || t.Name.Local == "namedview" {
-					depth++
-					continue
-				}
+// writeSVGNote writes the fitted note of day with its first line at top, in
+// millimeters
+func writeSVGNote(sb *strings.Builder, texts *svgTextWriter, config Config, day Day, note fittedNote, x, top float64) {
+	url, color := config.noteLink(day)
+	if url != "" {
+		fmt.Fprintf(sb, "  <a xlink:href=\"%s\">\n", escapeXMLAttr(url))
+	}
+	text := svgText{
+		x: x * svgUnitsPerMM, y: lineBaseline(top, note.lineHeight, 0) * svgUnitsPerMM,
+		font: noteFontName(config, day), size: note.size * mmPerPoint * svgUnitsPerMM, fill: color.String(),
+		lineHeight: note.lineHeight * svgUnitsPerMM,
+	}
+	for _, line := range note.lines {
+		if !line.plain() {
+			text.runs = note.lines
+			break
+		}
+		text.lines = append(text.lines, line.String())
+	}
+	texts.write(sb, text)
+	if url != "" {
+		sb.WriteString("  </a>\n")
+	}
+}
 
-				// Write the element
-				innerContent.WriteString("<")
-				innerContent.WriteString(t.Name.Local)
+// writeSVGQRCode writes the QR code of text as a square of size at x, y, in
+// millimeters. The dark modules are a single path
+func writeSVGQRCode(sb *strings.Builder, config Config, text string, x, y, size float64) error {
+	qr, err := EncodeQR(text, config.QRLevel)
+	if err != nil {
+		return err
+	}
 
-				// Write attributes (filtering out editor-specific ones)
-				for _, attr := range t.Attr {
-					// Skip Inkscape and Sodipodi attributes
-					if attr.Name.Space == "http://www.inkscape.org/namespaces/inkscape" ||
-						attr.Name.Space == "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd" {
-						continue
-					}
+	writeSVGRect(sb, x*svgUnitsPerMM, y*svgUnitsPerMM, size*svgUnitsPerMM, size*svgUnitsPerMM, qrLight, Line{})
 
-					innerContent.WriteString(" ")
-					if attr.Name.Space != "" {
-						innerContent.WriteString(attr.Name.Space)
-						innerContent.WriteString(":")
-					}
-					innerContent.WriteString(attr.Name.Local)
-					innerContent.WriteString(`="`)
-					innerContent.WriteString(escapeXMLAttr(attr.Value))
-					innerContent.WriteString(`"`)
-				}
+	var d strings.Builder
+	for _, module := range qr.rects(x*svgUnitsPerMM, y*svgUnitsPerMM, size*svgUnitsPerMM) {
+		fmt.Fprintf(&d, "M%s %sh%sv%sh-%sz", svgNumber(module.x), svgNumber(module.y),
+			svgNumber(module.w), svgNumber(module.h), svgNumber(module.w))
+	}
+	fmt.Fprintf(sb, `  <path fill="%s" shape-rendering="crispEdges" d="%s"/>`, qrDark, d.String())
+	sb.WriteString("\n")
 
-				innerContent.WriteString(">")
-				depth++
+	return nil
+}
+
+// writeSVGMoon writes the moon of a day, see layoutMoon
+func writeSVGMoon(sb *strings.Builder, texts *svgTextWriter, config Config, glyph moonGlyph) {
+	style := config.Theme.Moon
+	u := func(mm float64) string { return svgNumber(mm * svgUnitsPerMM) }
+
+	if style.Shadow.Valid {
+		fmt.Fprintf(sb, `  <circle cx="%s" cy="%s" r="%s" fill="%s"/>`, u(glyph.cx), u(glyph.cy), u(glyph.r), style.Shadow)
+		sb.WriteString("\n")
+	}
+	if style.Light.Valid && len(glyph.lit) > 0 {
+		var d strings.Builder
+		for i, point := range glyph.lit {
+			command := "L"
+			if i == 0 {
+				command = "M"
 			}
+			fmt.Fprintf(&d, "%s%s %s", command, u(point.x), u(point.y))
+		}
+		fmt.Fprintf(sb, `  <path fill="%s" d="%sZ"/>`, style.Light, d.String())
+		sb.WriteString("\n")
+	}
+	if style.Color.Valid {
+		fmt.Fprintf(sb, `  <circle cx="%s" cy="%s" r="%s" fill="none" stroke="%s" stroke-width="%s"/>`,
+			u(glyph.cx), u(glyph.cy), u(glyph.r), style.Color, u(moonOutlineWidth))
+		sb.WriteString("\n")
+	}
 
-		case xml.EndElement:
-			if inSVG {
-				// Skip Inkscape and Sodipodi namespace elements
-				if t.Name.Space == "http://www.inkscape.org/namespaces/inkscape" ||
-					t.Name.Space == "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd" {
-					depth--
-					if depth == 0 {
-						inSVG = false
-					}
-					continue
-				}
+	if text := glyph.time; text != nil {
+		texts.write(sb, svgText{
+			x: text.x * svgUnitsPerMM, y: text.y * svgUnitsPerMM, anchor: "middle",
+			font: config.Fonts[text.font], size: text.size * mmPerPoint * svgUnitsPerMM, fill: text.color.String(),
+			lines: []string{text.text},
+		})
+	}
+}
 
-				// Skip metadata elements
-				if t.Name.Local == "metadata" || t.Name.Local == "namedview" {
-					depth--
-					if depth == 0 {
-						inSVG = false
-					}
-					continue
-				}
+// writeSVGIcon writes a use of the symbol of an icon, in SVG units. The width
+// and height scale the symbol, xlink:href is used for compatibility with older
+// SVG viewers
+func writeSVGIcon(sb *strings.Builder, iconID string, x, y, size float64) {
+	fmt.Fprintf(sb, `  <use xlink:href="#%s" x="%s" y="%s" width="%s" height="%s"/>`,
+		iconID, svgNumber(x), svgNumber(y), svgNumber(size), svgNumber(size))
+	sb.WriteString("\n")
+}
 
-				// Check if we're closing the root <svg> element
-				if t.Name.Local == "svg" && depth == 1 {
-					inSVG = false
-					depth--
-					continue
-				}
+// writeSVGRect writes a rectangle with the given fill and border, any of
+// them can be missing
+func writeSVGRect(sb *strings.Builder, x, y, w, h float64, fill Color, border Line) {
+	if !fill.Valid && !border.Visible() {
+		return
+	}
 
-				// Write closing tag
-				innerContent.WriteString("</")
-				innerContent.WriteString(t.Name.Local)
-				innerContent.WriteString(">")
-				depth--
+	fmt.Fprintf(sb, `  <rect x="%s" y="%s" width="%s" height="%s" fill="%s"`,
+		svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), fill)
+	if border.Visible() {
+		fmt.Fprintf(sb, ` stroke="%s" stroke-width="%s"`, border.Color, svgNumber(border.Width))
+	}
+	sb.WriteString("/>\n")
+}
 
-				if depth == 0 {
-					inSVG = false
-				}
-			}
+// scaleLine returns line with its width converted by scale
+func scaleLine(line Line, scale func(float64) float64) Line {
+	line.Width = scale(line.Width)
+	return line
+}
+
+// noteFontName returns the font of the note of day
+func noteFontName(config Config, day Day) string {
+	if note := day.Note(); note != nil && note.Font != "" {
+		return note.Font
+	}
+	return config.Fonts[FontNotes]
+}
+
+// collectSVGIcons collects all unique SVG icon files from the calendar's special days
+func (r SVGRenderer) collectSVGIcons(cal Calendar) map[string]string {
+	iconMap := make(map[string]string)
+	iconCounter := 0
 
-		case xml.CharData:
-			if inSVG && depth > 0 {
-				// Only include character data if we're inside a graphic element
-				// and it's not just whitespace
-				text := strings.TrimSpace(string(t))
-				if text != "" {
-					innerContent.WriteString(escapeXML(string(t)))
+	for _, week := range cal.Weeks {
+		for _, cell := range week {
+			for _, day := range cell.CellDays() {
+				for _, iconPath := range day.Icons() {
+					// Only add if not already in map
+					if _, exists := iconMap[iconPath]; !exists {
+						iconID := fmt.Sprintf("icon-%d", iconCounter)
+						iconMap[iconPath] = iconID
+						iconCounter++
+					}
 				}
 			}
-
-		case xml.Comment:
-			// Skip comments
-			continue
 		}
 	}
 
-	return strings.TrimSpace(innerContent.String()), viewBox, nil
-}
-
-// escapeXMLAttr escapes XML attribute values
-func escapeXMLAttr(s string) string {
-	s = strings.ReplaceAll(s, "&", "&amp;")
-	s = strings.ReplaceAll(s, "<", "&lt;")
-	s = strings.ReplaceAll(s, ">", "&gt;")
-	s = strings.ReplaceAll(s, `"`, "&quot;")
-	return s
+	return iconMap
 }
 
-// wrapText breaks text into lines that fit within the specified width
-// Uses a simple approximation: average character width ≈ fontSize * 0.6
-func (r SVGRenderer) wrapText(text string, fontSize, maxWidth float64) []string {
-	if text == "" {
-		return []string{text}
+// sortedIconPaths returns the paths of iconMap in the order their ids were
+// assigned, so the output doesn't depend on the iteration order of the map
+func sortedIconPaths(iconMap map[string]string) []string {
+	paths := make([]string, 0, len(iconMap))
+	for iconPath := range iconMap {
+		paths = append(paths, iconPath)
 	}
 
-	// Approximate average character width (most fonts are roughly 0.6x the font size)
-	avgCharWidth := fontSize * 0.6
-	maxCharsPerLine := int(maxWidth / avgCharWidth)
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := iconMap[paths[i]], iconMap[paths[j]]
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
 
-	// If text fits on one line, return it as-is
-	if len(text) <= maxCharsPerLine {
-		return []string{text}
-	}
+	return paths
+}
 
-	var lines []string
-	words := strings.Fields(text)
-	currentLine := ""
+// writeDefsSection writes the <defs> section with all SVG icons, the icons
+// that can't be loaded are reported together in the returned error
+func (r SVGRenderer) writeDefsSection(sb *strings.Builder, iconMap map[string]string) error {
+	sb.WriteString("  <defs>\n")
 
-	for _, word := range words {
-		testLine := currentLine
-		if testLine != "" {
-			testLine += " " + word
+	var errs []error
+	for _, iconPath := range sortedIconPaths(iconMap) {
+		iconID := iconMap[iconPath]
+		var innerContent, viewBox string
+		var err error
+		if isRasterIcon(iconPath) {
+			innerContent, viewBox, err = r.extractImageContent(iconPath)
 		} else {
-			testLine = word
+			innerContent, viewBox, err = r.importSVGContent(iconPath, iconID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("icon %q: %w", iconPath, err))
+			continue
 		}
 
-		// Check if adding this word would exceed the line width
-		if len(testLine) <= maxCharsPerLine {
-			currentLine = testLine
+		// Use <symbol> instead of <g> for better viewBox handling
+		// <symbol> is designed for reusable SVG content
+		if viewBox != "" {
+			fmt.Fprintf(sb, `    <symbol id="%s" viewBox="%s">%s</symbol>`, iconID, escapeXMLAttr(viewBox), innerContent)
 		} else {
-			// If current line has content, save it and start a new line
-			if currentLine != "" {
-				lines = append(lines, currentLine)
-				currentLine = word
-			} else {
-				// Word is too long, break it (shouldn't happen often, but handle it)
-				// Break the word itself if it's longer than maxCharsPerLine
-				if len(word) > maxCharsPerLine {
-					// Add what we have so far
-					if currentLine != "" {
-						lines = append(lines, currentLine)
-					}
-					// Break the long word
-					for len(word) > maxCharsPerLine {
-						lines = append(lines, word[:maxCharsPerLine])
-						word = word[maxCharsPerLine:]
-					}
-					currentLine = word
-				} else {
-					currentLine = word
-				}
-			}
+			fmt.Fprintf(sb, `    <symbol id="%s">%s</symbol>`, iconID, innerContent)
 		}
+		sb.WriteString("\n")
 	}
 
-	// Add the last line if there's any remaining text
-	if currentLine != "" {
-		lines = append(lines, currentLine)
+	sb.WriteString("  </defs>\n")
+
+	return errors.Join(errs...)
+}
+
+// importSVGContent reads an SVG icon and returns its sanitized inner content,
+// with its ids prefixed by iconID so they don't collide with other icons
+// Returns: innerContent, viewBox, error
+func (r SVGRenderer) importSVGContent(svgPath, iconID string) (string, string, error) {
+	content, err := readIcon(svgPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read SVG file %s: %w", svgPath, err)
+	}
+
+	innerContent, viewBox, err := importSVGIcon(content, iconID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to import SVG file %s: %w", svgPath, err)
+	}
+
+	return innerContent, viewBox, nil
+}
+
+// extractImageContent reads a PNG or JPEG icon and returns an <image> element
+// with the image inlined as a data URI, sized by its viewBox
+// Returns: innerContent, viewBox, error
+func (r SVGRenderer) extractImageContent(imagePath string) (string, string, error) {
+	content, err := readIcon(imagePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read image file %s: %w", imagePath, err)
 	}
 
-	return lines
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode image file %s: %w", imagePath, err)
+	}
+
+	dataURI := fmt.Sprintf("data:image/%s;base64,%s", format, base64.StdEncoding.EncodeToString(content))
+	innerContent := fmt.Sprintf(`<image width="%d" height="%d" xlink:href="%s"/>`, config.Width, config.Height, dataURI)
+	viewBox := fmt.Sprintf("0 0 %d %d", config.Width, config.Height)
+
+	return innerContent, viewBox, nil
+}
+
+// escapeXMLAttr escapes XML attribute values
+func escapeXMLAttr(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	s = strings.ReplaceAll(s, `"`, "&quot;")
+	return s
 }
 
 // escapeXML escapes XML special characters in text
@@ -0,0 +1,305 @@
+package galendar
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// SVGFontMode tells the SVG renderer how to make fonts available to viewers
+type SVGFontMode string
+
+const (
+	// SVGFontsReference names the fonts and lets viewers find them
+	SVGFontsReference SVGFontMode = "reference"
+	// SVGFontsEmbed inlines a subset of the font files with @font-face
+	SVGFontsEmbed SVGFontMode = "embed"
+	// SVGFontsOutline converts all text to paths
+	SVGFontsOutline SVGFontMode = "outline"
+)
+
+// ParseSVGFontMode parses a font mode, an empty string means reference
+func ParseSVGFontMode(s string) (SVGFontMode, error) {
+	switch mode := SVGFontMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return SVGFontsReference, nil
+	case SVGFontsReference, SVGFontsEmbed, SVGFontsOutline:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid svg fonts mode: %q (must be embed, outline or reference)", s)
+	}
+}
+
+// svgText is a block of text, one baseline per line
+type svgText struct {
+	x, y       float64
+	anchor     string // text-anchor, "" means start
+	font       string // font name or path, as in Config.Fonts
+	size       float64
+	fill       string
+	lines      []string
+	runs       []richLine // lines of styled runs, written instead of lines
+	lineHeight float64
+}
+
+// svgTextWriter writes text elements using the configured font mode and
+// keeps track of the glyphs used so embedded fonts can be subset
+type svgTextWriter struct {
+	mode    SVGFontMode
+	cache   *fontCache
+	aliases map[string]string        // font -> family name of the embedded font
+	fonts   []string                 // embedded fonts in order of first use
+	runes   map[string]map[rune]bool // font -> runes drawn with it
+}
+
+func newSVGTextWriter(mode SVGFontMode) *svgTextWriter {
+	return &svgTextWriter{
+		mode:    mode,
+		cache:   newFontCache(),
+		aliases: map[string]string{},
+		runes:   map[string]map[rune]bool{},
+	}
+}
+
+// write writes t to sb, as <text> or as <path> in outline mode
+func (w *svgTextWriter) write(sb *strings.Builder, t svgText) {
+	if t.runs != nil {
+		w.writeRuns(sb, t)
+		return
+	}
+
+	if w.mode == SVGFontsOutline {
+		if metrics := w.cache.metricsForFont(t.font); metrics != nil {
+			w.writeOutline(sb, metrics, t)
+			return
+		}
+	}
+
+	fmt.Fprintf(sb, `  <text x="%s" y="%s" font-family="%s" font-size="%s"`,
+		svgNumber(t.x), svgNumber(t.y), escapeXMLAttr(w.family(t.font, t.lines...)), svgNumber(t.size))
+	if t.anchor != "" {
+		fmt.Fprintf(sb, ` text-anchor="%s"`, t.anchor)
+	}
+	fmt.Fprintf(sb, ` fill="%s">`, t.fill)
+	for i, line := range t.lines {
+		if i == 0 {
+			sb.WriteString(escapeXML(line))
+		} else {
+			fmt.Fprintf(sb, `<tspan x="%s" dy="%s">%s</tspan>`, svgNumber(t.x), svgNumber(t.lineHeight), escapeXML(line))
+		}
+	}
+	sb.WriteString("</text>\n")
+}
+
+// family returns the font-family for texts written with fontName. Fonts
+// given by path are referenced by their family name, and in embed mode by the
+// alias of the embedded file
+func (w *svgTextWriter) family(fontName string, texts ...string) string {
+	metrics := w.cache.metricsForFont(fontName)
+	if metrics == nil {
+		return fontName
+	}
+
+	if w.mode == SVGFontsEmbed && metrics.data != nil {
+		alias, ok := w.aliases[fontName]
+		if !ok {
+			alias = fmt.Sprintf("galendar-font-%d", len(w.fonts))
+			w.aliases[fontName] = alias
+			w.fonts = append(w.fonts, fontName)
+			w.runes[fontName] = map[rune]bool{}
+		}
+		for _, text := range texts {
+			for _, r := range text {
+				w.runes[fontName][r] = true
+			}
+		}
+		return alias
+	}
+
+	if filepath.Ext(fontName) != "" && metrics.family != "" {
+		return metrics.family
+	}
+	return fontName
+}
+
+// writeRuns writes the runs of t as <tspan> elements of a <text>, styled
+// with the bold and italic variants of the font when it has them and with
+// font-weight and font-style to let viewers fake them when it doesn't. In
+// outline mode every run is a path of its own
+func (w *svgTextWriter) writeRuns(sb *strings.Builder, t svgText) {
+	runFont := func(style textStyle) string {
+		if variant := w.cache.fontVariant(t.font, textStyle{bold: style.bold, italic: style.italic}); variant != "" {
+			return variant
+		}
+		return t.font
+	}
+
+	if w.mode == SVGFontsOutline {
+		for i, line := range t.runs {
+			x := t.x
+			for _, run := range line {
+				font, fill := runFont(run.style), t.fill
+				if run.style.color.Valid {
+					fill = run.style.color.String()
+				}
+				w.write(sb, svgText{x: x, y: t.y + float64(i)*t.lineHeight, font: font, size: t.size, fill: fill, lines: []string{run.text}})
+				x += w.cache.metricsForFont(font).textWidth(run.text, t.size)
+			}
+		}
+		return
+	}
+
+	family := w.family(t.font)
+	fmt.Fprintf(sb, `  <text x="%s" y="%s" font-family="%s" font-size="%s" fill="%s">`,
+		svgNumber(t.x), svgNumber(t.y), escapeXMLAttr(family), svgNumber(t.size), t.fill)
+	for i, line := range t.runs {
+		if i > 0 && len(line) == 0 {
+			fmt.Fprintf(sb, `<tspan x="%s" dy="%s"></tspan>`, svgNumber(t.x), svgNumber(t.lineHeight))
+		}
+		for j, run := range line {
+			var attrs strings.Builder
+			if i > 0 && j == 0 {
+				fmt.Fprintf(&attrs, ` x="%s" dy="%s"`, svgNumber(t.x), svgNumber(t.lineHeight))
+			}
+			if runFamily := w.family(runFont(run.style), run.text); runFamily != family {
+				fmt.Fprintf(&attrs, ` font-family="%s"`, escapeXMLAttr(runFamily))
+			}
+			if run.style.bold {
+				attrs.WriteString(` font-weight="bold"`)
+			}
+			if run.style.italic {
+				attrs.WriteString(` font-style="italic"`)
+			}
+			if run.style.color.Valid {
+				fmt.Fprintf(&attrs, ` fill="%s"`, run.style.color)
+			}
+
+			if attrs.Len() == 0 {
+				sb.WriteString(escapeXML(run.text))
+			} else {
+				fmt.Fprintf(sb, `<tspan%s>%s</tspan>`, attrs.String(), escapeXML(run.text))
+			}
+		}
+	}
+	sb.WriteString("</text>\n")
+}
+
+// writeStyle writes the @font-face rules of the fonts embedded so far
+func (w *svgTextWriter) writeStyle(sb *strings.Builder) {
+	if len(w.fonts) == 0 {
+		return
+	}
+
+	sb.WriteString("  <style>\n")
+	for _, fontName := range w.fonts {
+		metrics := w.cache.metricsForFont(fontName)
+
+		data, err := subsetTrueType(metrics.data, metrics.font, w.runes[fontName])
+		if err != nil {
+			log.Printf("can't subset font %q, embedding the whole font: %v", fontName, err)
+			data = metrics.data
+		}
+
+		mimeType, format := "font/ttf", "truetype"
+		if strings.HasPrefix(string(data), "OTTO") {
+			mimeType, format = "font/otf", "opentype"
+		}
+
+		fmt.Fprintf(sb, `    @font-face { font-family: "%s"; src: url(data:%s;base64,%s) format("%s"); }`,
+			w.aliases[fontName], mimeType, base64.StdEncoding.EncodeToString(data), format)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  </style>\n")
+}
+
+// writeOutline writes t as a single path built from the glyph outlines, the
+// text is kept in aria-label for accessibility
+func (w *svgTextWriter) writeOutline(sb *strings.Builder, metrics *fontMetrics, t svgText) {
+	scale := t.size / metrics.unitsPerEm
+	ppem := fixed.Int26_6(metrics.unitsPerEm * 64)
+
+	var d strings.Builder
+	point := func(penX, baseline float64, p fixed.Point26_6) string {
+		return svgNumber(penX+float64(p.X)/64*scale) + " " + svgNumber(baseline+float64(p.Y)/64*scale)
+	}
+
+	for i, line := range t.lines {
+		penX := t.x
+		switch t.anchor {
+		case "middle":
+			penX -= metrics.textWidth(line, t.size) / 2
+		case "end":
+			penX -= metrics.textWidth(line, t.size)
+		}
+		baseline := t.y + float64(i)*t.lineHeight
+
+		prev, hasPrev := sfnt.GlyphIndex(0), false
+		for _, r := range line {
+			index, err := metrics.font.GlyphIndex(&metrics.buf, r)
+			if err != nil || index == 0 {
+				penX += 0.6 * t.size
+				hasPrev = false
+				continue
+			}
+
+			if hasPrev {
+				if kern, err := metrics.font.Kern(&metrics.buf, prev, index, ppem, font.HintingNone); err == nil {
+					penX += float64(kern) / 64 * scale
+				}
+			}
+
+			// segments are in font units with the Y axis pointing down
+			segments, err := metrics.font.LoadGlyph(&metrics.buf, index, ppem, nil)
+			if err == nil {
+				for j, segment := range segments {
+					switch segment.Op {
+					case sfnt.SegmentOpMoveTo:
+						if j > 0 {
+							d.WriteString("Z")
+						}
+						d.WriteString("M" + point(penX, baseline, segment.Args[0]))
+					case sfnt.SegmentOpLineTo:
+						d.WriteString("L" + point(penX, baseline, segment.Args[0]))
+					case sfnt.SegmentOpQuadTo:
+						d.WriteString("Q" + point(penX, baseline, segment.Args[0]) + " " + point(penX, baseline, segment.Args[1]))
+					case sfnt.SegmentOpCubeTo:
+						d.WriteString("C" + point(penX, baseline, segment.Args[0]) + " " + point(penX, baseline, segment.Args[1]) + " " + point(penX, baseline, segment.Args[2]))
+					}
+				}
+				if len(segments) > 0 {
+					d.WriteString("Z")
+				}
+			}
+
+			if advance, err := metrics.font.GlyphAdvance(&metrics.buf, index, ppem, font.HintingNone); err == nil {
+				penX += float64(advance) / 64 * scale
+			}
+			prev, hasPrev = index, true
+		}
+	}
+
+	if d.Len() == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, `  <path aria-label="%s" fill="%s" d="%s"/>`,
+		escapeXMLAttr(strings.Join(t.lines, " ")), t.fill, d.String())
+	sb.WriteString("\n")
+}
+
+// svgNumber formats v with at most two decimals
+func svgNumber(v float64) string {
+	v = math.Round(v*100) / 100
+	if v == 0 {
+		v = 0 // avoid "-0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
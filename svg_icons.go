@@ -0,0 +1,317 @@
+package galendar
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	svgNamespace   = "http://www.w3.org/2000/svg"
+	xlinkNamespace = "http://www.w3.org/1999/xlink"
+	xmlNamespace   = "http://www.w3.org/XML/1998/namespace"
+)
+
+// activeSVGElements are removed (with all their content) from imported icons
+var activeSVGElements = map[string]bool{
+	"script":           true,
+	"foreignObject":    true,
+	"iframe":           true,
+	"embed":            true,
+	"object":           true,
+	"handler":          true,
+	"listener":         true,
+	"animate":          true,
+	"animateColor":     true,
+	"animateMotion":    true,
+	"animateTransform": true,
+	"set":              true,
+	"discard":          true,
+	"metadata":         true,
+	"namedview":        true,
+	"title":            true,
+	"desc":             true,
+}
+
+// presentationAttributes of the root <svg> of an icon are kept in a <g>
+// wrapping its content, everything else of the root is dropped
+var presentationAttributes = map[string]bool{
+	"fill":              true,
+	"fill-opacity":      true,
+	"fill-rule":         true,
+	"stroke":            true,
+	"stroke-width":      true,
+	"stroke-opacity":    true,
+	"stroke-linecap":    true,
+	"stroke-linejoin":   true,
+	"stroke-miterlimit": true,
+	"opacity":           true,
+	"color":             true,
+	"style":             true,
+	"transform":         true,
+}
+
+var (
+	cssURLPattern      = regexp.MustCompile(`url\(\s*['"]?([^'")]*)['"]?\s*\)`)
+	cssImportPattern   = regexp.MustCompile(`(?i)@import[^;]*;?`)
+	cssIDPattern       = regexp.MustCompile(`#([A-Za-z_][\w-]*)`)
+	cssClassPattern    = regexp.MustCompile(`\.([A-Za-z_][\w-]*)`)
+	cssDangerousValues = regexp.MustCompile(`(?i)(javascript:|expression\s*\(|behavior\s*:|-moz-binding)`)
+	safeDataImage      = regexp.MustCompile(`^data:image/(png|jpeg|jpg|gif|webp);`)
+)
+
+// svgIconImporter rewrites the content of an SVG icon so it can be embedded
+// with other icons in the same document: ids and classes are prefixed with
+// the icon id, references are updated to the prefixed ids, and active content
+// (scripts, event handlers, animations and external references) is removed
+type svgIconImporter struct {
+	prefix string
+}
+
+// importSVGIcon reads an SVG icon and returns its sanitized inner content and
+// its viewBox, every id of the icon is prefixed with iconID
+func importSVGIcon(content []byte, iconID string) (string, string, error) {
+	importer := svgIconImporter{prefix: iconID + "-"}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var out strings.Builder
+	var viewBox string
+	depth := 0
+	skipDepth := 0
+	var elements []string // names of the open elements, innermost last
+	rootWrapped := false
+	foundRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to parse SVG: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			elements = append(elements, t.Name.Local)
+			if skipDepth > 0 {
+				continue
+			}
+
+			if !foundRoot {
+				if t.Name.Local != "svg" {
+					return "", "", fmt.Errorf("missing <svg> root element, found <%s>", t.Name.Local)
+				}
+				foundRoot = true
+
+				var attrs []string
+				for _, attr := range t.Attr {
+					if attr.Name.Space != "" {
+						continue
+					}
+					if attr.Name.Local == "viewBox" {
+						viewBox = attr.Value
+						continue
+					}
+					if presentationAttributes[attr.Name.Local] {
+						if value, ok := importer.attribute("g", attr.Name.Local, attr.Value); ok {
+							attrs = append(attrs, fmt.Sprintf(`%s="%s"`, attr.Name.Local, escapeXMLAttr(value)))
+						}
+					}
+				}
+				if len(attrs) > 0 {
+					out.WriteString("<g " + strings.Join(attrs, " ") + ">")
+					rootWrapped = true
+				}
+				continue
+			}
+
+			if !isSVGElementNamespace(t.Name.Space) || activeSVGElements[t.Name.Local] {
+				skipDepth = depth
+				continue
+			}
+
+			out.WriteString("<")
+			out.WriteString(t.Name.Local)
+			for _, attr := range t.Attr {
+				name, ok := svgAttributeName(attr.Name)
+				if !ok {
+					continue
+				}
+				value, ok := importer.attribute(t.Name.Local, name, attr.Value)
+				if !ok {
+					continue
+				}
+				fmt.Fprintf(&out, ` %s="%s"`, name, escapeXMLAttr(value))
+			}
+			out.WriteString(">")
+
+		case xml.EndElement:
+			depth--
+			elements = elements[:len(elements)-1]
+			if skipDepth > 0 {
+				if depth < skipDepth {
+					skipDepth = 0
+				}
+				continue
+			}
+			if depth == 0 {
+				continue
+			}
+			out.WriteString("</")
+			out.WriteString(t.Name.Local)
+			out.WriteString(">")
+
+		case xml.CharData:
+			if skipDepth > 0 || depth < 2 {
+				continue
+			}
+			text := string(t)
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			if elements[len(elements)-1] == "style" {
+				text = importer.styleSheet(text)
+			}
+			out.WriteString(escapeXML(text))
+		}
+	}
+
+	if !foundRoot {
+		return "", "", fmt.Errorf("missing <svg> root element")
+	}
+
+	if rootWrapped {
+		out.WriteString("</g>")
+	}
+
+	return strings.TrimSpace(out.String()), viewBox, nil
+}
+
+// isSVGElementNamespace reports if an element belongs to SVG, elements of
+// other namespaces (Inkscape, Sodipodi, RDF...) are editor metadata
+func isSVGElementNamespace(space string) bool {
+	return space == "" || space == svgNamespace
+}
+
+// svgAttributeName returns the qualified name to write for an attribute,
+// only attributes of SVG, xlink and xml are kept
+func svgAttributeName(name xml.Name) (string, bool) {
+	switch name.Space {
+	case "", svgNamespace:
+		return name.Local, true
+	case xlinkNamespace, "xlink":
+		return "xlink:" + name.Local, true
+	case xmlNamespace, "xml":
+		return "xml:" + name.Local, true
+	}
+	return "", false
+}
+
+// attribute rewrites the value of an attribute, returning false if the
+// attribute has to be removed
+func (importer svgIconImporter) attribute(element, name, value string) (string, bool) {
+	lower := strings.ToLower(name)
+
+	// event handlers (onclick, onload...)
+	if strings.HasPrefix(lower, "on") {
+		return "", false
+	}
+
+	switch lower {
+	case "id":
+		return importer.prefix + value, true
+	case "class":
+		classes := strings.Fields(value)
+		for i, class := range classes {
+			classes[i] = importer.prefix + class
+		}
+		return strings.Join(classes, " "), true
+	case "href", "xlink:href":
+		value = strings.TrimSpace(value)
+		if ref, ok := strings.CutPrefix(value, "#"); ok {
+			return "#" + importer.prefix + ref, true
+		}
+		if element == "image" && safeDataImage.MatchString(value) {
+			return value, true
+		}
+		return "", false
+	case "style":
+		return importer.css(value), true
+	}
+
+	if cssDangerousValues.MatchString(value) {
+		return "", false
+	}
+
+	if strings.Contains(value, "url(") {
+		return importer.urls(value), true
+	}
+
+	return value, true
+}
+
+// styleSheet rewrites the content of <style> elements: ids and classes in
+// selectors are prefixed and declaration blocks are sanitized
+func (importer svgIconImporter) styleSheet(text string) string {
+	if !strings.ContainsAny(text, "{}") {
+		return text
+	}
+
+	text = cssImportPattern.ReplaceAllString(text, "")
+
+	var out strings.Builder
+	for text != "" {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			out.WriteString(text)
+			break
+		}
+		selector := text[:open]
+		selector = cssIDPattern.ReplaceAllString(selector, "#"+importer.prefix+"$1")
+		selector = cssClassPattern.ReplaceAllString(selector, "."+importer.prefix+"$1")
+		out.WriteString(selector)
+
+		end := strings.IndexByte(text[open:], '}')
+		if end < 0 {
+			out.WriteString("{" + importer.css(text[open+1:]) + "}")
+			break
+		}
+		out.WriteString("{" + importer.css(text[open+1:open+end]) + "}")
+		text = text[open+end+1:]
+	}
+
+	return out.String()
+}
+
+// css sanitizes a CSS fragment removing imports, dangerous values and
+// external urls, local urls are prefixed
+func (importer svgIconImporter) css(css string) string {
+	css = cssImportPattern.ReplaceAllString(css, "")
+
+	var declarations []string
+	for _, declaration := range strings.Split(css, ";") {
+		if cssDangerousValues.MatchString(declaration) {
+			continue
+		}
+		declarations = append(declarations, declaration)
+	}
+	css = strings.Join(declarations, ";")
+
+	return importer.urls(css)
+}
+
+// urls prefixes local url(#id) references and removes external ones
+func (importer svgIconImporter) urls(value string) string {
+	return cssURLPattern.ReplaceAllStringFunc(value, func(match string) string {
+		target := cssURLPattern.FindStringSubmatch(match)[1]
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(target), "#"); ok {
+			return "url(#" + importer.prefix + ref + ")"
+		}
+		return "none"
+	})
+}
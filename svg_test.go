@@ -0,0 +1,835 @@
+package galendar_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+
+	"github.com/unkiwii/galendar"
+)
+
+const testIconWithGradient = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"
+     xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" viewBox="0 0 10 10" onload="alert(1)">
+  <script>alert("icon")</script>
+  <defs>
+    <linearGradient id="grad"><stop offset="0" stop-color="#f00"/></linearGradient>
+    <path id="shape" d="M0 0 L10 10"/>
+  </defs>
+  <rect width="10" height="10" fill="url(#grad)" inkscape:label="background" onclick="steal()"/>
+  <use xlink:href="#shape"/>
+  <image xlink:href="https://example.com/tracker.png" width="1" height="1"/>
+  <a href="javascript:alert(1)"><circle r="1" style="fill:url(http://example.com/x.svg#a);stroke:url(#grad)"/></a>
+</svg>
+`
+
+func TestSVGRenderer_IconImport(t *testing.T) {
+	iconsDir := t.TempDir()
+	for _, name := range []string{"first.svg", "second.svg"} {
+		if err := os.WriteFile(filepath.Join(iconsDir, name), []byte(testIconWithGradient), 0644); err != nil {
+			t.Fatalf("Failed to write icon: %v", err)
+		}
+	}
+
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "1/12"
+icon = "`+filepath.Join(iconsDir, "first.svg")+`"
+
+[[day]]
+when = "2/12"
+icon = "`+filepath.Join(iconsDir, "second.svg")+`"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 12
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	for _, unexpected := range []string{"<script", "onload", "onclick", "alert(", "example.com", "inkscape", "http://www.inkscape.org"} {
+		if strings.Contains(svg, unexpected) {
+			t.Errorf("Expected %q to be removed from the output", unexpected)
+		}
+	}
+
+	for _, expected := range []string{
+		`id="icon-0-grad"`, `id="icon-1-grad"`,
+		`fill="url(#icon-0-grad)"`, `fill="url(#icon-1-grad)"`,
+		`<use xlink:href="#icon-0-shape">`, `stroke:url(#icon-1-grad)`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+
+	if strings.Count(svg, "<symbol") != 2 {
+		t.Errorf("Expected 2 symbols, got %d", strings.Count(svg, "<symbol"))
+	}
+}
+
+func TestSVGRenderer_IconStyleSheet(t *testing.T) {
+	icon := filepath.Join(t.TempDir(), "styled.svg")
+	content := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10">
+  <style>.dot { fill: red }</style>
+  <circle class="dot" r="1"/>
+  <text x="0" y="5">Día #uno {libre}</text>
+</svg>`
+	if err := os.WriteFile(icon, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write icon: %v", err)
+	}
+
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "1/12"
+icon = "`+icon+`"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 12
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	output, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(output)
+
+	// Only the content of <style> is CSS, text with braces is kept as is
+	for _, expected := range []string{`<style>.icon-0-dot {`, `class="icon-0-dot"`, `>Día #uno {libre}</text>`} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+}
+
+func TestSVGRenderer_WrapsNotesWithFontMetrics(t *testing.T) {
+	note := "Día de la Independencia Argentina"
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "9/7"
+holiday = true
+text = "`+note+`"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 7
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	match := regexp.MustCompile(`font-size="([0-9.]+)"[^>]*>(Día[^<]*)((?:<tspan[^>]*>[^<]*</tspan>)*)</text>`).FindStringSubmatch(string(content))
+	if match == nil {
+		t.Fatalf("Expected the note in the output")
+	}
+
+	lines := []string{match[2]}
+	for _, tspan := range regexp.MustCompile(`>([^<]*)</tspan>`).FindAllStringSubmatch(match[3], -1) {
+		lines = append(lines, tspan[1])
+	}
+	if strings.Join(lines, " ") != note {
+		t.Errorf("Expected lines %q to join into %q", lines, note)
+	}
+	if len(lines) < 2 {
+		t.Fatalf("Expected the note to be wrapped, got %q", lines)
+	}
+
+	goFont, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatalf("Failed to parse font: %v", err)
+	}
+	fontSize, _ := strconv.ParseFloat(match[1], 64)
+	face, err := opentype.NewFace(goFont, &opentype.FaceOptions{Size: fontSize, DPI: 72})
+	if err != nil {
+		t.Fatalf("Failed to create face: %v", err)
+	}
+
+	// 7 columns in the 265mm between margins, with the padding of the notes of
+	// the classic theme on both sides, in SVG units (1122 units in 297mm)
+	availableWidth := ((297.0-2*16)/7 - 2*1.5) * 1122 / 297
+	for _, line := range lines {
+		width := float64(font.MeasureString(face, line)) / 64
+		if width > availableWidth {
+			t.Errorf("Line %q is %.1f wide, expected at most %.1f", line, width, availableWidth)
+		}
+	}
+	// The first line should be as full as possible
+	firstAndNext := lines[0] + " " + strings.Fields(lines[1])[0]
+	if width := float64(font.MeasureString(face, firstAndNext)) / 64; width <= availableWidth {
+		t.Errorf("Line %q fits in %.1f, expected it in the first line", firstAndNext, availableWidth)
+	}
+}
+
+func TestSVGRenderer_FontModes(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "25/12"
+holiday = true
+text = "Navidad"
+`)
+	defer os.Remove(tmpFile)
+
+	render := func(mode galendar.SVGFontMode) string {
+		cfg := testConfig(t, galendar.SVGRenderer{})
+		cfg.Year, cfg.Month = 2025, 12
+		cfg.SVGFonts = mode
+
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+
+		if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return string(content)
+	}
+
+	t.Run("reference", func(t *testing.T) {
+		svg := render(galendar.SVGFontsReference)
+		if !strings.Contains(svg, `font-family="Go"`) {
+			t.Errorf("Expected fonts to be referenced by family name")
+		}
+		if strings.Contains(svg, "@font-face") {
+			t.Errorf("Expected no embedded fonts")
+		}
+	})
+
+	t.Run("embed", func(t *testing.T) {
+		svg := render(galendar.SVGFontsEmbed)
+		match := regexp.MustCompile(`@font-face \{ font-family: "([^"]+)"; src: url\(data:font/ttf;base64,([^)]+)\)`).FindStringSubmatch(svg)
+		if match == nil {
+			t.Fatalf("Expected an embedded font")
+		}
+		if !strings.Contains(svg, `font-family="`+match[1]+`"`) {
+			t.Errorf("Expected text to use the embedded font %q", match[1])
+		}
+
+		data, err := base64.StdEncoding.DecodeString(match[2])
+		if err != nil {
+			t.Fatalf("Failed to decode font: %v", err)
+		}
+		if len(data) >= len(goregular.TTF) {
+			t.Errorf("Expected a subset smaller than the font (%d bytes), got %d bytes", len(goregular.TTF), len(data))
+		}
+
+		subset, err := opentype.Parse(data)
+		if err != nil {
+			t.Fatalf("Failed to parse embedded font: %v", err)
+		}
+		face, err := opentype.NewFace(subset, &opentype.FaceOptions{Size: 12, DPI: 72})
+		if err != nil {
+			t.Fatalf("Failed to create face: %v", err)
+		}
+		if _, _, ok := face.GlyphBounds('N'); !ok {
+			t.Errorf("Expected the glyph for 'N' in the subset")
+		}
+	})
+
+	t.Run("outline", func(t *testing.T) {
+		svg := render(galendar.SVGFontsOutline)
+		if strings.Contains(svg, "<text") {
+			t.Errorf("Expected all text to be converted to paths")
+		}
+		if !strings.Contains(svg, `<path aria-label="Navidad"`) {
+			t.Errorf("Expected the note as a path")
+		}
+	})
+}
+
+func TestParseSVGFontMode(t *testing.T) {
+	for input, expected := range map[string]galendar.SVGFontMode{
+		"":          galendar.SVGFontsReference,
+		"reference": galendar.SVGFontsReference,
+		"Embed":     galendar.SVGFontsEmbed,
+		"outline":   galendar.SVGFontsOutline,
+	} {
+		mode, err := galendar.ParseSVGFontMode(input)
+		if err != nil || mode != expected {
+			t.Errorf("ParseSVGFontMode(%q) = %q, %v, expected %q", input, mode, err, expected)
+		}
+	}
+
+	if _, err := galendar.ParseSVGFontMode("inline"); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+}
+
+func TestSVGRenderer_MiniMonths(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "25/12"
+holiday = true
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 1
+	cfg.MiniMonths = true
+
+	// December of the previous year shows the special days of its year
+	specialDays := galendar.SpecialDays{}
+	for _, year := range []int{2024, 2025} {
+		yearCfg := cfg
+		yearCfg.Year = year
+		days, err := galendar.LoadSpecialDaysFromFile(tmpFile, yearCfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+		specialDays = specialDays.Merge(days)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	for _, expected := range []string{">Diciembre 2024</text>", ">Febrero 2025</text>"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+
+	// 9 weekend days in December 2024 plus the holiday on 25/12/2024 (a
+	// Wednesday) and 8 weekend days in February 2025
+	fills := strings.Count(svg, `fill="`+cfg.Theme.MiniMonth.HolidayFill.String()+`"/>`)
+	if fills != 9+1+8 {
+		t.Errorf("Expected %d holiday backgrounds in the mini months, got %d", 9+1+8, fills)
+	}
+}
+
+func TestSVGRenderer_MiniMonthsOfOtherYears(t *testing.T) {
+	// 3 Rajab is on 23/12/2025, a Tuesday, and was never in December 2024
+	tmpFile := createTempSpecialDaysFile(t, `[[day]]
+when = "hijri:3/7"
+holiday = true
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 1
+	cfg.MiniMonths = true
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	// Only the 9 weekend days of December 2024 and the 8 of February 2025
+	fills := strings.Count(string(content), `fill="`+cfg.Theme.MiniMonth.HolidayFill.String()+`"/>`)
+	if fills != 9+8 {
+		t.Errorf("Expected %d holiday backgrounds in the mini months, got %d", 9+8, fills)
+	}
+}
+
+func TestSVGRenderer_FoldedCells(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "31/3"
+holiday = true
+text = "Cierre"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 3
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err == nil {
+		cal, err = cal.Fold(5)
+	}
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	if count := strings.Count(svg, "<line "); count != 2 {
+		t.Errorf("Expected 2 split cells, got %d", count)
+	}
+	for _, expected := range []string{">30</text>", ">31</text>", ">Cierre<"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+
+	// The number boxes of the halves are 8mm high, the 23rd and 30th are
+	// Sundays and the 31st is a holiday
+	holidayBoxes := strings.Count(svg, `height="30.22" fill="`+cfg.Theme.DayBox.HolidayFill.String()+`"`)
+	if holidayBoxes != 3 {
+		t.Errorf("Expected 3 shaded halves, got %d", holidayBoxes)
+	}
+}
+
+func TestSVGRenderer_NoteLinks(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "12/3"
+text = "Ver [minuta](https://wiki.example.com/?a=1&b=2)"
+`)
+	defer os.Remove(tmpFile)
+
+	for _, printOnly := range []bool{false, true} {
+		cfg := testConfig(t, galendar.SVGRenderer{})
+		cfg.Year, cfg.Month = 2025, 3
+		cfg.Theme.PrintOnly = printOnly
+
+		specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+		if err != nil {
+			t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+		}
+
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+		if err == nil {
+			cal, err = cal.Fold(5)
+		}
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+
+		if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		svg := string(content)
+
+		if !strings.Contains(svg, ">Ver minuta<") {
+			t.Errorf("Expected the note without markup in the output")
+		}
+		link := strings.Contains(svg, `<a xlink:href="https://wiki.example.com/?a=1&amp;b=2">`)
+		if link == printOnly {
+			t.Errorf("Expected link %v with a print only theme %v", !printOnly, printOnly)
+		}
+	}
+}
+
+func TestSVGRenderer_RichTextNotes(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "1/5"
+holiday = true
+text = '**¡Feriado!**\n*Día* del {color:red}trabajador{/color}'
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 5
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err == nil {
+		cal, err = cal.Fold(5)
+	}
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	for _, expected := range []string{
+		`<tspan font-weight="bold">¡Feriado!</tspan>`,
+		`dy="19.19" font-style="italic">Día </tspan>del <tspan fill="#d32f2f">trabajador</tspan>`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+	if strings.Contains(svg, "**") || strings.Contains(svg, "{color") {
+		t.Errorf("Expected no markup in the output")
+	}
+}
+
+func TestSVGRenderer_RichTextLiteralMarkers(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "5/5"
+text = '**Sin cerrar'
+
+[[day]]
+when = "6/5"
+text = '**Eso\*\*'
+
+[[day]]
+when = "7/5"
+text = '\*No\* *sí*'
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 5
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	// Markers never closed or escaped are text, only the last note has style
+	for _, expected := range []string{
+		`>**Sin cerrar</text>`,
+		`>**Eso**</text>`,
+		`>*No* <tspan font-style="italic">sí</tspan>`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+	if strings.Contains(svg, `font-weight="bold"`) {
+		t.Errorf("Expected no bold text in the output")
+	}
+}
+
+func TestSVGRenderer_QRCodes(t *testing.T) {
+	tmpFile := createTempSpecialDaysFile(t, `date_format = "2/1"
+
+[[day]]
+when = "12/3"
+text = "Reunión"
+qr = "https://meet.example.com/abc"
+`)
+	defer os.Remove(tmpFile)
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 3
+	cfg.FooterQR = "https://example.com/calendario"
+
+	specialDays, err := galendar.LoadSpecialDaysFromFile(tmpFile, cfg)
+	if err != nil {
+		t.Fatalf("LoadSpecialDaysFromFile failed: %v", err)
+	}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, specialDays)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	// A path of modules for the day and another for the footer, the footer
+	// one in the bottom right corner of the page, inside the margin
+	if count := strings.Count(svg, `<path fill="#000000" shape-rendering="crispEdges"`); count != 2 {
+		t.Errorf("Expected 2 QR codes, got %d", count)
+	}
+	number := func(mm float64) string {
+		return strconv.FormatFloat(math.Round(mm*1122/297.0*100)/100, 'f', -1, 64)
+	}
+	size, margin := galendar.DefaultQRSize, cfg.Theme.Page.Margin
+	footer := fmt.Sprintf(`<rect x="%s" y="%s" width="%s" height="%s" fill="#ffffff"/>`,
+		number(297-margin-size), number(794/(1122/297.0)-margin-size), number(size), number(size))
+	if !strings.Contains(svg, footer) {
+		t.Errorf("Expected the footer QR code at the bottom right: %s", footer)
+	}
+}
+
+func TestSVGRenderer_Moon(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 1
+
+	for display, expected := range map[galendar.MoonDisplay]int{
+		galendar.MoonDisplayNone:   0,
+		galendar.MoonDisplayPhases: 4,
+		galendar.MoonDisplayDaily:  31,
+	} {
+		cfg.Moon = display
+		cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+		if err != nil {
+			t.Fatalf("NewCalendar failed: %v", err)
+		}
+
+		if err := cfg.Renderer.RenderMonth(cfg, cal.In(time.FixedZone("ART", -3*60*60))); err != nil {
+			t.Fatalf("RenderMonth failed: %v", err)
+		}
+		content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		svg := string(content)
+
+		// Every moon is an outline over its shadow, the new moon has no lit part
+		outlines := strings.Count(svg, `fill="none" stroke="#404040"`)
+		if outlines != expected {
+			t.Errorf("Expected %d moons with %s, got %d", expected, display, outlines)
+		}
+		if lit := strings.Count(svg, `<path fill="#ffffff"`); expected > 0 && lit != expected-1 {
+			t.Errorf("Expected %d lit moons with %s, got %d", expected-1, display, lit)
+		}
+
+		// The first quarter at 23:56 UTC, in the time zone of the calendar
+		if hasTime := strings.Contains(svg, ">20:56<"); hasTime != (expected > 0) {
+			t.Errorf("Expected the time of the first quarter with %s: %t", display, expected > 0)
+		}
+	}
+}
+
+func TestSVGRenderer_Sun(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 6
+	cfg.Coordinates = &galendar.Coordinates{Latitude: -34.6037, Longitude: -58.3816}
+	cfg.Twilight, cfg.DayLength = true, true
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	cal = cal.In(time.FixedZone("ART", -3*60*60)).At(*cfg.Coordinates)
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	// Sunrise and sunset, civil twilight and length of the day of June 21st
+	for _, expected := range []string{">08:00 - 17:50<", ">(07:32 - 18:18)<", ">9h50m +0m"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+}
+
+func TestSVGRenderer_HijriDayLabel(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 3
+	cfg.Language = galendar.Spanish
+	cfg.DayLabel = galendar.DayLabelHijri
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	// March 1st of 2025 is the 1st of Ramadan, March 31st the 1st of Shawwal
+	for _, expected := range []string{">1 Ramadán<", ">30 Ramadán<", ">1 Shawwal<"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+	if !strings.Contains(svg, `text-anchor="end"`) {
+		t.Errorf("Expected the day labels aligned to the right of the cells")
+	}
+}
+
+func TestSVGRenderer_ChineseDayLabel(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 2
+	cfg.Language = galendar.English
+	cfg.DayLabel = galendar.DayLabelChinese
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	// The solar terms of February 3rd and 18th and the second month starting
+	// on the 28th
+	for _, expected := range []string{">Start of spring<", ">Rain water<", ">Month 2<"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+}
+
+func TestSVGRenderer_FoldedMoon(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 8
+	cfg.Moon = galendar.MoonDisplayPhases
+
+	// August 2025 needs 6 rows, the 31st with its first quarter is folded
+	// into the cell of the 24th
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err == nil {
+		cal, err = cal.Fold(5)
+	}
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	if outlines := strings.Count(svg, `fill="none" stroke="#404040"`); outlines != 5 {
+		t.Errorf("Expected 5 moons, got %d", outlines)
+	}
+	if !strings.Contains(svg, ">06:25<") {
+		t.Errorf("Expected the time of the first quarter of the 31st")
+	}
+}
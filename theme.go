@@ -0,0 +1,457 @@
+package galendar
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const DefaultThemeName = "classic"
+
+//go:embed themes/*.toml
+var builtinThemes embed.FS
+
+// Theme holds every visual property of a calendar page. Lengths are in
+// millimeters (the SVG renderer scales them to its units), font sizes in
+// points and colors as Color. The properties are documented in
+// themes/classic.toml
+type Theme struct {
+	Name       string          `toml:"name"`
+	PrintOnly  bool            `toml:"print_only"`
+	Page       PageStyle       `toml:"page"`
+	Title      TitleStyle      `toml:"title"`
+	Weekdays   WeekdaysStyle   `toml:"weekdays"`
+	Cell       CellStyle       `toml:"cell"`
+	DayBox     DayBoxStyle     `toml:"day_box"`
+	DayNumber  DayNumberStyle  `toml:"day_number"`
+	Notes      NotesStyle      `toml:"notes"`
+	Footnotes  FootnotesStyle  `toml:"footnotes"`
+	Icon       IconStyle       `toml:"icon"`
+	OtherMonth OtherMonthStyle `toml:"other_month"`
+	MiniMonth  MiniMonthStyle  `toml:"mini_month"`
+	Folded     FoldedStyle     `toml:"folded"`
+	Photo      PhotoStyle      `toml:"photo"`
+	Cover      CoverStyle      `toml:"cover"`
+	Links      LinksStyle      `toml:"links"`
+	Moon       MoonStyle       `toml:"moon"`
+	Sun        SunStyle        `toml:"sun"`
+	DayLabel   DayLabelStyle   `toml:"day_label"`
+}
+
+type PageStyle struct {
+	Background Color   `toml:"background"`
+	Margin     float64 `toml:"margin"`
+}
+
+type TitleStyle struct {
+	Format string  `toml:"format"` // {month} and {year} are replaced
+	Color  Color   `toml:"color"`
+	Size   float64 `toml:"size"`
+	Height float64 `toml:"height"`
+}
+
+type WeekdaysStyle struct {
+	Color  Color   `toml:"color"`
+	Size   float64 `toml:"size"`
+	Height float64 `toml:"height"`
+}
+
+type CellStyle struct {
+	Background Color `toml:"background"`
+	Border     Line  `toml:"border"`
+}
+
+type DayBoxStyle struct {
+	Width       float64 `toml:"width"` // fraction of the cell width
+	Height      float64 `toml:"height"`
+	Fill        Color   `toml:"fill"`
+	HolidayFill Color   `toml:"holiday_fill"`
+	Border      Line    `toml:"border"`
+}
+
+type DayNumberStyle struct {
+	Color        Color   `toml:"color"`
+	HolidayColor Color   `toml:"holiday_color"`
+	Size         float64 `toml:"size"`
+}
+
+type NotesStyle struct {
+	Color   Color   `toml:"color"`
+	Size    float64 `toml:"size"`
+	Padding float64 `toml:"padding"`
+	Gap     float64 `toml:"gap"`
+}
+
+type FootnotesStyle struct {
+	Color Color `toml:"color"`
+}
+
+type IconStyle struct {
+	Size    float64 `toml:"size"` // fraction of the cell width
+	Padding float64 `toml:"padding"`
+}
+
+type OtherMonthStyle struct {
+	Color      Color `toml:"color"`
+	Background Color `toml:"background"`
+}
+
+type MiniMonthStyle struct {
+	Width        float64 `toml:"width"` // the height is the one of the title row
+	TitleSize    float64 `toml:"title_size"`
+	Size         float64 `toml:"size"`
+	Color        Color   `toml:"color"`
+	HolidayColor Color   `toml:"holiday_color"`
+	HolidayFill  Color   `toml:"holiday_fill"`
+}
+
+type FoldedStyle struct {
+	Diagonal   Line    `toml:"diagonal"`
+	Header     float64 `toml:"header"` // height of the number boxes
+	NumberSize float64 `toml:"number_size"`
+	IconSize   float64 `toml:"icon_size"` // fraction of the cell width
+}
+
+type PhotoStyle struct {
+	Split  float64 `toml:"split"` // fraction of the content height
+	Gap    float64 `toml:"gap"`
+	Border Line    `toml:"border"`
+}
+
+type CoverStyle struct {
+	Format string  `toml:"format"` // {year} is replaced
+	Color  Color   `toml:"color"`
+	Size   float64 `toml:"size"`
+	Height float64 `toml:"height"`
+}
+
+type LinksStyle struct {
+	Color Color `toml:"color"` // of the notes with a url, none keeps the color of the notes
+}
+
+type MoonStyle struct {
+	Size     float64 `toml:"size"` // diameter
+	TextSize float64 `toml:"text_size"`
+	Color    Color   `toml:"color"` // of the outline and the times of the phases
+	Light    Color   `toml:"light"`
+	Shadow   Color   `toml:"shadow"`
+}
+
+type SunStyle struct {
+	TextSize float64 `toml:"text_size"`
+	Color    Color   `toml:"color"`
+}
+
+type DayLabelStyle struct {
+	TextSize float64 `toml:"text_size"`
+	Color    Color   `toml:"color"`
+}
+
+// Line is a stroke, a zero width or an invalid color means no line
+type Line struct {
+	Color Color   `toml:"color"`
+	Width float64 `toml:"width"`
+}
+
+// Visible reports if the line has to be drawn
+func (line Line) Visible() bool {
+	return line.Width > 0 && line.Color.Valid
+}
+
+// Color is an RGB color, written in theme files as "#rrggbb", "#rgb" or
+// "none" (or empty) for no color. Colors for print can be written as
+// "cmyk(c, m, y, k)" with percentages, they keep their exact CMYK values for
+// CMYK output and R, G and B are an approximation for the screen
+type Color struct {
+	R, G, B    uint8
+	C, M, Y, K uint8 // percentages, only when IsCMYK
+	Valid      bool
+	IsCMYK     bool
+}
+
+func (c *Color) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	if s == "" || s == "none" {
+		*c = Color{}
+		return nil
+	}
+
+	if values, ok := strings.CutPrefix(s, "cmyk("); ok && strings.HasSuffix(values, ")") {
+		var inks [4]uint8
+		parts := strings.Split(strings.TrimSuffix(values, ")"), ",")
+		for i, part := range parts {
+			n, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(part), "%"), 10, 8)
+			if len(parts) != 4 || err != nil || n > 100 {
+				return fmt.Errorf("invalid color %q (cmyk() needs 4 percentages)", string(text))
+			}
+			inks[i] = uint8(n)
+		}
+		*c = CMYKColor(inks[0], inks[1], inks[2], inks[3])
+		return nil
+	}
+
+	hex, ok := strings.CutPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if !ok || len(hex) != 6 || err != nil {
+		return fmt.Errorf("invalid color %q (must be #rrggbb, #rgb, cmyk(c, m, y, k) or none)", string(text))
+	}
+
+	*c = Color{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), Valid: true}
+	return nil
+}
+
+// CMYKColor returns the color of the given ink percentages
+func CMYKColor(c, m, y, k uint8) Color {
+	white := func(ink uint8) uint8 {
+		return uint8(math.Round(255 * (1 - float64(ink)/100) * (1 - float64(k)/100)))
+	}
+	return Color{R: white(c), G: white(m), B: white(y), C: c, M: m, Y: y, K: k, Valid: true, IsCMYK: true}
+}
+
+func (c Color) MarshalText() ([]byte, error) {
+	if c.IsCMYK {
+		return []byte(fmt.Sprintf("cmyk(%d, %d, %d, %d)", c.C, c.M, c.Y, c.K)), nil
+	}
+	return []byte(c.String()), nil
+}
+
+// String returns the color as "#rrggbb" or "none", as used by SVG
+func (c Color) String() string {
+	if !c.Valid {
+		return "none"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// RGB returns the components of the color as used by gofpdf
+func (c Color) RGB() (r, g, b int) {
+	return int(c.R), int(c.G), int(c.B)
+}
+
+// CMYK returns the inks of the color from 0 to 1, the exact ones of colors
+// given in CMYK or a conversion without color profile of the RGB ones, that
+// prints grays with black ink only
+func (c Color) CMYK() (cyan, magenta, yellow, black float64) {
+	if c.IsCMYK {
+		return float64(c.C) / 100, float64(c.M) / 100, float64(c.Y) / 100, float64(c.K) / 100
+	}
+
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	black = 1 - max(r, g, b)
+	if black == 1 {
+		return 0, 0, 0, 1
+	}
+	return (1 - r - black) / (1 - black), (1 - g - black) / (1 - black), (1 - b - black) / (1 - black), black
+}
+
+// BuiltinThemeNames returns the names of the themes embedded in the binary
+func BuiltinThemeNames() []string {
+	entries, _ := fs.ReadDir(builtinThemes, "themes")
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".toml"))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LoadTheme loads a builtin theme by name or a theme file by path. Theme
+// files only need the properties they change, the rest are taken from the
+// default theme
+func LoadTheme(nameOrPath string) (Theme, error) {
+	if nameOrPath == "" {
+		nameOrPath = DefaultThemeName
+	}
+
+	theme, err := decodeBuiltinTheme(DefaultThemeName, Theme{})
+	if err != nil {
+		return Theme{}, err
+	}
+	if nameOrPath == DefaultThemeName {
+		return theme, nil
+	}
+
+	if filepath.Ext(nameOrPath) == "" && !strings.ContainsRune(nameOrPath, filepath.Separator) {
+		theme, err = decodeBuiltinTheme(nameOrPath, theme)
+		if errors.Is(err, fs.ErrNotExist) {
+			return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", nameOrPath, strings.Join(BuiltinThemeNames(), ", "))
+		}
+		return theme, err
+	}
+
+	if _, err := toml.DecodeFile(nameOrPath, &theme); err != nil {
+		return Theme{}, fmt.Errorf("can't load theme file %q: %w", nameOrPath, err)
+	}
+	if theme.Name == DefaultThemeName {
+		theme.Name = strings.TrimSuffix(filepath.Base(nameOrPath), filepath.Ext(nameOrPath))
+	}
+
+	return theme, theme.validate()
+}
+
+func decodeBuiltinTheme(name string, base Theme) (Theme, error) {
+	content, err := builtinThemes.ReadFile(path.Join("themes", name+".toml"))
+	if err != nil {
+		return Theme{}, err
+	}
+
+	theme := base
+	if _, err := toml.Decode(string(content), &theme); err != nil {
+		return Theme{}, fmt.Errorf("can't load builtin theme %q: %w", name, err)
+	}
+
+	return theme, theme.validate()
+}
+
+func (theme Theme) validate() error {
+	var errs []error
+	for name, size := range map[string]float64{
+		"title.size":            theme.Title.Size,
+		"weekdays.size":         theme.Weekdays.Size,
+		"day_number.size":       theme.DayNumber.Size,
+		"notes.size":            theme.Notes.Size,
+		"mini_month.width":      theme.MiniMonth.Width,
+		"mini_month.title_size": theme.MiniMonth.TitleSize,
+		"mini_month.size":       theme.MiniMonth.Size,
+		"folded.number_size":    theme.Folded.NumberSize,
+		"cover.size":            theme.Cover.Size,
+		"moon.size":             theme.Moon.Size,
+		"moon.text_size":        theme.Moon.TextSize,
+		"sun.text_size":         theme.Sun.TextSize,
+		"day_label.text_size":   theme.DayLabel.TextSize,
+	} {
+		if size <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %v", name, size))
+		}
+	}
+	if theme.DayBox.Width <= 0 || theme.DayBox.Width > 1 {
+		errs = append(errs, fmt.Errorf("day_box.width must be a fraction of the cell width, got %v", theme.DayBox.Width))
+	}
+	if theme.Icon.Size < 0 || theme.Icon.Size > 1 {
+		errs = append(errs, fmt.Errorf("icon.size must be a fraction of the cell width, got %v", theme.Icon.Size))
+	}
+	if theme.Folded.IconSize < 0 || theme.Folded.IconSize > 1 {
+		errs = append(errs, fmt.Errorf("folded.icon_size must be a fraction of the cell width, got %v", theme.Folded.IconSize))
+	}
+	if theme.Photo.Split <= 0 || theme.Photo.Split >= 1 {
+		errs = append(errs, fmt.Errorf("photo.split must be a fraction of the content height, got %v", theme.Photo.Split))
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid theme %q: %w", theme.Name, err)
+	}
+	return nil
+}
+
+// fontSize returns the size of the text drawn with font, one of AllFonts
+func (theme *Theme) fontSize(font string) *float64 {
+	switch font {
+	case FontMonths:
+		return &theme.Title.Size
+	case FontWeekdays:
+		return &theme.Weekdays.Size
+	case FontDays:
+		return &theme.DayNumber.Size
+	default:
+		return &theme.Notes.Size
+	}
+}
+
+// fontSizes returns the sizes of the text drawn with each of AllFonts
+func (theme Theme) fontSizes() map[string]float64 {
+	sizes := map[string]float64{}
+	for _, font := range AllFonts {
+		sizes[font] = *theme.fontSize(font)
+	}
+	return sizes
+}
+
+// withFontSizes returns config with the sizes of the deprecated
+// Config.FontSizes in its theme
+func (config Config) withFontSizes() Config {
+	for font, size := range config.FontSizes {
+		if size > 0 {
+			*config.Theme.fontSize(font) = size
+		}
+	}
+	return config
+}
+
+// defaultTheme returns the default theme, builtin themes are always valid
+func defaultTheme() Theme {
+	theme, _ := LoadTheme(DefaultThemeName)
+	return theme
+}
+
+// title returns the title of the page of cal
+func (theme Theme) title(config Config, cal Calendar) string {
+	return strings.NewReplacer(
+		"{month}", config.Language.MonthName(cal.Month),
+		"{year}", strconv.Itoa(cal.Year),
+	).Replace(theme.Title.Format)
+}
+
+// dayNumberColor returns the color of the number and notes of day
+func (theme Theme) dayNumberColor(day Day) Color {
+	switch {
+	case !day.IsCurrentMonth:
+		return theme.OtherMonth.Color
+	case day.IsHoliday():
+		return theme.DayNumber.HolidayColor
+	default:
+		return theme.DayNumber.Color
+	}
+}
+
+// noteColor returns the color of the note of day
+func (theme Theme) noteColor(day Day) Color {
+	if !day.IsCurrentMonth {
+		return theme.OtherMonth.Color
+	}
+	return theme.Notes.Color
+}
+
+// cellBackground returns the background of the cell of day
+func (theme Theme) cellBackground(day Day) Color {
+	if !day.IsCurrentMonth {
+		return theme.OtherMonth.Background
+	}
+	return theme.Cell.Background
+}
+
+// dayBoxFill returns the fill of the box with the number of day
+func (theme Theme) dayBoxFill(day Day) Color {
+	if day.IsHoliday() {
+		return theme.DayBox.HolidayFill
+	}
+	return theme.DayBox.Fill
+}
+
+const mmPerPoint = 25.4 / 72
+
+// centeredBaseline returns the baseline that vertically centers text of
+// size points in a box of height starting at top, like gofpdf does in cells
+func centeredBaseline(top, height, size float64) float64 {
+	return top + height/2 + 0.3*size*mmPerPoint
+}
+
+// lineBaseline returns the baseline of line i of a block of text starting at
+// top with lines of lineHeight
+func lineBaseline(top, lineHeight float64, i int) float64 {
+	return top + (float64(i)+0.8)*lineHeight
+}
@@ -0,0 +1,185 @@
+package galendar_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkiwii/galendar"
+)
+
+func TestLoadTheme_Builtin(t *testing.T) {
+	names := galendar.BuiltinThemeNames()
+	for _, expected := range []string{"classic", "dark", "high-contrast", "minimal"} {
+		if !strings.Contains(strings.Join(names, ","), expected) {
+			t.Errorf("Expected builtin theme %q in %v", expected, names)
+		}
+	}
+
+	for _, name := range names {
+		theme, err := galendar.LoadTheme(name)
+		if err != nil {
+			t.Errorf("LoadTheme(%q) failed: %v", name, err)
+			continue
+		}
+		if theme.Name != name {
+			t.Errorf("Expected theme name %q, got %q", name, theme.Name)
+		}
+	}
+
+	if _, err := galendar.LoadTheme("neon"); err == nil {
+		t.Errorf("Expected an error for an unknown theme")
+	}
+}
+
+func TestLoadTheme_File(t *testing.T) {
+	themeFile := filepath.Join(t.TempDir(), "mine.toml")
+	err := os.WriteFile(themeFile, []byte(`
+[title]
+color = "#f00"
+format = "{year} - {month}"
+
+[cell]
+border = { color = "none", width = 0.5 }
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write theme file: %v", err)
+	}
+
+	theme, err := galendar.LoadTheme(themeFile)
+	if err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+
+	if theme.Name != "mine" {
+		t.Errorf("Expected the theme to be named after its file, got %q", theme.Name)
+	}
+	if got := theme.Title.Color.String(); got != "#ff0000" {
+		t.Errorf("Expected title color #ff0000, got %s", got)
+	}
+	if theme.Cell.Border.Visible() {
+		t.Errorf("Expected no cell border")
+	}
+
+	classic, _ := galendar.LoadTheme(galendar.DefaultThemeName)
+	if theme.DayBox != classic.DayBox || theme.Title.Size != classic.Title.Size {
+		t.Errorf("Expected missing properties to be taken from the classic theme")
+	}
+}
+
+func TestColor_CMYK(t *testing.T) {
+	var c galendar.Color
+	if err := c.UnmarshalText([]byte("cmyk(0, 100, 100, 0)")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+
+	if cyan, magenta, yellow, black := c.CMYK(); cyan != 0 || magenta != 1 || yellow != 1 || black != 0 {
+		t.Errorf("Expected the inks of the color, got %v %v %v %v", cyan, magenta, yellow, black)
+	}
+	if got := c.String(); got != "#ff0000" {
+		t.Errorf("Expected an RGB approximation #ff0000, got %s", got)
+	}
+	if text, _ := c.MarshalText(); string(text) != "cmyk(0, 100, 100, 0)" {
+		t.Errorf("Expected the color to keep its inks, got %s", text)
+	}
+
+	if err := c.UnmarshalText([]byte("cmyk(0, 100, 100)")); err == nil {
+		t.Errorf("Expected an error for a color with 3 inks")
+	}
+}
+
+func TestLoadTheme_InvalidFile(t *testing.T) {
+	for name, content := range map[string]string{
+		"color": "[title]\ncolor = \"red\"\n",
+		"size":  "[notes]\nsize = 0.0\n",
+		"width": "[day_box]\nwidth = 2.0\n",
+	} {
+		themeFile := filepath.Join(t.TempDir(), name+".toml")
+		if err := os.WriteFile(themeFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write theme file: %v", err)
+		}
+
+		if _, err := galendar.LoadTheme(themeFile); err == nil {
+			t.Errorf("Expected an error for an invalid %s", name)
+		}
+	}
+}
+
+func TestSVGRenderer_Theme(t *testing.T) {
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 7
+
+	theme, err := galendar.LoadTheme("dark")
+	if err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+	cfg.Theme = theme
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	svg := string(content)
+
+	for _, expected := range []string{
+		`<rect x="0" y="0" width="1122" height="794" fill="#1e1e1e"/>`,
+		`fill="#f0f0f0">Julio 2025</text>`,
+		`stroke="#4a4a4a"`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in the output", expected)
+		}
+	}
+	if strings.Contains(svg, "#000000") {
+		t.Errorf("Expected no colors outside the theme")
+	}
+}
+
+func TestConfig_DeprecatedFontSizes(t *testing.T) {
+	if size := galendar.DefaultFontSizes[galendar.FontMonths]; size != 24 {
+		t.Errorf("Expected the month size of the default theme, 24, got %v", size)
+	}
+
+	cfg := testConfig(t, galendar.SVGRenderer{})
+	cfg.Year, cfg.Month = 2025, 7
+	cfg.FontSizes = map[string]float64{galendar.FontMonths: 36}
+
+	cal, err := galendar.NewCalendar(cfg.Year, cfg.Month, cfg.WeekStart, nil)
+	if err != nil {
+		t.Fatalf("NewCalendar failed: %v", err)
+	}
+
+	if err := cfg.Renderer.RenderMonth(cfg, cal); err != nil {
+		t.Fatalf("RenderMonth failed: %v", err)
+	}
+
+	content, err := os.ReadFile(cfg.MonthOutputFilePath(cal))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(content), `font-size="47.98" text-anchor="middle" fill="#000000">Julio 2025</text>`) {
+		t.Errorf("Expected the title at 36pt")
+	}
+
+	// The 5th of July 2025 is a Saturday
+	saturday := cal.Weeks[0][6]
+	if r, g, b, a := saturday.FillColor(); r != 200 || g != 200 || b != 200 || a != 1 {
+		t.Errorf("Expected the holiday fill of the default theme, got %d, %d, %d, %d", r, g, b, a)
+	}
+	if r, g, b, a := cal.Weeks[0][0].FillColor(); a != 0 {
+		t.Errorf("Expected no fill out of the month, got %d, %d, %d, %d", r, g, b, a)
+	}
+	if r, g, b, a := saturday.TextColor(); r != 0 || g != 0 || b != 0 || a != 1 {
+		t.Errorf("Expected the day color of the default theme, got %d, %d, %d, %d", r, g, b, a)
+	}
+}
@@ -0,0 +1,221 @@
+package galendar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PaperSize is the size of a sheet of paper in millimeters, portrait
+type PaperSize struct {
+	Name          string
+	Width, Height float64
+}
+
+var paperSizes = map[string]PaperSize{
+	"a0":      {"a0", 841, 1189},
+	"a1":      {"a1", 594, 841},
+	"a2":      {"a2", 420, 594},
+	"a3":      {"a3", 297, 420},
+	"a4":      {"a4", 210, 297},
+	"a5":      {"a5", 148, 210},
+	"letter":  {"letter", 215.9, 279.4},
+	"legal":   {"legal", 215.9, 355.6},
+	"tabloid": {"tabloid", 279.4, 431.8},
+}
+
+// ParsePaperSize parses a paper size by name (a0-a5, letter, legal or
+// tabloid) or as "WxH" in millimeters, an empty string means no paper
+func ParsePaperSize(s string) (PaperSize, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return PaperSize{}, nil
+	}
+	if size, ok := paperSizes[s]; ok {
+		return size, nil
+	}
+
+	w, h, ok := strings.Cut(s, "x")
+	width, errW := strconv.ParseFloat(strings.TrimSpace(w), 64)
+	height, errH := strconv.ParseFloat(strings.TrimSpace(h), 64)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return PaperSize{}, fmt.Errorf("invalid paper size: %q (must be a0-a5, letter, legal, tabloid or WxH in millimeters)", s)
+	}
+
+	return PaperSize{Name: s, Width: min(width, height), Height: max(width, height)}, nil
+}
+
+// IsZero reports if there is no paper size
+func (size PaperSize) IsZero() bool {
+	return size.Width == 0 || size.Height == 0
+}
+
+// landscape returns the width and height of the paper turned to landscape
+func (size PaperSize) landscape() (float64, float64) {
+	return size.Height, size.Width
+}
+
+const (
+	DefaultTilePaper   = "a4"
+	DefaultTileOverlap = 10.0
+)
+
+// tileMargin is the space around the part of the poster printed on a tile,
+// out of reach of most office printers and used for marks and labels
+const tileMargin = 10.0
+
+// tileGrid is how a poster is split into tiles of paper, the tiles overlap
+// by overlap and show step more of the poster than the previous one
+type tileGrid struct {
+	paperWidth, paperHeight float64 // of the tiles, as printed
+	width, height           float64 // of the poster on every tile
+	stepX, stepY            float64
+	overlap                 float64
+	rows, columns           int
+}
+
+// newTileGrid returns the grid of tiles of paper for a poster of posterWidth
+// by posterHeight, with the paper turned to the orientation that needs less
+// tiles
+func newTileGrid(posterWidth, posterHeight float64, paper PaperSize, overlap float64) tileGrid {
+	var best tileGrid
+	for _, turned := range []bool{false, true} {
+		grid := tileGrid{paperWidth: paper.Width, paperHeight: paper.Height, overlap: overlap}
+		if turned {
+			grid.paperWidth, grid.paperHeight = paper.landscape()
+		}
+		grid.width, grid.height = grid.paperWidth-2*tileMargin, grid.paperHeight-2*tileMargin
+		grid.stepX, grid.stepY = grid.width-overlap, grid.height-overlap
+		grid.columns = max(1, int(math.Ceil((posterWidth-overlap)/grid.stepX)))
+		grid.rows = max(1, int(math.Ceil((posterHeight-overlap)/grid.stepY)))
+
+		if best.rows == 0 || grid.rows*grid.columns < best.rows*best.columns {
+			best = grid
+		}
+	}
+
+	return best
+}
+
+// tilePDF draws every page queued by drawPDFPage scaled to the poster size of
+// opts and split into tiles. Each tile has its row and column in the margin
+// and alignment marks on the middle of the overlaps with its neighbours: tiles
+// are cut there and glued over the tiles before them matching the marks
+func tilePDF(pdf *pdfDocument, config Config, pages []func() error) error {
+	opts := config.Print
+	posterWidth, posterHeight := opts.Poster.landscape()
+	scale := min(posterWidth/pdfPageWidth, posterHeight/pdfPageHeight)
+	posterWidth, posterHeight = pdfPageWidth*scale, pdfPageHeight*scale
+
+	overlap := opts.TileOverlap
+	grid := newTileGrid(posterWidth, posterHeight, opts.TilePaper, overlap)
+	if overlap >= min(grid.width, grid.height) {
+		return fmt.Errorf("overlap of %gmm is larger than the tiles", overlap)
+	}
+
+	for pageIdx, draw := range pages {
+		for row := range grid.rows {
+			for column := range grid.columns {
+				pdf.AddPageFormat("P", gofpdf.SizeType{Wd: grid.paperWidth, Ht: grid.paperHeight})
+				if opts.PDFX {
+					pdf.SetPageBox("trim", 0, 0, grid.paperWidth, grid.paperHeight)
+				}
+
+				// The poster is moved so the part of this tile starts at the margin
+				left, top := float64(column)*grid.stepX, float64(row)*grid.stepY
+				pdf.ClipRect(tileMargin, tileMargin, grid.width, grid.height, false)
+				pdf.TransformBegin()
+				pdf.TransformTranslate(tileMargin-left, tileMargin-top)
+				pdf.TransformScale(scale*100, scale*100, 0, 0)
+				err := draw()
+				pdf.TransformEnd()
+				pdf.ClipEnd()
+				if err != nil {
+					return fmt.Errorf("can't draw tile %d, %d of page %d: %w", row+1, column+1, pageIdx+1, err)
+				}
+
+				drawTileMarks(pdf, grid, row, column)
+				if err := drawTileLabel(pdf, config, grid, pageIdx, len(pages), row, column); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// drawTileMarks draws a dashed cut line with a cross at its ends, in the
+// margin, at the middle of the overlap with the tiles around the tile
+func drawTileMarks(pdf *pdfDocument, grid tileGrid, row, column int) {
+	setPDFDrawColor(pdf, Color{Valid: true})
+	pdf.SetLineWidth(markLineWidth)
+
+	cross := func(x, y float64) {
+		pdf.Line(x-markLength/2, y, x+markLength/2, y)
+		pdf.Line(x, y-markLength/2, x, y+markLength/2)
+	}
+
+	right, bottom := tileMargin+grid.width, tileMargin+grid.height
+	center := tileMargin / 2
+
+	var lines [][4]float64
+	if column > 0 {
+		x := tileMargin + grid.overlap/2
+		lines = append(lines, [4]float64{x, tileMargin, x, bottom})
+	}
+	if column < grid.columns-1 {
+		x := right - grid.overlap/2
+		lines = append(lines, [4]float64{x, tileMargin, x, bottom})
+	}
+	if row > 0 {
+		y := tileMargin + grid.overlap/2
+		lines = append(lines, [4]float64{tileMargin, y, right, y})
+	}
+	if row < grid.rows-1 {
+		y := bottom - grid.overlap/2
+		lines = append(lines, [4]float64{tileMargin, y, right, y})
+	}
+
+	for _, line := range lines {
+		pdf.SetDashPattern([]float64{2, 2}, 0)
+		pdf.Line(line[0], line[1], line[2], line[3])
+		pdf.SetDashPattern(nil, 0)
+
+		if line[0] == line[2] {
+			cross(line[0], center)
+			cross(line[0], bottom+center)
+		} else {
+			cross(center, line[1])
+			cross(right+center, line[1])
+		}
+	}
+}
+
+// drawTileLabel writes the row and column of a tile centered in its bottom
+// margin, between the marks
+func drawTileLabel(pdf *pdfDocument, config Config, grid tileGrid, page, pages, row, column int) error {
+	label := fmt.Sprintf("%s %d, %s %d (%d x %d)",
+		config.Language.Read("row"), row+1, config.Language.Read("column"), column+1, grid.rows, grid.columns)
+	if pages > 1 {
+		label = fmt.Sprintf("%s %d/%d, %s", config.Language.Read("page"), page+1, pages, label)
+	}
+
+	if err := setFont(pdf, FontDays, tileLabelSize); err != nil {
+		return fmt.Errorf("can't set font %q: %w", FontDays, err)
+	}
+	setPDFTextColor(pdf, Color{Valid: true})
+	x := (grid.paperWidth - pdf.GetStringWidth(label)) / 2
+	pdf.Text(x, centeredBaseline(grid.paperHeight-tileMargin, tileMargin, tileLabelSize), label)
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("can't write tile label %q: %w", label, err)
+	}
+
+	return nil
+}
+
+// tileLabelSize is the font size of the labels of tiles, in points
+const tileLabelSize = 8.0